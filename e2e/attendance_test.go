@@ -0,0 +1,183 @@
+package main_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/provider"
+	"github.com/aravindanve/livemeet-server/src/resource"
+	"github.com/gorilla/mux"
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func newMockLiveKitWebhookRequest(p provider.Provider, e *livekit.WebhookEvent) *http.Request {
+	data, err := protojson.Marshal(e)
+	if err != nil {
+		panic("error encoding webhook event: " + err.Error())
+	}
+
+	// sign webhook like livekit
+	sha := sha256.Sum256(data)
+	cf := p.LiveKitConfig()
+	token, err := auth.NewAccessToken(cf.APIKey, cf.APISecret).
+		SetValidFor(5 * time.Minute).
+		SetSha256(base64.StdEncoding.EncodeToString(sha[:])).
+		ToJWT()
+	if err != nil {
+		panic("error signing webhook event: " + err.Error())
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/livekit/webhook", bytes.NewReader(data))
+	req.Header.Set("authorization", token)
+	req.Header.Set("content-type", "application/webhook+json")
+	return req
+}
+
+func TestAttendanceSearch(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+	participant := &resource.Participant{
+		ID:        resource.NewResourceID(),
+		MeetingID: meeting.ID,
+		Name:      "My Name",
+	}
+	err := p.AttendanceCollection().Register(ctx, participant)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	r := resource.RegisterAttendanceRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	// join and leave
+	joinedAt := time.Now().Add(-10 * time.Minute).Unix()
+	for _, e := range []*livekit.WebhookEvent{{
+		Event:       "participant_joined",
+		Room:        &livekit.Room{Name: meeting.Code},
+		Participant: &livekit.ParticipantInfo{Sid: "PA_1", Identity: string(participant.ID), JoinedAt: joinedAt},
+		CreatedAt:   joinedAt,
+	}, {
+		Event:       "participant_left",
+		Room:        &livekit.Room{Name: meeting.Code},
+		Participant: &livekit.ParticipantInfo{Sid: "PA_1", Identity: string(participant.ID)},
+		CreatedAt:   joinedAt + 300,
+	}} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, newMockLiveKitWebhookRequest(p, e))
+
+		s := w.Result().StatusCode
+		if s != http.StatusNoContent {
+			t.Errorf("expected status to be %#v got %#v", http.StatusNoContent, s)
+			return
+		}
+	}
+
+	// test json
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/meetings/"+string(meeting.ID)+"/attendance", nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	var m struct {
+		Attendees []resource.AttendanceReportRow `json:"attendees"`
+	}
+	err = json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(m.Attendees) != 1 || m.Attendees[0].Duration != 300 {
+		t.Errorf("expected one attendee with duration 300 got %#v", m.Attendees)
+		return
+	}
+
+	// test csv
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/meetings/"+string(meeting.ID)+"/attendance?format=csv", nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	records, err := csv.NewReader(w.Result().Body).ReadAll()
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(records) != 2 || records[1][1] != "My Name" || records[1][5] != "300" {
+		t.Errorf("unexpected csv records %#v", records)
+		return
+	}
+}
+
+func TestAttendanceWebhookBadAuth(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	r := resource.RegisterAttendanceRoutes(mux.NewRouter(), p)
+
+	w := httptest.NewRecorder()
+	req := newMockLiveKitWebhookRequest(p, &livekit.WebhookEvent{Event: "room_started"})
+	req.Header.Set("authorization", "some-token")
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusUnauthorized {
+		t.Errorf("expected status to be %#v got %#v", http.StatusUnauthorized, s)
+		return
+	}
+}
+
+func TestAttendanceWebhookBodyTooLarge(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	r := resource.RegisterAttendanceRoutes(mux.NewRouter(), p)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/livekit/webhook", bytes.NewReader(bytes.Repeat([]byte(" "), 2<<20)))
+	req.Header.Set("authorization", "some-token")
+	req.Header.Set("content-type", "application/webhook+json")
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status to be %#v got %#v", http.StatusRequestEntityTooLarge, s)
+		return
+	}
+}
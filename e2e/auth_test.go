@@ -30,7 +30,7 @@ func getMockAuthHeader() string {
 	mockAuthHeaderMut.Lock()
 	if mockAuthHeader == nil {
 		// create mock auth header
-		cf := config.NewAuthConfigProvider().AuthConfig()
+		cf := config.NewConfig().AuthConfig()
 		user := getMockUser()
 		token, err := jwt.NewBuilder().
 			Issuer(cf.Issuer).
@@ -58,7 +58,7 @@ func getMockAuthHeader() string {
 
 type mockAuthProvider struct {
 	resource.AuthDeps
-	Release       func(ctx context.Context)
+	Release       func(ctx context.Context) error
 	mongoDatabase *mongo.Database
 }
 
@@ -73,12 +73,17 @@ func (m *mockAuthProvider) GoogleOAuth2Client() client.GoogleOAuth2Client {
 
 type mockGoogleOAuth2Client struct {
 	client.GoogleOAuth2Client
+	ensureKeySetErr error
 }
 
 func newMockGoogleOAuth2Client() client.GoogleOAuth2Client {
 	return &mockGoogleOAuth2Client{}
 }
 
+func (m *mockGoogleOAuth2Client) EnsureKeySet(ctx context.Context) error {
+	return m.ensureKeySetErr
+}
+
 func (m *mockGoogleOAuth2Client) VerifyIDToken(ctx context.Context, signed string) (*client.GoogleOAuth2Token, error) {
 	user := getMockUser()
 	token := jwt.New()
@@ -248,7 +253,10 @@ func TestAuthGC(t *testing.T) {
 	}
 
 	// wait for gc to complete
-	time.Sleep(2 * time.Second)
+	if err := p.AuthCollection().Wait(ctx); err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
 
 	user := getMockUser()
 
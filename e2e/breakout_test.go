@@ -0,0 +1,248 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/client"
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/provider"
+	"github.com/aravindanve/livemeet-server/src/resource"
+	"github.com/gorilla/mux"
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/twitchtv/twirp"
+)
+
+type mockBreakoutProvider struct {
+	provider.Provider
+	livekitClient *mockLiveKitClient
+}
+
+func newMockBreakoutProvider(ctx context.Context) *mockBreakoutProvider {
+	p := provider.NewProvider(ctx)
+	return &mockBreakoutProvider{Provider: p, livekitClient: newMockLiveKitClient()}
+}
+
+func (m *mockBreakoutProvider) LiveKitClient() client.LiveKitClient {
+	return m.livekitClient
+}
+
+func newMockBreakouts(t *testing.T, r *mux.Router, meeting resource.Meeting) []resource.Breakout {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/breakouts", strings.NewReader(
+		`{"count":2,"names":["Design"]}`,
+	))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Fatalf("expected status to be %#v got %#v", http.StatusOK, s)
+	}
+
+	var m struct {
+		Breakouts []resource.Breakout `json:"breakouts"`
+	}
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Fatalf("expected error to be nil got %#v", err)
+	}
+	return m.Breakouts
+}
+
+func TestBreakoutCreate(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockBreakoutProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterBreakoutRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	// test breakouts
+	breakouts := newMockBreakouts(t, r, meeting)
+	if len(breakouts) != 2 {
+		t.Errorf("expected breakouts to have 2 items got %#v", len(breakouts))
+		return
+	}
+	if breakouts[0].Name != "Design" || breakouts[1].Name != "Room 2" {
+		t.Errorf("expected breakout names to be %q and %q got %q and %q", "Design", "Room 2", breakouts[0].Name, breakouts[1].Name)
+		return
+	}
+	if breakouts[1].RoomName != meeting.Code+"_breakout_2" {
+		t.Errorf("expected room name to be %q got %q", meeting.Code+"_breakout_2", breakouts[1].RoomName)
+		return
+	}
+
+	// test second create conflicts
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/breakouts", strings.NewReader(`{"count":1}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusConflict {
+		t.Errorf("expected status to be %#v got %#v", http.StatusConflict, s)
+		return
+	}
+}
+
+func TestBreakoutAssignAndRoomToken(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockBreakoutProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+	participantID := resource.NewResourceID()
+	p.livekitClient.participants = []*livekit.ParticipantInfo{{Identity: string(participantID)}}
+
+	r := resource.RegisterBreakoutRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	breakouts := newMockBreakouts(t, r, meeting)
+
+	// test assign unknown participant
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID)+"/breakouts/"+string(breakouts[0].ID),
+		strings.NewReader(`{"participantIds":["`+string(resource.NewResourceID())+`"]}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusBadRequest {
+		t.Errorf("expected status to be %#v got %#v", http.StatusBadRequest, s)
+		return
+	}
+
+	// test assign
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID)+"/breakouts/"+string(breakouts[0].ID),
+		strings.NewReader(`{"participantIds":["`+string(participantID)+`"]}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+	if p.livekitClient.sendDataReq == nil {
+		t.Errorf("expected data to be sent got nil")
+		return
+	}
+
+	// create conference room token
+	cf := p.LiveKitConfig()
+	token, err := auth.NewAccessToken(cf.APIKey, cf.APISecret).
+		AddGrant(&auth.VideoGrant{Room: meeting.Code, RoomJoin: true}).
+		SetIdentity(string(participantID)).
+		SetValidFor(2 * time.Minute).
+		ToJWT()
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	// test room token for other breakout
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/breakouts/"+string(breakouts[1].ID)+"/roomTokens", nil)
+	req.Header.Set("authorization", "Bearer "+token)
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusUnauthorized {
+		t.Errorf("expected status to be %#v got %#v", http.StatusUnauthorized, s)
+		return
+	}
+
+	// test room token for assigned breakout
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/breakouts/"+string(breakouts[0].ID)+"/roomTokens", nil)
+	req.Header.Set("authorization", "Bearer "+token)
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	var m resource.RoomToken
+	err = json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if m.RoomType != resource.RoomType_Breakout || m.RoomName != breakouts[0].RoomName {
+		t.Errorf("expected breakout room token for %q got %#v", breakouts[0].RoomName, m)
+		return
+	}
+
+	// test close
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/breakouts:close", nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusNoContent {
+		t.Errorf("expected status to be %#v got %#v", http.StatusNoContent, s)
+		return
+	}
+}
+
+func TestBreakoutAssignRoomNotFound(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockBreakoutProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterBreakoutRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	breakouts := newMockBreakouts(t, r, meeting)
+
+	// conference room is empty
+	p.livekitClient.listParticipantsErr = twirp.NotFoundError("requested room does not exist")
+
+	// test assign unknown participant
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID)+"/breakouts/"+string(breakouts[0].ID),
+		strings.NewReader(`{"participantIds":["`+string(resource.NewResourceID())+`"]}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusBadRequest {
+		t.Errorf("expected status to be %#v got %#v", http.StatusBadRequest, s)
+		return
+	}
+
+	// test assign when notifying fails
+	p.livekitClient.sendDataErr = errors.New("livekit unavailable")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID)+"/breakouts/"+string(breakouts[0].ID),
+		strings.NewReader(`{"participantIds":[]}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+	if p.livekitClient.sendDataReq == nil {
+		t.Errorf("expected data to be sent got nil")
+		return
+	}
+}
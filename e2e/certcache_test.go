@@ -0,0 +1,53 @@
+package main_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/aravindanve/livemeet-server/src/client"
+	"github.com/aravindanve/livemeet-server/src/provider"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func TestMongoCertCache(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	c := client.NewMongoCertCache(p.MongoDatabase())
+	key := "example.com+" + primitive.NewObjectID().Hex()
+
+	if _, err := c.Get(ctx, key); !errors.Is(err, autocert.ErrCacheMiss) {
+		t.Errorf("expected error to be cache miss got %#v", err)
+		return
+	}
+
+	for _, data := range [][]byte{[]byte("first"), []byte("second")} {
+		if err := c.Put(ctx, key, data); err != nil {
+			t.Errorf("expected error to be nil got %#v", err)
+			return
+		}
+		got, err := c.Get(ctx, key)
+		if err != nil {
+			t.Errorf("expected error to be nil got %#v", err)
+			return
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("expected data to be %q got %q", data, got)
+			return
+		}
+	}
+
+	if err := c.Delete(ctx, key); err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if _, err := c.Get(ctx, key); !errors.Is(err, autocert.ErrCacheMiss) {
+		t.Errorf("expected error to be cache miss got %#v", err)
+	}
+}
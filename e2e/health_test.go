@@ -0,0 +1,143 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aravindanve/livemeet-server/src/client"
+	"github.com/aravindanve/livemeet-server/src/provider"
+	"github.com/aravindanve/livemeet-server/src/resource"
+	"github.com/gorilla/mux"
+)
+
+type mockHealthProvider struct {
+	provider.Provider
+	livekitClient      *mockLiveKitClient
+	googleOAuth2Client *mockGoogleOAuth2Client
+}
+
+func newMockHealthProvider(ctx context.Context) *mockHealthProvider {
+	p := provider.NewProvider(ctx)
+	return &mockHealthProvider{
+		Provider:           p,
+		livekitClient:      newMockLiveKitClient(),
+		googleOAuth2Client: &mockGoogleOAuth2Client{},
+	}
+}
+
+func (m *mockHealthProvider) LiveKitClient() client.LiveKitClient {
+	return m.livekitClient
+}
+
+func (m *mockHealthProvider) GoogleOAuth2Client() client.GoogleOAuth2Client {
+	return m.googleOAuth2Client
+}
+
+func TestHealthLive(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockHealthProvider(ctx)
+	defer p.Release(ctx)
+
+	r := resource.RegisterHealthRoutes(mux.NewRouter(), p)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+}
+
+func TestHealthReady(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockHealthProvider(ctx)
+	defer p.Release(ctx)
+
+	r := resource.RegisterHealthRoutes(mux.NewRouter(), p)
+
+	// test ready
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	var m resource.Health
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(m.Checks) != 3 || m.Checks["mongo"].Status != resource.HealthStatus_OK {
+		t.Errorf("expected 3 passing checks got %#v", m.Checks)
+		return
+	}
+
+	// test google jwks unreachable does not gate readiness
+	p.googleOAuth2Client.ensureKeySetErr = errors.New("connection refused")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	m = resource.Health{}
+	err = json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if c := m.Checks["googleJwks"]; c.Status != resource.HealthStatus_Unavailable {
+		t.Errorf("expected googleJwks check to be unavailable got %#v", c)
+		return
+	}
+
+	// test livekit unreachable
+	p.livekitClient.pingErr = errors.New("dial tcp 10.0.0.5:7880: connection refused")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusServiceUnavailable {
+		t.Errorf("expected status to be %#v got %#v", http.StatusServiceUnavailable, s)
+		return
+	}
+
+	body := w.Body.String()
+	if strings.Contains(body, "10.0.0.5") {
+		t.Errorf("expected errors not to be exposed got %s", body)
+		return
+	}
+
+	m = resource.Health{}
+	err = json.NewDecoder(strings.NewReader(body)).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if c := m.Checks["livekit"]; c.Status != resource.HealthStatus_Unavailable {
+		t.Errorf("expected livekit check to be unavailable got %#v", c)
+		return
+	}
+}
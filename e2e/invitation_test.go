@@ -0,0 +1,227 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aravindanve/livemeet-server/src/client"
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/provider"
+	"github.com/aravindanve/livemeet-server/src/resource"
+	"github.com/gorilla/mux"
+)
+
+type mockInvitationProvider struct {
+	provider.Provider
+	mailer *mockMailer
+}
+
+func newMockInvitationProvider(ctx context.Context) *mockInvitationProvider {
+	p := provider.NewProvider(ctx)
+	return &mockInvitationProvider{Provider: p, mailer: &mockMailer{}}
+}
+
+func (m *mockInvitationProvider) Mailer() client.Mailer {
+	return m.mailer
+}
+
+type mockMailer struct {
+	mut    sync.Mutex
+	mails  []*client.Mail
+	failTo string // recipient that fails
+}
+
+func (m *mockMailer) Send(ctx context.Context, mail *client.Mail) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	for _, to := range mail.To {
+		if to == m.failTo {
+			return errors.New("mailbox unavailable")
+		}
+	}
+	m.mails = append(m.mails, mail)
+	return nil
+}
+
+var invitationTokenRegexp = regexp.MustCompile(`invitation=([^\s]+)`)
+
+func TestInvitationCreate(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockInvitationProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterInvitationRoutes(mux.NewRouter(), p)
+	resource.RegisterParticipantRoutes(r, p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/invitations", strings.NewReader(
+		`{"emails":["Guest@example.com","guest@example.com"]}`,
+	))
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m struct {
+		Invitations []resource.Invitation `json:"invitations"`
+	}
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(m.Invitations) != 1 {
+		t.Errorf("expected invitations to have 1 items got %#v", len(m.Invitations))
+		return
+	}
+	if m.Invitations[0].Email != "guest@example.com" {
+		t.Errorf("expected email to be %q got %q", "guest@example.com", m.Invitations[0].Email)
+		return
+	}
+
+	// test mailer
+	if len(p.mailer.mails) != 1 {
+		t.Errorf("expected mails to have 1 items got %#v", len(p.mailer.mails))
+		return
+	}
+	match := invitationTokenRegexp.FindStringSubmatch(p.mailer.mails[0].Text)
+	if match == nil {
+		t.Errorf("expected invitation link in mail got %q", p.mailer.mails[0].Text)
+		return
+	}
+	token, _ := url.QueryUnescape(match[1])
+	body, _ := json.Marshal(map[string]string{"name": "Guest", "invitation": token})
+
+	// test invitation pre-admits participant
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(string(body)))
+	r.ServeHTTP(w, req)
+
+	s = w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+	var pm resource.ParticipantWithRoomTokens
+	err = json.NewDecoder(w.Result().Body).Decode(&pm)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if pm.Status != resource.ParticipantStatus_Admitted {
+		t.Errorf(`expected status to be %q got %q`, resource.ParticipantStatus_Admitted, pm.Status)
+		return
+	}
+
+	// test invitation is single use
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(string(body)))
+	r.ServeHTTP(w, req)
+
+	s = w.Result().StatusCode
+	if s != http.StatusBadRequest {
+		t.Errorf("expected status to be %#v got %#v", http.StatusBadRequest, s)
+		return
+	}
+}
+
+func TestInvitationCreateWithFailedMail(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockInvitationProvider(ctx)
+	defer p.Release(ctx)
+	p.mailer.failTo = "failing@example.com"
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterInvitationRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/invitations", strings.NewReader(
+		`{"emails":["first@example.com","failing@example.com","last@example.com"]}`,
+	))
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m struct {
+		Invitations []resource.Invitation        `json:"invitations"`
+		Failed      []resource.InvitationFailure `json:"failed"`
+	}
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(m.Invitations) != 2 || m.Invitations[0].Email != "first@example.com" || m.Invitations[1].Email != "last@example.com" {
+		t.Errorf("expected first and last to be invited got %#v", m.Invitations)
+		return
+	}
+	if len(m.Failed) != 1 || m.Failed[0].Email != "failing@example.com" {
+		t.Errorf("expected failing to be reported got %#v", m.Failed)
+		return
+	}
+}
+
+func TestInvitationCreateBadAuth(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockInvitationProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterInvitationRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/invitations", strings.NewReader(
+		`{"emails":["guest@example.com"]}`,
+	))
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusUnauthorized {
+		t.Errorf("expected status to be %#v got %#v", http.StatusUnauthorized, s)
+		return
+	}
+}
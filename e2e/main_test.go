@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aravindanve/livemeet-server/src/provider"
 	"github.com/ory/dockertest/v3"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -75,6 +76,15 @@ func setupMain() {
 	}); err != nil {
 		log.Panicf("could not connect to mongo container: %s", err)
 	}
+
+	// create indexes
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+	if err := p.EnsureIndexes(ctx); err != nil {
+		log.Panicf("could not create indexes: %s", err)
+	}
 }
 
 func teardownMain(code *int) {
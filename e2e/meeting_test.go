@@ -34,10 +34,11 @@ func newMockMeeting(ctx context.Context) resource.Meeting {
 	code = code[:4] + "-" + code[4:8] + "-" + code[8:]
 
 	user := getMockUser()
+	expiresAt := time.Now().Add(1 * time.Hour)
 	mockMeeting := &resource.Meeting{
 		UserID:    user.ID,
 		Code:      code,
-		ExpiresAt: time.Now().Add(1 * time.Hour),
+		ExpiresAt: &expiresAt,
 	}
 
 	err = p.MeetingCollection().Save(ctx, mockMeeting)
@@ -212,3 +213,209 @@ func TestMeetingRetrieve(t *testing.T) {
 		return
 	}
 }
+
+func TestMeetingUpdate(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterMeetingRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID), strings.NewReader(
+		`{"lobby":{"bypass":"domains","domains":["Example.com"]}}`,
+	))
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m resource.Meeting
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if m.Lobby.Bypass != resource.MeetingLobbyBypass_Domains {
+		t.Errorf("expected lobby bypass to be %q got %q", resource.MeetingLobbyBypass_Domains, m.Lobby.Bypass)
+		return
+	}
+	if len(m.Lobby.Domains) != 1 || m.Lobby.Domains[0] != "example.com" {
+		t.Errorf("expected lobby domains to be %#v got %#v", []string{"example.com"}, m.Lobby.Domains)
+		return
+	}
+}
+
+func TestMeetingCreatePersonal(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	r := resource.RegisterMeetingRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings", strings.NewReader(`{"code":"Mock-Standup"}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m resource.Meeting
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if m.Code != "mock-standup" {
+		t.Errorf("expected code to be %q got %q", "mock-standup", m.Code)
+		return
+	}
+	if !m.Personal {
+		t.Errorf("expected personal to be true got %#v", m.Personal)
+		return
+	}
+	if m.ExpiresAt != nil {
+		t.Errorf("expected expiresAt to be nil got %#v", m.ExpiresAt)
+		return
+	}
+
+	// test second personal meeting conflicts
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings", strings.NewReader(`{"code":"mock-standup-2"}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	s = w.Result().StatusCode
+	if s != http.StatusConflict {
+		t.Errorf("expected status to be %#v got %#v", http.StatusConflict, s)
+		return
+	}
+}
+
+func TestMeetingCreatePersonalBadCode(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	r := resource.RegisterMeetingRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	for _, code := range []string{"abcd-efgh-ijk", "admin", "a"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/meetings", strings.NewReader(`{"code":"`+code+`"}`))
+		req.Header.Set("authorization", getMockAuthHeader())
+
+		// test route
+		r.ServeHTTP(w, req)
+
+		// test status code
+		s := w.Result().StatusCode
+		if s != http.StatusBadRequest {
+			t.Errorf("expected status for %q to be %#v got %#v", code, http.StatusBadRequest, s)
+			return
+		}
+	}
+}
+
+func TestMeetingExtend(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterMeetingRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+":extend", nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m resource.Meeting
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if m.ExpiresAt == nil || !m.ExpiresAt.After(*meeting.ExpiresAt) {
+		t.Errorf("expected expiresAt to be after %v got %v", *meeting.ExpiresAt, m.ExpiresAt)
+		return
+	}
+}
+
+func TestMeetingRetrieveExpired(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	// expire meeting
+	meeting := newMockMeeting(ctx)
+	expiresAt := time.Now().Add(-1 * time.Minute)
+	meeting.ExpiresAt = &expiresAt
+
+	err := p.MeetingCollection().Save(ctx, &meeting)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	r := resource.RegisterMeetingRoutes(mux.NewRouter(), p)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/meetings/"+string(meeting.ID), nil)
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusNotFound {
+		t.Errorf("expected status to be %#v got %#v", http.StatusNotFound, s)
+		return
+	}
+}
@@ -0,0 +1,208 @@
+package main_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/resource"
+	"github.com/gorilla/mux"
+	"github.com/livekit/protocol/auth"
+)
+
+func TestMessageCreateAndSearch(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockBreakoutProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterMessageRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	// create conference room token
+	cf := p.LiveKitConfig()
+	token, err := auth.NewAccessToken(cf.APIKey, cf.APISecret).
+		AddGrant(&auth.VideoGrant{Room: meeting.Code, RoomJoin: true}).
+		SetIdentity(string(resource.NewResourceID())).
+		SetMetadata(`{"name":"Jane"}`).
+		SetValidFor(2 * time.Minute).
+		ToJWT()
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	// test create
+	for _, text := range []string{"hello", "world", "again"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/messages",
+			strings.NewReader(`{"text":"`+text+`"}`))
+		req.Header.Set("authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+
+		if s := w.Result().StatusCode; s != http.StatusOK {
+			t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+			return
+		}
+
+		var m resource.Message
+		err = json.NewDecoder(w.Result().Body).Decode(&m)
+		if err != nil {
+			t.Errorf("expected error to be nil got %#v", err)
+			return
+		}
+		if m.Text != text || m.Name != "Jane" || m.RoomName != meeting.Code {
+			t.Errorf("expected message %q from %q got %#v", text, "Jane", m)
+			return
+		}
+		if p.livekitClient.sendDataReq == nil || p.livekitClient.sendDataReq.Room != meeting.Code {
+			t.Errorf("expected data to be sent to %q got %#v", meeting.Code, p.livekitClient.sendDataReq)
+			return
+		}
+	}
+
+	// test search with room token
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/meetings/"+string(meeting.ID)+"/messages?limit=2", nil)
+	req.Header.Set("authorization", "Bearer "+token)
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	var m struct {
+		Messages   []resource.Message   `json:"messages"`
+		NextBefore *resource.ResourceID `json:"nextBefore"`
+	}
+	err = json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(m.Messages) != 2 || m.Messages[0].Text != "world" || m.Messages[1].Text != "again" {
+		t.Errorf("expected messages %q and %q got %#v", "world", "again", m.Messages)
+		return
+	}
+	if m.NextBefore == nil {
+		t.Errorf("expected nextBefore to be set got nil")
+		return
+	}
+
+	// test search older as meeting admin
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/meetings/"+string(meeting.ID)+"/messages?limit=2&before="+string(*m.NextBefore), nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	err = json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(m.Messages) != 1 || m.Messages[0].Text != "hello" || m.NextBefore != nil {
+		t.Errorf("expected message %q and no nextBefore got %#v", "hello", m)
+		return
+	}
+}
+
+func TestMessageCreateBadAuth(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockBreakoutProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterMessageRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	// create room token for another room
+	cf := p.LiveKitConfig()
+	token, err := auth.NewAccessToken(cf.APIKey, cf.APISecret).
+		AddGrant(&auth.VideoGrant{Room: "other", RoomJoin: true}).
+		SetIdentity(string(resource.NewResourceID())).
+		SetValidFor(2 * time.Minute).
+		ToJWT()
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	// test create
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/messages", strings.NewReader(`{"text":"hello"}`))
+	req.Header.Set("authorization", "Bearer "+token)
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusUnauthorized {
+		t.Errorf("expected status to be %#v got %#v", http.StatusUnauthorized, s)
+		return
+	}
+}
+
+func TestMessageCreateWithFailedRelay(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockBreakoutProvider(ctx)
+	defer p.Release(ctx)
+	p.livekitClient.sendDataErr = errors.New("livekit unavailable")
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterMessageRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	// create conference room token
+	cf := p.LiveKitConfig()
+	token, err := auth.NewAccessToken(cf.APIKey, cf.APISecret).
+		AddGrant(&auth.VideoGrant{Room: meeting.Code, RoomJoin: true}).
+		SetIdentity(string(resource.NewResourceID())).
+		SetValidFor(2 * time.Minute).
+		ToJWT()
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	// test create
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/messages", strings.NewReader(`{"text":"hello"}`))
+	req.Header.Set("authorization", "Bearer "+token)
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	var m resource.Message
+	err = json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if m.ID == "" || m.Text != "hello" {
+		t.Errorf("expected saved message got %#v", m)
+		return
+	}
+}
@@ -0,0 +1,160 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/provider"
+	"github.com/aravindanve/livemeet-server/src/resource"
+	"github.com/gorilla/mux"
+)
+
+func newMockRecurringMeeting(ctx context.Context, p provider.Provider) resource.Meeting {
+	meeting := newMockMeeting(ctx)
+	meeting.Recurrence = &resource.MeetingRecurrence{
+		Rule:     "FREQ=DAILY",
+		Start:    time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC),
+		Duration: 30,
+		TimeZone: "UTC",
+	}
+
+	err := p.MeetingCollection().Save(ctx, &meeting)
+	if err != nil {
+		panic("error saving meeting: " + err.Error())
+	}
+	return meeting
+}
+
+func TestOccurrenceSearch(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockRecurringMeeting(ctx, p)
+
+	r := resource.RegisterOccurrenceRoutes(mux.NewRouter(), p)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/meetings/"+string(meeting.ID)+
+		"/occurrences?from=2023-01-03T00:00:00Z&to=2023-01-06T00:00:00Z", nil)
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m struct {
+		Occurrences []resource.Occurrence `json:"occurrences"`
+	}
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(m.Occurrences) != 3 {
+		t.Errorf("expected occurrences to have 3 items got %#v", len(m.Occurrences))
+		return
+	}
+	if m.Occurrences[0].ID != "20230103T090000Z" {
+		t.Errorf("expected occurrence id to be %q got %q", "20230103T090000Z", m.Occurrences[0].ID)
+		return
+	}
+}
+
+func TestOccurrenceUpdate(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockRecurringMeeting(ctx, p)
+
+	r := resource.RegisterOccurrenceRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	// cancel one occurrence and move another out of range
+	for _, b := range []struct{ id, body string }{
+		{"20230103T090000Z", `{"cancelled":true}`},
+		{"20230104T090000Z", `{"start":"2023-01-07T09:00:00Z","end":"2023-01-07T10:00:00Z"}`},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID)+"/occurrences/"+b.id,
+			strings.NewReader(b.body))
+		req.Header.Set("authorization", getMockAuthHeader())
+		r.ServeHTTP(w, req)
+
+		s := w.Result().StatusCode
+		if s != http.StatusOK {
+			t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+			return
+		}
+	}
+
+	// test search
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/meetings/"+string(meeting.ID)+
+		"/occurrences?from=2023-01-03T00:00:00Z&to=2023-01-06T00:00:00Z", nil)
+	r.ServeHTTP(w, req)
+
+	var m struct {
+		Occurrences []resource.Occurrence `json:"occurrences"`
+	}
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(m.Occurrences) != 2 {
+		t.Errorf("expected occurrences to have 2 items got %#v", len(m.Occurrences))
+		return
+	}
+	if !m.Occurrences[0].Cancelled {
+		t.Errorf("expected first occurrence to be cancelled")
+		return
+	}
+}
+
+func TestOccurrenceUpdateNotFound(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockRecurringMeeting(ctx, p)
+
+	r := resource.RegisterOccurrenceRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID)+"/occurrences/20230103T100000Z",
+		strings.NewReader(`{"cancelled":true}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusNotFound {
+		t.Errorf("expected status to be %#v got %#v", http.StatusNotFound, s)
+		return
+	}
+}
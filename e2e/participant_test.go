@@ -3,10 +3,12 @@ package main_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -44,10 +46,9 @@ func newMockMeetingAndParticipant(ctx context.Context) (resource.Meeting, resour
 }
 
 type mockParticipantProvider struct {
-	config.AuthConfigProvider
 	resource.ParticipantDeps
 	livekitClient *mockLiveKitClient
-	Release       func(ctx context.Context)
+	Release       func(ctx context.Context) error
 }
 
 func newMockParticipantProvider(ctx context.Context) *mockParticipantProvider {
@@ -55,10 +56,9 @@ func newMockParticipantProvider(ctx context.Context) *mockParticipantProvider {
 	livekitClient := newMockLiveKitClient()
 
 	return &mockParticipantProvider{
-		AuthConfigProvider: p,
-		ParticipantDeps:    p,
-		Release:            p.Release,
-		livekitClient:      livekitClient,
+		ParticipantDeps: p,
+		Release:         p.Release,
+		livekitClient:   livekitClient,
 	}
 }
 
@@ -67,7 +67,11 @@ func (m *mockParticipantProvider) LiveKitClient() client.LiveKitClient {
 }
 
 type mockLiveKitClient struct {
-	sendDataReq *livekit.SendDataRequest
+	sendDataReq         *livekit.SendDataRequest
+	sendDataErr         error
+	participants        []*livekit.ParticipantInfo
+	listParticipantsErr error
+	pingErr             error
 }
 
 func newMockLiveKitClient() *mockLiveKitClient {
@@ -76,9 +80,23 @@ func newMockLiveKitClient() *mockLiveKitClient {
 
 func (m *mockLiveKitClient) SendData(ctx context.Context, req *livekit.SendDataRequest) (*livekit.SendDataResponse, error) {
 	m.sendDataReq = req
+	if m.sendDataErr != nil {
+		return nil, m.sendDataErr
+	}
 	return &livekit.SendDataResponse{}, nil
 }
 
+func (m *mockLiveKitClient) ListParticipants(ctx context.Context, req *livekit.ListParticipantsRequest) (*livekit.ListParticipantsResponse, error) {
+	if m.listParticipantsErr != nil {
+		return nil, m.listParticipantsErr
+	}
+	return &livekit.ListParticipantsResponse{Participants: m.participants}, nil
+}
+
+func (m *mockLiveKitClient) Ping(ctx context.Context) error {
+	return m.pingErr
+}
+
 func TestParticipantCreateWithAuth(t *testing.T) {
 	t.Parallel()
 	defer panicGuard(t)
@@ -471,7 +489,7 @@ func TestParticipantUpdateBadAuth(t *testing.T) {
 	req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID)+"/participants/"+string(participant.ID), strings.NewReader(`{"status":"denied"}`))
 
 	// create auth header
-	cf := config.NewAuthConfigProvider().AuthConfig()
+	cf := config.NewConfig().AuthConfig()
 	token, err := jwt.NewBuilder().
 		Issuer(cf.Issuer).
 		Expiration(time.Now().Add(cf.TTL)).
@@ -519,3 +537,608 @@ func TestParticipantUpdateBadAuth(t *testing.T) {
 		return
 	}
 }
+
+func TestParticipantCreateWithMessage(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{"name":"My Name","message":" From the design team "}`))
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m resource.ParticipantWithRoomTokens
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if m.Message == nil || *m.Message != "From the design team" {
+		t.Errorf(`expected message to be %q got %#v`, "From the design team", m.Message)
+		return
+	}
+
+	// test waiting room token metadata
+	if len(m.RoomTokens) != 1 || m.RoomTokens[0].RoomType != resource.RoomType_Waiting {
+		t.Errorf("expected only a waiting room token got %#v", m.RoomTokens)
+		return
+	}
+	verifier, err := auth.ParseAPIToken(m.RoomTokens[0].AccessToken)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	claims, err := verifier.Verify(p.LiveKitConfig().APISecret)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	var metadata resource.ParticipantMetadata
+	err = json.Unmarshal([]byte(claims.Metadata), &metadata)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if metadata.Message == nil || *metadata.Message != "From the design team" {
+		t.Errorf(`expected metadata message to be %q got %#v`, "From the design team", metadata.Message)
+		return
+	}
+}
+
+func TestParticipantCreateNoName(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{"message":"Hello"}`))
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusBadRequest {
+		t.Errorf("expected status to be %#v got %#v", http.StatusBadRequest, s)
+		return
+	}
+}
+
+func TestParticipantUpdateDeniedWithMessage(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting, participant := newMockMeetingAndParticipant(ctx)
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID)+"/participants/"+string(participant.ID), strings.NewReader(`{"status":"denied","message":"Please use your work account"}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test livekit send data
+	if p.livekitClient.sendDataReq == nil {
+		t.Errorf("expected livekit send data to be set got %#v", p.livekitClient.sendDataReq)
+		return
+	}
+	d, err := resource.DecodeData(p.livekitClient.sendDataReq.Data)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	data, ok := d.(*resource.ParticipantDeniedData)
+	if !ok {
+		t.Errorf(`expected data to be participant denied got %#v`, d)
+		return
+	}
+	if data.Message == nil || *data.Message != "Please use your work account" {
+		t.Errorf(`expected message to be %q got %#v`, "Please use your work account", data.Message)
+		return
+	}
+}
+
+func TestParticipantUpdateAdmittedWithMessage(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting, participant := newMockMeetingAndParticipant(ctx)
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID)+"/participants/"+string(participant.ID), strings.NewReader(`{"status":"admitted","message":"Welcome"}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusBadRequest {
+		t.Errorf("expected status to be %#v got %#v", http.StatusBadRequest, s)
+		return
+	}
+}
+
+func TestParticipantCreateLobbyBypass(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+	meeting.Lobby = resource.MeetingLobby{Bypass: resource.MeetingLobbyBypass_Everyone, Domains: []string{}}
+	err := p.MeetingCollection().Save(ctx, &meeting)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{"name":"My Name","message":"From the design team"}`))
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m resource.ParticipantWithRoomTokens
+	err = json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if m.Status != resource.ParticipantStatus_Admitted {
+		t.Errorf(`expected status to be %q got %q`, resource.ParticipantStatus_Admitted, m.Status)
+		return
+	}
+	if len(m.RoomTokens) != 1 {
+		t.Errorf("expected roomTokens to have 1 items got %#v", len(m.RoomTokens))
+		return
+	}
+	if m.RoomTokens[0].RoomType != resource.RoomType_Conference {
+		t.Errorf(`expected roomType in room token 0 to be %q got %q`, resource.RoomType_Conference, m.RoomTokens[0].RoomType)
+		return
+	}
+
+	// test message is not shared with the conference room
+	verifier, err := auth.ParseAPIToken(m.RoomTokens[0].AccessToken)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	claims, err := verifier.Verify(p.LiveKitConfig().APISecret)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	var metadata resource.ParticipantMetadata
+	err = json.Unmarshal([]byte(claims.Metadata), &metadata)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if metadata.Message != nil {
+		t.Errorf("expected metadata message to be nil got %#v", *metadata.Message)
+		return
+	}
+}
+
+func TestParticipantAdmitAll(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting, participant := newMockMeetingAndParticipant(ctx)
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants:admitAll", nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m struct {
+		Participants []resource.Participant `json:"participants"`
+	}
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(m.Participants) != 1 {
+		t.Errorf("expected participants to have 1 items got %#v", len(m.Participants))
+		return
+	}
+	if m.Participants[0].ID != participant.ID {
+		t.Errorf("expected participant id to be %q got %q", participant.ID, m.Participants[0].ID)
+		return
+	}
+	if m.Participants[0].Status != resource.ParticipantStatus_Admitted {
+		t.Errorf(`expected status to be %q got %q`, resource.ParticipantStatus_Admitted, m.Participants[0].Status)
+		return
+	}
+
+	// test livekit send data
+	if p.livekitClient.sendDataReq == nil {
+		t.Errorf("expected livekit send data to be set got %#v", p.livekitClient.sendDataReq)
+	}
+}
+
+func TestParticipantAdmitAllWithFailedNotification(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+	p.livekitClient.sendDataErr = errors.New("livekit unavailable")
+
+	meeting, participant := newMockMeetingAndParticipant(ctx)
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants:admitAll", nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m struct {
+		Participants []resource.Participant             `json:"participants"`
+		Failed       []resource.ParticipantAdmitFailure `json:"failed"`
+	}
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if len(m.Participants) != 1 || m.Participants[0].ID != participant.ID {
+		t.Errorf("expected participant %q to be admitted got %#v", participant.ID, m.Participants)
+		return
+	}
+	if len(m.Failed) != 0 {
+		t.Errorf("expected failed to have 0 items got %#v", len(m.Failed))
+		return
+	}
+
+	// test participant is saved
+	saved, err := p.ParticipantCollection().FindOneByID(ctx, participant.ID)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if saved.Status != resource.ParticipantStatus_Admitted {
+		t.Errorf(`expected status to be %q got %q`, resource.ParticipantStatus_Admitted, saved.Status)
+		return
+	}
+}
+
+func TestParticipantAdmitAllBadAuth(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting, _ := newMockMeetingAndParticipant(ctx)
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants:admitAll", nil)
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusUnauthorized {
+		t.Errorf("expected status to be %#v got %#v", http.StatusUnauthorized, s)
+		return
+	}
+}
+
+func TestParticipantCreateWithPasscode(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+	mp := provider.NewProvider(ctx)
+	defer mp.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	resource.RegisterMeetingRoutes(r, mp)
+	r.Use(middleware.AuthMiddleware(mp))
+
+	// set meeting passcode
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID), strings.NewReader(`{"passcode":"secret-passcode"}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test missing passcode
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{"name":"My Name"}`))
+	r.ServeHTTP(w, req)
+
+	s = w.Result().StatusCode
+	if s != http.StatusUnauthorized {
+		t.Errorf("expected status to be %#v got %#v", http.StatusUnauthorized, s)
+		return
+	}
+
+	// test wrong passcode
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{"name":"My Name","passcode":"wrong-passcode"}`))
+	r.ServeHTTP(w, req)
+
+	s = w.Result().StatusCode
+	if s != http.StatusUnauthorized {
+		t.Errorf("expected status to be %#v got %#v", http.StatusUnauthorized, s)
+		return
+	}
+
+	// test passcode
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{"name":"My Name","passcode":"secret-passcode"}`))
+	r.ServeHTTP(w, req)
+
+	s = w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test owner does not need passcode
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	s = w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+}
+
+func TestParticipantCreateWithPasscodeLimit(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+	mp := provider.NewProvider(ctx)
+	defer mp.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	resource.RegisterMeetingRoutes(r, mp)
+	r.Use(middleware.AuthMiddleware(mp))
+
+	// set meeting passcode
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID), strings.NewReader(`{"passcode":"secret-passcode"}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test parallel wrong passcodes are limited
+	max := p.ParticipantConfig().PasscodeFailuresMaxPerIP
+	statuses := make(chan int, max*2)
+	var wg sync.WaitGroup
+	for i := 0; i < max*2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{"name":"My Name","passcode":"wrong-passcode"}`))
+			r.ServeHTTP(w, req)
+			statuses <- w.Result().StatusCode
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+
+	unauthorized := 0
+	for s := range statuses {
+		if s == http.StatusUnauthorized {
+			unauthorized++
+		} else if s != http.StatusTooManyRequests {
+			t.Errorf("expected status to be %#v or %#v got %#v", http.StatusUnauthorized, http.StatusTooManyRequests, s)
+			return
+		}
+	}
+	if unauthorized != max {
+		t.Errorf("expected %d passcodes to be checked got %d", max, unauthorized)
+		return
+	}
+
+	// test other clients are not locked out
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{"name":"My Name","passcode":"secret-passcode"}`))
+	req.RemoteAddr = "198.51.100.1:1234"
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+}
+
+func TestParticipantCreateWithPasscodeMeetingLimit(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := newMockParticipantProvider(ctx)
+	defer p.Release(ctx)
+	mp := provider.NewProvider(ctx)
+	defer mp.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	resource.RegisterMeetingRoutes(r, mp)
+	r.Use(middleware.AuthMiddleware(mp))
+
+	// set meeting passcode
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/meetings/"+string(meeting.ID), strings.NewReader(`{"passcode":"secret-passcode"}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test wrong passcodes from many addresses, each under the ip limit, are
+	// limited per meeting
+	cf := p.ParticipantConfig()
+	perIP := cf.PasscodeFailuresMaxPerIP - 1
+	ips := cf.PasscodeFailuresMaxPerMeeting/perIP + 2
+	statuses := make(chan int, ips*perIP)
+	var wg sync.WaitGroup
+	for i := 0; i < ips; i++ {
+		remoteAddr := fmt.Sprintf("2001:db8::%x", i+1)
+		for j := 0; j < perIP; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{"name":"My Name","passcode":"wrong-passcode"}`))
+				req.RemoteAddr = "[" + remoteAddr + "]:1234"
+				r.ServeHTTP(w, req)
+				statuses <- w.Result().StatusCode
+			}()
+		}
+	}
+	wg.Wait()
+	close(statuses)
+
+	unauthorized := 0
+	for s := range statuses {
+		if s == http.StatusUnauthorized {
+			unauthorized++
+		} else if s != http.StatusTooManyRequests {
+			t.Errorf("expected status to be %#v or %#v got %#v", http.StatusUnauthorized, http.StatusTooManyRequests, s)
+			return
+		}
+	}
+	if unauthorized != cf.PasscodeFailuresMaxPerMeeting {
+		t.Errorf("expected %d passcodes to be checked got %d", cf.PasscodeFailuresMaxPerMeeting, unauthorized)
+		return
+	}
+
+	// test new addresses are limited too
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{"name":"My Name","passcode":"wrong-passcode"}`))
+	req.RemoteAddr = "198.51.100.1:1234"
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusTooManyRequests {
+		t.Errorf("expected status to be %#v got %#v", http.StatusTooManyRequests, s)
+		return
+	}
+}
@@ -0,0 +1,48 @@
+package main_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/provider"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMongoRateLimitStore(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	s := middleware.NewMongoRateLimitStore(p.MongoDatabase())
+	key := "test:" + primitive.NewObjectID().Hex()
+	limit := middleware.RateLimit{Requests: 2, Per: time.Minute}
+
+	for i, expected := range []bool{true, true, false} {
+		allowed, retryAfter, err := s.Take(ctx, key, limit)
+		if err != nil {
+			t.Errorf("expected error to be nil got %#v", err)
+			return
+		}
+		if allowed != expected {
+			t.Errorf("expected take %d allowed to be %v got %v", i, expected, allowed)
+			return
+		}
+		if !allowed && (retryAfter <= 0 || retryAfter > 30*time.Second) {
+			t.Errorf("expected retry after to be within 30s got %v", retryAfter)
+			return
+		}
+	}
+
+	if err := s.Reset(ctx, key); err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if allowed, _, err := s.Take(ctx, key, limit); err != nil || !allowed {
+		t.Errorf("expected take after reset to be allowed got %v %#v", allowed, err)
+		return
+	}
+}
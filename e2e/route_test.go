@@ -3,11 +3,16 @@ package main_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/aravindanve/livemeet-server/src/provider"
 	"github.com/aravindanve/livemeet-server/src/route"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestCORS(t *testing.T) {
@@ -39,3 +44,102 @@ func TestCORS(t *testing.T) {
 		return
 	}
 }
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	r := route.RegisterRoutes(mux.NewRouter(), p)
+
+	// make a request to a route with path params
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/meetings/62b3d5ec4f0d0de5a0b2c1d0", nil)
+	r.ServeHTTP(w, req)
+
+	// test metrics are not served with the api
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusNotFound {
+		t.Errorf("expected status to be %#v got %#v", http.StatusNotFound, s)
+		return
+	}
+
+	// test metrics
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	route.RegisterMetricsRoutes(mux.NewRouter()).ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+	if b := w.Body.String(); !strings.Contains(b, `route="/meetings/{meetingId}"`) {
+		t.Errorf("expected metrics to contain route template got %q", b)
+		return
+	}
+}
+
+func TestTracing(t *testing.T) {
+	// not parallel, sets the global tracer provider
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	// record spans
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	prevTP, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	r := route.RegisterRoutes(mux.NewRouter(), p)
+
+	// make a request with a traceparent, which is ignored for clients that
+	// are not trusted proxies
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/meetings/62b3d5ec4f0d0de5a0b2c1d0", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(w, req)
+
+	// test spans
+	var routeSpan, collectionSpan sdktrace.ReadOnlySpan
+	for _, s := range sr.Ended() {
+		if s.Name() == "GET /meetings/{meetingId}" {
+			routeSpan = s
+		}
+	}
+	if routeSpan == nil {
+		t.Errorf("expected route span")
+		return
+	}
+	if id := routeSpan.SpanContext().TraceID().String(); id == traceID || routeSpan.Parent().IsValid() {
+		t.Errorf("expected route span to start a new trace got %q", id)
+		return
+	}
+	for _, s := range sr.Ended() {
+		if s.Name() == "MeetingCollection.FindOneByID" && s.SpanContext().TraceID() == routeSpan.SpanContext().TraceID() {
+			collectionSpan = s
+		}
+	}
+	if collectionSpan == nil {
+		t.Errorf("expected collection span in trace %s", traceID)
+		return
+	}
+	if collectionSpan.Parent().SpanID() != routeSpan.SpanContext().SpanID() {
+		t.Errorf("expected collection span to be a child of route span")
+		return
+	}
+}
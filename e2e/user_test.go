@@ -2,11 +2,17 @@ package main_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"sync"
+	"testing"
 
+	"github.com/aravindanve/livemeet-server/src/middleware"
 	"github.com/aravindanve/livemeet-server/src/provider"
 	"github.com/aravindanve/livemeet-server/src/resource"
+	"github.com/gorilla/mux"
 )
 
 var mockUser *resource.User
@@ -24,6 +30,8 @@ func getMockUser() resource.User {
 		mockUser = &resource.User{
 			Name:               "Mock User",
 			ImageURL:           &mockUserImageURL,
+			Email:              "user@example.com",
+			EmailDomain:        "example.com",
 			Provider:           resource.UserProvider_Google,
 			ProviderResourceID: "some-google-id",
 		}
@@ -36,3 +44,145 @@ func getMockUser() resource.User {
 	mockUserMut.Unlock()
 	return *mockUser
 }
+
+func TestUserSearch(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	user := getMockUser()
+	meeting := newMockMeeting(ctx)
+
+	r := resource.RegisterUserRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users?email=User@Example.com&meetingId="+string(meeting.ID), nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m struct {
+		Users []map[string]any `json:"users"`
+	}
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	found := false
+	for _, u := range m.Users {
+		if u["id"] == string(user.ID) {
+			found = true
+		}
+		if _, ok := u["email"]; ok {
+			t.Errorf("expected user to not have email got %#v", u)
+			return
+		}
+		if _, ok := u["providerResourceId"]; ok {
+			t.Errorf("expected user to not have providerResourceId got %#v", u)
+			return
+		}
+	}
+	if !found {
+		t.Errorf("expected users to contain %q got %#v", user.ID, m.Users)
+		return
+	}
+}
+
+func TestUserSearchNoMeeting(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	r := resource.RegisterUserRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users?email=user@example.com", nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusBadRequest {
+		t.Errorf("expected status to be %#v got %#v", http.StatusBadRequest, s)
+		return
+	}
+}
+
+func TestUserSearchNotMeetingAdmin(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	meeting := newMockMeeting(ctx)
+	meeting.UserID = resource.NewResourceID()
+	err := p.MeetingCollection().Save(ctx, &meeting)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	r := resource.RegisterUserRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users?email=user@example.com&meetingId="+string(meeting.ID), nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusUnauthorized {
+		t.Errorf("expected status to be %#v got %#v", http.StatusUnauthorized, s)
+		return
+	}
+}
+
+func TestUserSearchNoAuth(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	r := resource.RegisterUserRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users?email=user@example.com", nil)
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusUnauthorized {
+		t.Errorf("expected status to be %#v got %#v", http.StatusUnauthorized, s)
+		return
+	}
+}
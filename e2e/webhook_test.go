@@ -0,0 +1,382 @@
+package main_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/client"
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/provider"
+	"github.com/aravindanve/livemeet-server/src/resource"
+	"github.com/gorilla/mux"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+)
+
+type mockWebhookProvider struct {
+	provider.Provider
+	webhookClient client.WebhookClient
+}
+
+func newMockWebhookProvider(ctx context.Context, srv *httptest.Server) *mockWebhookProvider {
+	p := provider.NewProvider(ctx)
+	return &mockWebhookProvider{Provider: p, webhookClient: client.NewWebhookClient(srv.Client())}
+}
+
+func (m *mockWebhookProvider) WebhookClient() client.WebhookClient {
+	return m.webhookClient
+}
+
+type mockWebhookReceiver struct {
+	*httptest.Server
+	events chan string
+}
+
+func newMockWebhookReceiver() *mockWebhookReceiver {
+	events := make(chan string, 10)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		events <- r.Header.Get(client.WebhookEventHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	return &mockWebhookReceiver{Server: srv, events: events}
+}
+
+func newMockWebhook(ctx context.Context, p resource.WebhookDeps, url string, events ...resource.WebhookEvent) resource.Webhook {
+	user := getMockUser()
+	webhook := &resource.Webhook{
+		UserID: user.ID,
+		URL:    url,
+		Events: events,
+		Secret: "some-secret",
+	}
+
+	err := p.WebhookCollection().Save(ctx, webhook)
+	if err != nil {
+		panic("error saving webhook: " + err.Error())
+	}
+	return *webhook
+}
+
+func TestWebhookCreate(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	srv := newMockWebhookReceiver()
+	defer srv.Close()
+	p := newMockWebhookProvider(ctx, srv.Server)
+	defer p.Release(ctx)
+
+	r := resource.RegisterWebhookRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(
+		`{"url":"`+srv.URL+`","events":["participant.waiting","participant.admitted"]}`,
+	))
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m resource.WebhookWithSecret
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if m.ID == "" {
+		t.Errorf("expected id in response got %#v", m.ID)
+		return
+	}
+	if m.Secret == "" {
+		t.Errorf("expected secret in response got %#v", m.Secret)
+		return
+	}
+	if len(m.Events) != 2 {
+		t.Errorf("expected events to have 2 items got %#v", len(m.Events))
+		return
+	}
+}
+
+func TestWebhookCreateBadURL(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	srv := newMockWebhookReceiver()
+	defer srv.Close()
+	p := newMockWebhookProvider(ctx, srv.Server)
+	defer p.Release(ctx)
+
+	r := resource.RegisterWebhookRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(
+		`{"url":"http://example.com","events":["meeting.created"]}`,
+	))
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusBadRequest {
+		t.Errorf("expected status to be %#v got %#v", http.StatusBadRequest, s)
+		return
+	}
+}
+
+func TestWebhookCreatePrivateURL(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	p := provider.NewProvider(ctx)
+	defer p.Release(ctx)
+
+	r := resource.RegisterWebhookRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	for _, u := range []string{"https://127.0.0.1/hook", "https://169.254.169.254/latest/meta-data", "https://localhost"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(
+			`{"url":"`+u+`","events":["meeting.created"]}`,
+		))
+		req.Header.Set("authorization", getMockAuthHeader())
+
+		// test route
+		r.ServeHTTP(w, req)
+
+		// test status code
+		s := w.Result().StatusCode
+		if s != http.StatusBadRequest {
+			t.Errorf("expected status for %s to be %#v got %#v", u, http.StatusBadRequest, s)
+			return
+		}
+	}
+}
+
+func TestWebhookTest(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	srv := newMockWebhookReceiver()
+	defer srv.Close()
+	p := newMockWebhookProvider(ctx, srv.Server)
+	defer p.Release(ctx)
+
+	webhook := newMockWebhook(ctx, p, srv.URL, resource.WebhookEvent_MeetingCreated)
+
+	r := resource.RegisterWebhookRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/"+string(webhook.ID)+"/test", nil)
+	req.Header.Set("authorization", getMockAuthHeader())
+
+	// test route
+	r.ServeHTTP(w, req)
+
+	// test status code
+	s := w.Result().StatusCode
+	if s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test response
+	var m resource.WebhookDelivery
+	err := json.NewDecoder(w.Result().Body).Decode(&m)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if m.Status != resource.WebhookDeliveryStatus_Succeeded {
+		t.Errorf("expected status to be %q got %q", resource.WebhookDeliveryStatus_Succeeded, m.Status)
+		return
+	}
+	if len(m.Attempts) != 1 {
+		t.Errorf("expected attempts to have 1 item got %#v", len(m.Attempts))
+		return
+	}
+
+	// test receiver
+	if e := <-srv.events; e != string(resource.WebhookEvent_Ping) {
+		t.Errorf("expected event to be %q got %q", resource.WebhookEvent_Ping, e)
+		return
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	srv := newMockWebhookReceiver()
+	defer srv.Close()
+	p := newMockWebhookProvider(ctx, srv.Server)
+	defer p.Release(ctx)
+
+	newMockWebhook(ctx, p, srv.URL, resource.WebhookEvent_ParticipantWaiting)
+	meeting := newMockMeeting(ctx)
+
+	// run dispatcher
+	dctx, dcancel := context.WithCancel(ctx)
+	defer dcancel()
+	go resource.NewWebhookDispatcher(p).Run(dctx)
+	time.Sleep(100 * time.Millisecond)
+
+	// save participant
+	participant := &resource.Participant{
+		MeetingID: meeting.ID,
+		Name:      "My Name",
+		Status:    resource.ParticipantStatus_Waiting,
+		ExpiresAt: time.Now().Add(1 * time.Hour),
+	}
+	err := p.ParticipantCollection().Save(ctx, participant)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	// test receiver
+	select {
+	case e := <-srv.events:
+		if e != string(resource.WebhookEvent_ParticipantWaiting) {
+			t.Errorf("expected event to be %q got %q", resource.WebhookEvent_ParticipantWaiting, e)
+			return
+		}
+	case <-ctx.Done():
+		t.Errorf("expected webhook delivery got timeout")
+		return
+	}
+}
+
+func TestWebhookDispatcherDirectlyAdmitted(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	srv := newMockWebhookReceiver()
+	defer srv.Close()
+	p := newMockWebhookProvider(ctx, srv.Server)
+	defer p.Release(ctx)
+
+	newMockWebhook(ctx, p, srv.URL, resource.WebhookEvent_ParticipantAdmitted)
+	meeting := newMockMeeting(ctx)
+
+	// run dispatcher
+	dctx, dcancel := context.WithCancel(ctx)
+	defer dcancel()
+	go resource.NewWebhookDispatcher(p).Run(dctx)
+	time.Sleep(100 * time.Millisecond)
+
+	// meeting admin joins without waiting
+	r := resource.RegisterParticipantRoutes(mux.NewRouter(), p)
+	r.Use(middleware.AuthMiddleware(p))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/meetings/"+string(meeting.ID)+"/participants", strings.NewReader(`{}`))
+	req.Header.Set("authorization", getMockAuthHeader())
+	r.ServeHTTP(w, req)
+
+	if s := w.Result().StatusCode; s != http.StatusOK {
+		t.Errorf("expected status to be %#v got %#v", http.StatusOK, s)
+		return
+	}
+
+	// test receiver
+	select {
+	case e := <-srv.events:
+		if e != string(resource.WebhookEvent_ParticipantAdmitted) {
+			t.Errorf("expected event to be %q got %q", resource.WebhookEvent_ParticipantAdmitted, e)
+			return
+		}
+	case <-ctx.Done():
+		t.Errorf("expected webhook delivery got timeout")
+		return
+	}
+}
+
+func TestWebhookDispatcherRecordingFinished(t *testing.T) {
+	t.Parallel()
+	defer panicGuard(t)
+	ctx, cancel := newTestContext()
+	defer cancel()
+	srv := newMockWebhookReceiver()
+	defer srv.Close()
+	p := newMockWebhookProvider(ctx, srv.Server)
+	defer p.Release(ctx)
+
+	newMockWebhook(ctx, p, srv.URL, resource.WebhookEvent_RecordingFinished)
+	meeting := newMockMeeting(ctx)
+
+	// run dispatcher
+	dctx, dcancel := context.WithCancel(ctx)
+	defer dcancel()
+	go resource.NewWebhookDispatcher(p).Run(dctx)
+	time.Sleep(100 * time.Millisecond)
+
+	// livekit sends egress ended twice
+	r := resource.RegisterAttendanceRoutes(mux.NewRouter(), p)
+	e := &livekit.WebhookEvent{
+		Event: webhook.EventEgressEnded,
+		EgressInfo: &livekit.EgressInfo{
+			EgressId: "EG_" + string(resource.NewResourceID()),
+			Status:   livekit.EgressStatus_EGRESS_COMPLETE,
+			Request: &livekit.EgressInfo_RoomComposite{
+				RoomComposite: &livekit.RoomCompositeEgressRequest{RoomName: meeting.Code},
+			},
+			Result: &livekit.EgressInfo_File{
+				File: &livekit.FileInfo{Filename: "recording.mp4"},
+			},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, newMockLiveKitWebhookRequest(p, e))
+
+		if s := w.Result().StatusCode; s != http.StatusNoContent {
+			t.Errorf("expected status to be %#v got %#v", http.StatusNoContent, s)
+			return
+		}
+	}
+
+	// test receiver
+	select {
+	case e := <-srv.events:
+		if e != string(resource.WebhookEvent_RecordingFinished) {
+			t.Errorf("expected event to be %q got %q", resource.WebhookEvent_RecordingFinished, e)
+			return
+		}
+	case <-ctx.Done():
+		t.Errorf("expected webhook delivery got timeout")
+		return
+	}
+
+	// test retried event is delivered once
+	select {
+	case e := <-srv.events:
+		t.Errorf("expected one delivery got %q", e)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type CertCacheDeps interface {
+	config.TLSConfigProvider
+}
+
+type CertCacheProvider interface {
+	CertCache() autocert.Cache
+}
+
+// NewCertCache returns the cache of certificates issued by acme
+func NewCertCache(ds CertCacheDeps, db *mongo.Database) autocert.Cache {
+	cf := ds.TLSConfig()
+	switch cf.CertCache {
+	case config.TLSCertCache_Mongo:
+		return NewMongoCertCache(db)
+	default:
+		return autocert.DirCache(cf.CertCacheDir)
+	}
+}
+
+type mongoCertCacheEntry struct {
+	Key       string    `bson:"_id"`
+	Data      []byte    `bson:"data"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+}
+
+type mongoCertCache struct {
+	collection *mongo.Collection
+}
+
+// NewMongoCertCache returns a cache that keeps certificates and account keys
+// in mongo so that instances share them, entries include private keys and
+// the database must be protected accordingly
+func NewMongoCertCache(db *mongo.Database) autocert.Cache {
+	collection := db.Collection("tlsCertCache")
+
+	return &mongoCertCache{collection: collection}
+}
+
+func (c *mongoCertCache) Get(ctx context.Context, key string) ([]byte, error) {
+	var entry mongoCertCacheEntry
+	err := c.collection.FindOne(ctx, bson.D{{Key: "_id", Value: key}}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, autocert.ErrCacheMiss
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry.Data, nil
+}
+
+func (c *mongoCertCache) Put(ctx context.Context, key string, data []byte) error {
+	_, err := c.collection.ReplaceOne(ctx, bson.D{{Key: "_id", Value: key}}, mongoCertCacheEntry{
+		Key:       key,
+		Data:      data,
+		UpdatedAt: time.Now(),
+	}, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (c *mongoCertCache) Delete(ctx context.Context, key string) error {
+	_, err := c.collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: key}})
+	return err
+}
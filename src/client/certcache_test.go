@@ -0,0 +1,28 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type mockCertCacheDeps struct {
+	tlsConfig config.TLSConfig
+}
+
+func (p *mockCertCacheDeps) TLSConfig() config.TLSConfig {
+	return p.tlsConfig
+}
+
+func TestNewCertCache(t *testing.T) {
+	t.Parallel()
+	p := &mockCertCacheDeps{config.TLSConfig{
+		Mode:         config.TLSMode_ACME,
+		CertCache:    config.TLSCertCache_Dir,
+		CertCacheDir: t.TempDir(),
+	}}
+	if _, ok := NewCertCache(p, nil).(autocert.DirCache); !ok {
+		t.Errorf("expected dir cache")
+	}
+}
@@ -12,8 +12,11 @@ import (
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/lestrrat-go/jwx/v2/jws"
 	"github.com/lestrrat-go/jwx/v2/jwt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/tracing"
 )
 
 const (
@@ -29,6 +32,7 @@ type GoogleOAuth2Token struct {
 type GoogleOAuth2Claims struct {
 	Email         string  `json:"email"`
 	EmailVerified bool    `json:"email_verified"`
+	HostedDomain  *string `json:"hd"`
 	GivenName     string  `json:"given_name"`
 	FamilyName    string  `json:"family_name"`
 	Picture       *string `json:"picture"`
@@ -44,6 +48,8 @@ type GoogleOAuth2ClientProvider interface {
 
 type GoogleOAuth2Client interface {
 	VerifyIDToken(ctx context.Context, signed string) (*GoogleOAuth2Token, error)
+	// EnsureKeySet fetches the key set unless cached
+	EnsureKeySet(ctx context.Context) error
 	setKeySet(key string, set jwk.Set, ttl time.Duration) GoogleOAuth2Client
 	setFetchClient(client *http.Client) GoogleOAuth2Client
 	setFetchURL(url *string) GoogleOAuth2Client
@@ -66,9 +72,13 @@ func NewGoogleOAuth2Client(ds GoogleOAuth2ClientDeps) GoogleOAuth2Client {
 	}
 }
 
-func (s *googleOAuth2Client) VerifyIDToken(ctx context.Context, signed string) (*GoogleOAuth2Token, error) {
-	var keyset jwk.Set
+func (s *googleOAuth2Client) EnsureKeySet(ctx context.Context) error {
+	_, err := s.keySet(ctx)
+	return err
+}
 
+// returns jwks from cache or fetch
+func (s *googleOAuth2Client) keySet(ctx context.Context) (jwk.Set, error) {
 	// set jwks fetch fetchOptions
 	var fetchOptions []jwk.FetchOption
 	if s.fetchClient != nil {
@@ -84,11 +94,24 @@ func (s *googleOAuth2Client) VerifyIDToken(ctx context.Context, signed string) (
 	// get jwks from cache or fetch
 	item := s.cache.Get("google")
 	if item != nil {
-		keyset = item.Value()
-	} else if set, err := jwk.Fetch(ctx, fetchURL, fetchOptions...); err == nil {
-		keyset = set
-		s.cache.Set("google", set, googleOAuth2JWKSTTL)
-	} else {
+		return item.Value(), nil
+	}
+	ctx, span := tracing.Start(ctx, "GoogleOAuth2Client.FetchKeySet",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("http.url", fetchURL)))
+	set, err := jwk.Fetch(ctx, fetchURL, fetchOptions...)
+	tracing.End(span, err)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set("google", set, googleOAuth2JWKSTTL)
+	return set, nil
+}
+
+func (s *googleOAuth2Client) VerifyIDToken(ctx context.Context, signed string) (*GoogleOAuth2Token, error) {
+	// get jwks
+	keyset, err := s.keySet(ctx)
+	if err != nil {
 		return nil, err
 	}
 
@@ -18,14 +18,14 @@ import (
 
 func TestNewGoogleOAuth2Client(t *testing.T) {
 	t.Parallel()
-	p := config.NewGoogleOAuth2ConfigProvider()
+	p := config.NewConfig()
 	var _ = NewGoogleOAuth2Client(p)
 }
 
 // see https://github.com/lestrrat-go/jwx/blob/develop/v2/docs/01-jwt.md#parse-and-verify-a-jwt-with-a-key-set-matching-kid
 func TestGoogleOAuth2ClientVerifyIDTokenNoClaims(t *testing.T) {
 	t.Parallel()
-	pr := config.NewGoogleOAuth2ConfigProvider()
+	pr := config.NewConfig()
 	cl := NewGoogleOAuth2Client(pr)
 
 	// generate rsa key
@@ -87,7 +87,7 @@ func TestGoogleOAuth2ClientVerifyIDTokenNoClaims(t *testing.T) {
 // see https://github.com/lestrrat-go/jwx/blob/develop/v2/docs/01-jwt.md#parse-and-verify-a-jwt-with-a-key-set-matching-kid
 func TestGoogleOAuth2ClientVerifyIDTokenWithClaims(t *testing.T) {
 	t.Parallel()
-	pr := config.NewGoogleOAuth2ConfigProvider()
+	pr := config.NewConfig()
 	cl := NewGoogleOAuth2Client(pr)
 
 	// generate rsa key
@@ -170,7 +170,7 @@ func TestGoogleOAuth2ClientVerifyIDTokenWithClaims(t *testing.T) {
 // see https://github.com/lestrrat-go/jwx/blob/develop/v2/docs/01-jwt.md#parse-and-verify-a-jwt-with-a-key-set-matching-kid
 func TestGoogleOAuth2ClientVerifyIDTokenRemoteJWKS(t *testing.T) {
 	t.Parallel()
-	pr := config.NewGoogleOAuth2ConfigProvider()
+	pr := config.NewConfig()
 	cl := NewGoogleOAuth2Client(pr)
 
 	// generate rsa key
@@ -255,3 +255,30 @@ func TestGoogleOAuth2ClientVerifyIDTokenRemoteJWKS(t *testing.T) {
 		return
 	}
 }
+
+func TestGoogleOAuth2ClientEnsureKeySet(t *testing.T) {
+	t.Parallel()
+	pr := config.NewConfig()
+	cl := NewGoogleOAuth2Client(pr)
+
+	// serve empty jwks
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("content-type", "application/json")
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer srv.Close()
+	cl.setFetchClient(srv.Client()).setFetchURL(&srv.URL)
+
+	for i := 0; i < 2; i++ {
+		if err := cl.EnsureKeySet(context.Background()); err != nil {
+			t.Errorf("expected error to be nil got %#v", err)
+			return
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected key set to be fetched once got %d", requests)
+		return
+	}
+}
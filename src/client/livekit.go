@@ -2,10 +2,17 @@ package client
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/metrics"
+	"github.com/aravindanve/livemeet-server/src/tracing"
 	"github.com/livekit/protocol/livekit"
 	lksdk "github.com/livekit/server-sdk-go"
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type LiveKitClientDeps interface {
@@ -18,23 +25,68 @@ type LiveKitClientProvider interface {
 
 type LiveKitClient interface {
 	SendData(ctx context.Context, req *livekit.SendDataRequest) (*livekit.SendDataResponse, error)
+	ListParticipants(ctx context.Context, req *livekit.ListParticipantsRequest) (*livekit.ListParticipantsResponse, error)
+	// Ping checks that livekit is reachable and accepts the api key
+	Ping(ctx context.Context) error
 }
 
 type liveKitClient struct {
+	LiveKitClientDeps
+	mut        sync.Mutex
 	config     config.LiveKitConfig
 	roomClient *lksdk.RoomServiceClient
 }
 
 func NewLiveKitClient(ds LiveKitClientDeps) LiveKitClient {
-	cf := ds.LiveKitConfig()
-	roomClient := lksdk.NewRoomServiceClient(cf.APIURL, cf.APIKey, cf.APISecret)
+	return &liveKitClient{LiveKitClientDeps: ds}
+}
+
+// returns a room client for the current config, recreated when the api key or
+// secret are rotated
+func (l *liveKitClient) client() *lksdk.RoomServiceClient {
+	cf := l.LiveKitConfig()
 
-	return &liveKitClient{
-		config:     cf,
-		roomClient: roomClient,
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	if l.roomClient == nil || cf.APIURL != l.config.APIURL || cf.APIKey != l.config.APIKey || cf.APISecret != l.config.APISecret {
+		l.config = cf
+		l.roomClient = lksdk.NewRoomServiceClient(cf.APIURL, cf.APIKey, cf.APISecret)
 	}
+	return l.roomClient
+}
+
+func (l *liveKitClient) SendData(ctx context.Context, req *livekit.SendDataRequest) (res *livekit.SendDataResponse, err error) {
+	ctx, span := tracing.Start(ctx, "LiveKitClient.SendData", trace.WithSpanKind(trace.SpanKindClient))
+	defer func(start time.Time) {
+		metrics.ObserveLiveKitRequest("SendData", start, err)
+		tracing.End(span, err)
+	}(time.Now())
+	return l.client().SendData(ctx, req)
+}
+
+func (l *liveKitClient) ListParticipants(ctx context.Context, req *livekit.ListParticipantsRequest) (res *livekit.ListParticipantsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "LiveKitClient.ListParticipants", trace.WithSpanKind(trace.SpanKindClient))
+	defer func(start time.Time) {
+		metrics.ObserveLiveKitRequest("ListParticipants", start, err)
+		tracing.End(span, err)
+	}(time.Now())
+	return l.client().ListParticipants(ctx, req)
+}
+
+func (l *liveKitClient) Ping(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "LiveKitClient.Ping", trace.WithSpanKind(trace.SpanKindClient))
+	defer func(start time.Time) {
+		metrics.ObserveLiveKitRequest("ListRooms", start, err)
+		tracing.End(span, err)
+	}(time.Now())
+	// list a room that does not exist to keep the response small
+	_, err = l.client().ListRooms(ctx, &livekit.ListRoomsRequest{Names: []string{"_ping"}})
+	return err
 }
 
-func (l *liveKitClient) SendData(ctx context.Context, req *livekit.SendDataRequest) (*livekit.SendDataResponse, error) {
-	return l.roomClient.SendData(ctx, req)
+// IsLiveKitNotFound reports whether err is a not found response of livekit,
+// such as for a room that is empty or closed
+func IsLiveKitNotFound(err error) bool {
+	var terr twirp.Error
+	return errors.As(err, &terr) && terr.Code() == twirp.NotFound
 }
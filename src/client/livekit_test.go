@@ -1,13 +1,57 @@
 package client
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/twitchtv/twirp"
 )
 
 func TestNewLiveKitClient(t *testing.T) {
 	t.Parallel()
-	p := config.NewLiveKitConfigProvider()
+	p := config.NewConfig()
 	var _ = NewLiveKitClient(p)
 }
+
+type mockLiveKitConfigProvider struct {
+	livekitConfig config.LiveKitConfig
+}
+
+func (m *mockLiveKitConfigProvider) LiveKitConfig() config.LiveKitConfig {
+	return m.livekitConfig
+}
+
+func TestLiveKitClientRotation(t *testing.T) {
+	t.Parallel()
+	p := &mockLiveKitConfigProvider{config.LiveKitConfig{
+		APIURL:    "https://localhost:7880",
+		APIKey:    "key",
+		APISecret: "secret",
+	}}
+	l := NewLiveKitClient(p).(*liveKitClient)
+
+	a := l.client()
+	if b := l.client(); a != b {
+		t.Errorf("expected room client to be reused")
+	}
+
+	p.livekitConfig.APISecret = "rotated"
+	if b := l.client(); a == b {
+		t.Errorf("expected room client to be recreated after rotation")
+	}
+}
+
+func TestIsLiveKitNotFound(t *testing.T) {
+	t.Parallel()
+	if !IsLiveKitNotFound(fmt.Errorf("wrapped: %w", twirp.NotFoundError("requested room does not exist"))) {
+		t.Errorf("expected not found error to be not found")
+	}
+	if IsLiveKitNotFound(twirp.InternalError("some error")) {
+		t.Errorf("expected internal error to not be not found")
+	}
+	if IsLiveKitNotFound(errors.New("some error")) {
+		t.Errorf("expected other error to not be not found")
+	}
+}
@@ -0,0 +1,160 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+)
+
+type Mail struct {
+	To      []string
+	Subject string
+	Text    string
+}
+
+type MailerDeps interface {
+	config.MailerConfigProvider
+}
+
+type MailerProvider interface {
+	Mailer() Mailer
+}
+
+type Mailer interface {
+	Send(ctx context.Context, m *Mail) error
+}
+
+func NewMailer(ds MailerDeps) Mailer {
+	cf := ds.MailerConfig()
+	switch cf.Transport {
+	case config.MailerTransport_SMTP:
+		return NewSMTPMailer(cf)
+	default:
+		return NewLogMailer(cf)
+	}
+}
+
+type smtpMailer struct {
+	config config.MailerConfig
+}
+
+func NewSMTPMailer(cf config.MailerConfig) Mailer {
+	return &smtpMailer{config: cf}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, mail *Mail) error {
+	msg, err := encodeMail(m.config.From, mail)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.config.SMTPUsername, m.config.SMTPPassword, m.config.SMTPHost)
+	}
+
+	from, err := parseMailAddress(m.config.From)
+	if err != nil {
+		return err
+	}
+
+	// smtp.SendMail does not take a context so run it in the background
+	addr := net.JoinHostPort(m.config.SMTPHost, strconv.Itoa(m.config.SMTPPort))
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, from, mail.To, msg)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		return err
+	}
+}
+
+type logMailer struct {
+	config config.MailerConfig
+}
+
+// NewLogMailer returns a mailer that writes messages to files in the
+// configured log dir, or the recipients and subject to the log if no dir is
+// configured. Meant for local development and tests.
+func NewLogMailer(cf config.MailerConfig) Mailer {
+	return &logMailer{config: cf}
+}
+
+func (m *logMailer) Send(ctx context.Context, mail *Mail) error {
+	msg, err := encodeMail(m.config.From, mail)
+	if err != nil {
+		return err
+	}
+
+	// the body is not logged since it may contain tokens such as invitation
+	// links
+	if m.config.LogDir == "" {
+		slog.Info("mail", "to", mail.To, "subject", mail.Subject)
+		return nil
+	}
+
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), hex.EncodeToString(buf))
+	return os.WriteFile(filepath.Join(m.config.LogDir, name), msg, 0644)
+}
+
+func parseMailAddress(s string) (string, error) {
+	a, err := mail.ParseAddress(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid mail address %q: %w", s, err)
+	}
+	return a.Address, nil
+}
+
+// encodes a plain text utf-8 message with headers
+func encodeMail(from string, m *Mail) ([]byte, error) {
+	if len(m.To) == 0 {
+		return nil, fmt.Errorf("missing mail recipients")
+	}
+	for _, to := range m.To {
+		if _, err := parseMailAddress(to); err != nil {
+			return nil, err
+		}
+		if strings.ContainsAny(to, "\r\n") {
+			return nil, fmt.Errorf("invalid mail address %q", to)
+		}
+	}
+	if strings.ContainsAny(from, "\r\n") {
+		return nil, fmt.Errorf("invalid mail address %q", from)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(m.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", m.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Text, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+)
+
+func TestNewMailer(t *testing.T) {
+	t.Parallel()
+	p := config.NewConfig()
+	var _ = NewMailer(p)
+}
+
+// serves a single smtp session and returns the received data
+func newMockSMTPServer(t *testing.T) (string, <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %#v", err)
+	}
+
+	received := make(chan string, 1)
+	go func() {
+		defer l.Close()
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		write := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		write("220 localhost")
+
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				write("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				write("354 go ahead")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				write("250 ok")
+			case strings.HasPrefix(cmd, "QUIT"):
+				write("221 bye")
+				return
+			default:
+				write("250 ok")
+			}
+		}
+	}()
+
+	return l.Addr().String(), received
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	t.Parallel()
+	addr, received := newMockSMTPServer(t)
+	host, port, _ := net.SplitHostPort(addr)
+	portNum, _ := strconv.Atoi(port)
+
+	m := NewSMTPMailer(config.MailerConfig{
+		Transport: config.MailerTransport_SMTP,
+		From:      "Livemeet <livemeet@example.com>",
+		SMTPHost:  host,
+		SMTPPort:  portNum,
+	})
+	err := m.Send(context.Background(), &Mail{
+		To:      []string{"user@example.com"},
+		Subject: "Hello",
+		Text:    "Hello there",
+	})
+	if err != nil {
+		t.Fatalf("Error sending mail: %#v", err)
+	}
+
+	data := <-received
+	if !strings.Contains(data, "Subject: Hello\r\n") {
+		t.Fatalf("Unexpected mail data: %#v", data)
+	}
+	if !strings.Contains(data, "Hello there") {
+		t.Fatalf("Unexpected mail data: %#v", data)
+	}
+}
+
+func TestLogMailerSend(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	m := NewLogMailer(config.MailerConfig{
+		Transport: config.MailerTransport_Log,
+		From:      "livemeet@localhost",
+		LogDir:    dir,
+	})
+	err := m.Send(context.Background(), &Mail{
+		To:      []string{"user@example.com"},
+		Subject: "Hello",
+		Text:    "Hello there",
+	})
+	if err != nil {
+		t.Fatalf("Error sending mail: %#v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Error reading dir: %#v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Unexpected number of mail files: %#v", len(entries))
+	}
+}
+
+func TestEncodeMailBadRecipient(t *testing.T) {
+	t.Parallel()
+
+	_, err := encodeMail("livemeet@localhost", &Mail{
+		To:      []string{"user@example.com\r\nBcc: other@example.com"},
+		Subject: "Hello",
+	})
+	if err == nil {
+		t.Fatalf("Expected error got nil")
+	}
+}
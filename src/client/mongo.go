@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/metrics"
+	"github.com/aravindanve/livemeet-server/src/tracing"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
@@ -20,7 +22,9 @@ type MongoClientProvider interface {
 }
 
 func NewMongoClient(ctx context.Context, ds MongoClientDeps) *mongo.Client {
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(ds.MongoConfig().ConnectionURI))
+	client, err := mongo.Connect(ctx, options.Client().
+		ApplyURI(ds.MongoConfig().ConnectionURI).
+		SetMonitor(tracing.WrapMongoMonitor(metrics.NewMongoMonitor())))
 	if err != nil {
 		panic(fmt.Sprintf("creating mongo client failed with %s", err.Error()))
 	}
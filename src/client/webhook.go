@@ -0,0 +1,189 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	webhookTimeout         = 10 * time.Second
+	WebhookEventHeader     = "x-webhook-event"
+	WebhookDeliveryHeader  = "x-webhook-delivery"
+	WebhookSignatureHeader = "x-webhook-signature"
+)
+
+// ErrWebhookAddressNotAllowed is returned for webhook urls that resolve to
+// loopback, private, link-local or other non public addresses
+var ErrWebhookAddressNotAllowed = errors.New("webhook address not allowed")
+
+// ranges not covered by netip.Addr methods that must not be reachable
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// IsPublicAddr returns whether addr may be used as a webhook destination
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+type WebhookRequest struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+type WebhookClientProvider interface {
+	WebhookClient() WebhookClient
+}
+
+type WebhookClient interface {
+	// CheckURL resolves the host of url and returns ErrWebhookAddressNotAllowed
+	// if any of its addresses are not allowed
+	CheckURL(ctx context.Context, url string) error
+	// Send posts a signed request and returns the response status code
+	Send(ctx context.Context, req *WebhookRequest) (int, error)
+}
+
+type webhookClient struct {
+	httpClient *http.Client
+	allowAddr  func(netip.Addr) bool
+}
+
+// NewWebhookClient returns a client that only connects to public addresses,
+// checked again when dialing so that dns rebinding cannot bypass CheckURL.
+// A custom httpClient is trusted to connect anywhere, e.g. in tests.
+// Redirects are never followed.
+func NewWebhookClient(httpClient *http.Client) WebhookClient {
+	allowAddr := func(netip.Addr) bool { return true }
+	if httpClient == nil {
+		allowAddr = IsPublicAddr
+		httpClient = &http.Client{
+			Timeout:   webhookTimeout,
+			Transport: newWebhookTransport(allowAddr),
+		}
+	}
+
+	cl := *httpClient
+	cl.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &webhookClient{httpClient: &cl, allowAddr: allowAddr}
+}
+
+// returns a transport without proxies that refuses to connect to addresses
+// that are not allowed
+func newWebhookTransport(allowAddr func(netip.Addr) bool) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !allowAddr(addr) {
+				return ErrWebhookAddressNotAllowed
+			}
+			return nil
+		},
+	}
+	return &http.Transport{
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: webhookTimeout,
+	}
+}
+
+func (c *webhookClient) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !c.allowAddr(addr) {
+			return ErrWebhookAddressNotAllowed
+		}
+	}
+	return nil
+}
+
+func (c *webhookClient) Send(ctx context.Context, req *WebhookRequest) (status int, err error) {
+	// trace context is not sent to third party urls
+	ctx, span := tracing.Start(ctx, "WebhookClient.Send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("webhook.event", req.Event)))
+	defer func() { tracing.End(span, err) }()
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	r.Header.Set("content-type", "application/json")
+	r.Header.Set(WebhookEventHeader, req.Event)
+	r.Header.Set(WebhookDeliveryHeader, req.DeliveryID)
+	r.Header.Set(WebhookSignatureHeader, SignWebhookBody(req.Secret, timestamp, req.Body))
+
+	res, err := c.httpClient.Do(r)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// SignWebhookBody returns the signature header value in the format
+// t=<unix timestamp>,v1=<hex hmac-sha256 of "<timestamp>.<body>">
+func SignWebhookBody(secret string, timestamp int64, body []byte) string {
+	t := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(body)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNewWebhookClient(t *testing.T) {
+	t.Parallel()
+	var _ = NewWebhookClient(nil)
+}
+
+func TestWebhookClientSend(t *testing.T) {
+	t.Parallel()
+	body := []byte(`{"event":"ping"}`)
+
+	// create test webhook server
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		s := r.Header.Get(WebhookSignatureHeader)
+		parts := strings.Split(s, ",")
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], "t=") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		timestamp, _ := strconv.ParseInt(strings.TrimPrefix(parts[0], "t="), 10, 64)
+		if s != SignWebhookBody("secret", timestamp, b) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(WebhookEventHeader) != "ping" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cl := NewWebhookClient(srv.Client())
+	status, err := cl.Send(context.Background(), &WebhookRequest{
+		URL:        srv.URL,
+		Secret:     "secret",
+		Event:      "ping",
+		DeliveryID: "some-id",
+		Body:       body,
+	})
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if status != http.StatusNoContent {
+		t.Errorf("expected status to be %#v got %#v", http.StatusNoContent, status)
+		return
+	}
+}
+
+func TestWebhookClientSendErrorStatus(t *testing.T) {
+	t.Parallel()
+
+	// create test webhook server
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	cl := NewWebhookClient(srv.Client())
+	status, err := cl.Send(context.Background(), &WebhookRequest{
+		URL:    srv.URL,
+		Secret: "secret",
+		Event:  "ping",
+		Body:   []byte(`{}`),
+	})
+	if err == nil {
+		t.Errorf("expected error got nil")
+		return
+	}
+	if status != http.StatusInternalServerError {
+		t.Errorf("expected status to be %#v got %#v", http.StatusInternalServerError, status)
+		return
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"93.184.216.34", "2606:2800:220:1::"} {
+		if !IsPublicAddr(netip.MustParseAddr(s)) {
+			t.Errorf("expected %s to be public", s)
+		}
+	}
+	for _, s := range []string{
+		"127.0.0.1", "::1", "10.0.0.1", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"0.0.0.0", "::", "100.64.0.1", "fd00::1", "fe80::1", "::ffff:127.0.0.1", "64:ff9b::a00:1",
+	} {
+		if IsPublicAddr(netip.MustParseAddr(s)) {
+			t.Errorf("expected %s not to be public", s)
+		}
+	}
+}
+
+func TestWebhookClientCheckURL(t *testing.T) {
+	t.Parallel()
+	cl := NewWebhookClient(nil)
+
+	for _, u := range []string{"https://127.0.0.1/hook", "https://169.254.169.254/latest", "https://localhost:8443", "https://[::1]/hook"} {
+		if err := cl.CheckURL(context.Background(), u); !errors.Is(err, ErrWebhookAddressNotAllowed) {
+			t.Errorf("expected %s to be not allowed got %#v", u, err)
+		}
+	}
+	if err := cl.CheckURL(context.Background(), "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+	}
+}
+
+func TestWebhookClientSendPrivateAddress(t *testing.T) {
+	t.Parallel()
+	called := false
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	// the default client refuses to connect even if the url was not checked
+	_, err := NewWebhookClient(nil).Send(context.Background(), &WebhookRequest{
+		URL:    srv.URL,
+		Secret: "secret",
+		Event:  "ping",
+		Body:   []byte(`{}`),
+	})
+	if !errors.Is(err, ErrWebhookAddressNotAllowed) {
+		t.Errorf("expected error to be not allowed got %#v", err)
+	}
+	if called {
+		t.Errorf("expected server not to be called")
+	}
+}
+
+func TestWebhookClientSendRedirect(t *testing.T) {
+	t.Parallel()
+	redirected := false
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			redirected = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	status, err := NewWebhookClient(srv.Client()).Send(context.Background(), &WebhookRequest{
+		URL:    srv.URL,
+		Secret: "secret",
+		Event:  "ping",
+		Body:   []byte(`{}`),
+	})
+	if err == nil || status != http.StatusTemporaryRedirect {
+		t.Errorf("expected redirect to fail with status %#v got %#v %#v", http.StatusTemporaryRedirect, status, err)
+	}
+	if redirected {
+		t.Errorf("expected redirect not to be followed")
+	}
+}
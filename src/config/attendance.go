@@ -0,0 +1,34 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	attendanceTTL = 365 * 24 * time.Hour
+)
+
+type AttendanceConfig struct {
+	TTL time.Duration
+}
+
+type AttendanceConfigProvider interface {
+	AttendanceConfig() AttendanceConfig
+}
+
+type attendanceConfigProvider struct {
+	attendanceConfig atomic.Pointer[AttendanceConfig] // swapped on reload
+}
+
+func (p *attendanceConfigProvider) AttendanceConfig() AttendanceConfig {
+	return *p.attendanceConfig.Load()
+}
+
+func NewAttendanceConfigProvider(l *Loader) AttendanceConfigProvider {
+	p := &attendanceConfigProvider{}
+	p.attendanceConfig.Store(&AttendanceConfig{
+		TTL: l.DurationWithDefault("ATTENDANCE_TTL", attendanceTTL),
+	})
+	return p
+}
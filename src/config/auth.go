@@ -1,6 +1,8 @@
 package config
 
 import (
+	"bytes"
+	"sync/atomic"
 	"time"
 
 	"github.com/lestrrat-go/jwx/v2/jwa"
@@ -9,13 +11,20 @@ import (
 const (
 	authAccessTokenTTL    = 24 * time.Hour
 	authAccessTokenIssuer = "https://github.com/aravindanve/livemeet-server"
+	authRefreshTokenTTL   = 90 * 24 * time.Hour
 )
 
 type AuthConfig struct {
-	Algorithm jwa.SignatureAlgorithm
-	Secret    []byte
-	Issuer    string
-	TTL       time.Duration
+	Algorithm       jwa.SignatureAlgorithm
+	Secret          []byte
+	Issuer          string
+	TTL             time.Duration
+	RefreshTokenTTL time.Duration
+
+	// PreviousSecret is still accepted when verifying until
+	// PreviousSecretExpiresAt so tokens signed before a rotation stay valid
+	PreviousSecret          []byte
+	PreviousSecretExpiresAt time.Time
 }
 
 type AuthConfigProvider interface {
@@ -23,20 +32,40 @@ type AuthConfigProvider interface {
 }
 
 type authConfigProvider struct {
-	authConfig AuthConfig
+	authConfig atomic.Pointer[AuthConfig] // swapped on reload
 }
 
 func (p *authConfigProvider) AuthConfig() AuthConfig {
-	return p.authConfig
+	return *p.authConfig.Load()
+}
+
+func NewAuthConfigProvider(l *Loader) AuthConfigProvider {
+	p := &authConfigProvider{}
+	p.authConfig.Store(&AuthConfig{
+		Algorithm:       jwa.HS512,
+		Secret:          l.BytesBase64("AUTH_SECRET"),
+		Issuer:          authAccessTokenIssuer,
+		TTL:             l.DurationWithDefault("AUTH_ACCESS_TOKEN_TTL", authAccessTokenTTL),
+		RefreshTokenTTL: l.DurationWithDefault("AUTH_REFRESH_TOKEN_TTL", authRefreshTokenTTL),
+	})
+	return p
+}
+
+// Secrets returns the secrets accepted when verifying tokens, the current
+// secret first
+func (c AuthConfig) Secrets() [][]byte {
+	if c.PreviousSecret != nil && time.Now().Before(c.PreviousSecretExpiresAt) {
+		return [][]byte{c.Secret, c.PreviousSecret}
+	}
+	return [][]byte{c.Secret}
 }
 
-func NewAuthConfigProvider() AuthConfigProvider {
-	return &authConfigProvider{
-		authConfig: AuthConfig{
-			Algorithm: jwa.HS512,
-			Secret:    GetenvBytesBase64("AUTH_SECRET"),
-			Issuer:    authAccessTokenIssuer,
-			TTL:       authAccessTokenTTL,
-		},
+// keeps the secret of curr in next for grace when it was rotated, otherwise
+// carries over the previous secret of curr
+func rotateAuthSecret(curr, next *AuthConfig, grace time.Duration) {
+	if !bytes.Equal(curr.Secret, next.Secret) {
+		next.PreviousSecret, next.PreviousSecretExpiresAt = curr.Secret, time.Now().Add(grace)
+	} else {
+		next.PreviousSecret, next.PreviousSecretExpiresAt = curr.PreviousSecret, curr.PreviousSecretExpiresAt
 	}
 }
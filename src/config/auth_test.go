@@ -1,8 +1,38 @@
 package config
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestNewAuthConfigProvider(t *testing.T) {
 	t.Parallel()
-	var _ = NewAuthConfigProvider()
+	l := NewLoader(NewEnvSource())
+	var _ = NewAuthConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRotateAuthSecret(t *testing.T) {
+	t.Parallel()
+	curr := &AuthConfig{Secret: []byte("old")}
+	next := &AuthConfig{Secret: []byte("new")}
+	rotateAuthSecret(curr, next, time.Minute)
+	if s := next.Secrets(); len(s) != 2 || string(s[0]) != "new" || string(s[1]) != "old" {
+		t.Errorf("expected new and old secrets to be accepted got %q", s)
+	}
+
+	// carried over on reloads without rotation
+	same := &AuthConfig{Secret: []byte("new")}
+	rotateAuthSecret(next, same, time.Minute)
+	if s := same.Secrets(); len(s) != 2 || string(s[1]) != "old" {
+		t.Errorf("expected old secret to be carried over got %q", s)
+	}
+
+	// dropped after the grace window
+	same.PreviousSecretExpiresAt = time.Now().Add(-time.Second)
+	if s := same.Secrets(); len(s) != 1 || string(s[0]) != "new" {
+		t.Errorf("expected only the new secret to be accepted got %q", s)
+	}
 }
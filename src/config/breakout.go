@@ -0,0 +1,34 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	breakoutTTL = 24 * time.Hour
+)
+
+type BreakoutConfig struct {
+	TTL time.Duration
+}
+
+type BreakoutConfigProvider interface {
+	BreakoutConfig() BreakoutConfig
+}
+
+type breakoutConfigProvider struct {
+	breakoutConfig atomic.Pointer[BreakoutConfig] // swapped on reload
+}
+
+func (p *breakoutConfigProvider) BreakoutConfig() BreakoutConfig {
+	return *p.breakoutConfig.Load()
+}
+
+func NewBreakoutConfigProvider(l *Loader) BreakoutConfigProvider {
+	p := &breakoutConfigProvider{}
+	p.breakoutConfig.Store(&BreakoutConfig{
+		TTL: l.DurationWithDefault("BREAKOUT_TTL", breakoutTTL),
+	})
+	return p
+}
@@ -1,27 +1,228 @@
 package config
 
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	secretTimeout = 10 * time.Second
+)
+
 type Config interface {
 	HttpConfigProvider
 	MongoConfigProvider
 	GoogleOAuth2ConfigProvider
 	LiveKitConfigProvider
 	AuthConfigProvider
+	EventConfigProvider
+	MailerConfigProvider
+	InvitationConfigProvider
+	RateLimitConfigProvider
+	LogConfigProvider
+	TracingConfigProvider
+	MeetingConfigProvider
+	ParticipantConfigProvider
+	OccurrenceConfigProvider
+	AttendanceConfigProvider
+	BreakoutConfigProvider
+	MessageConfigProvider
+	WebhookConfigProvider
+	SecretConfigProvider
+	CORSConfigProvider
+	TLSConfigProvider
+	SecurityConfigProvider
+	ProxyConfigProvider
+	// Reload refreshes secret sources, reads the config file again and swaps
+	// the config that can change at runtime: cors, security headers, trusted
+	// proxies, rate limits, log level, ttls and livekit credentials. Nothing is swapped if the new config is invalid.
+	Reload(ctx context.Context) error
+	// Watch reloads on SIGHUP, when the config file changes and every secret
+	// refresh interval until ctx is done, reloaded is called after each reload
+	Watch(ctx context.Context, reloaded func(err error))
 }
 
+// config embeds the concrete providers of config that can change at runtime
+// so that their snapshots can be swapped on reload
 type config struct {
 	HttpConfigProvider
 	MongoConfigProvider
 	GoogleOAuth2ConfigProvider
-	LiveKitConfigProvider
-	AuthConfigProvider
+	*livekitConfigProvider
+	*authConfigProvider
+	EventConfigProvider
+	MailerConfigProvider
+	*invitationConfigProvider
+	*rateLimitConfigProvider
+	*logConfigProvider
+	TracingConfigProvider
+	*meetingConfigProvider
+	*participantConfigProvider
+	*occurrenceConfigProvider
+	*attendanceConfigProvider
+	*breakoutConfigProvider
+	*messageConfigProvider
+	*webhookConfigProvider
+	SecretConfigProvider
+	*corsConfigProvider
+	TLSConfigProvider
+	*securityConfigProvider
+	*proxyConfigProvider
+	flags   MapSource
+	path    string // config file
+	secrets []SecretSource
 }
 
+// NewConfig loads config from env and CONFIG_FILE, panics listing every
+// problem found
 func NewConfig() Config {
+	cf, err := Load(nil)
+	if err != nil {
+		panic(fmt.Sprintf("invalid config:\n%s", err.Error()))
+	}
+	return cf
+}
+
+// Load reads config from command line flags, env, vault if VAULT_ADDR is set
+// and a yaml or toml file set by --config-file or CONFIG_FILE in that order of
+// precedence, and returns every problem found
+func Load(args []string) (Config, error) {
+	flags, err := NewFlagSource(args)
+	if err != nil {
+		return nil, err
+	}
+	path := NewLoader(flags, NewEnvSource()).StringWithDefault("CONFIG_FILE", "")
+	file, err := readFileSource(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// secret sources are configured by flags, env and file
+	sl := NewLoader(flags, NewEnvSource(), file)
+	scf := NewSecretConfigProvider(sl).SecretConfig()
+	if err := sl.Validate(); err != nil {
+		return nil, err
+	}
+
+	var secrets []SecretSource
+	if scf.VaultAddr != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+		defer cancel()
+		vault, err := NewVaultSecretSource(ctx, scf.VaultAddr, scf.VaultToken, scf.VaultKVMount, scf.VaultKVPath)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, vault)
+	}
+
+	l := NewLoader(configSources(flags, secrets, file)...)
+	cf := newConfig(l)
+	cf.flags = flags
+	cf.path = path
+	cf.secrets = secrets
+
+	for _, name := range flags.names() {
+		if name != "CONFIG_FILE" && !l.Used(name) {
+			l.Errorf("unknown flag %s", flagName(name))
+		}
+	}
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+	return cf, nil
+}
+
+// returns an empty source if path is empty
+func readFileSource(path string) (Source, error) {
+	if path == "" {
+		return MapSource{}, nil
+	}
+	return NewFileSource(path)
+}
+
+// returns sources in order of precedence
+func configSources(flags Source, secrets []SecretSource, file Source) []Source {
+	sources := []Source{flags, NewEnvSource()}
+	for _, s := range secrets {
+		sources = append(sources, s)
+	}
+	return append(sources, file)
+}
+
+// reads all config, problems are reported by l.Validate
+func newConfig(l *Loader) *config {
 	return &config{
-		HttpConfigProvider:         NewHttpConfigProvider(),
-		MongoConfigProvider:        NewMongoConfigProvider(),
-		GoogleOAuth2ConfigProvider: NewGoogleOAuth2ConfigProvider(),
-		LiveKitConfigProvider:      NewLiveKitConfigProvider(),
-		AuthConfigProvider:         NewAuthConfigProvider(),
+		HttpConfigProvider:         NewHttpConfigProvider(l),
+		MongoConfigProvider:        NewMongoConfigProvider(l),
+		GoogleOAuth2ConfigProvider: NewGoogleOAuth2ConfigProvider(l),
+		livekitConfigProvider:      NewLiveKitConfigProvider(l).(*livekitConfigProvider),
+		authConfigProvider:         NewAuthConfigProvider(l).(*authConfigProvider),
+		EventConfigProvider:        NewEventConfigProvider(l),
+		MailerConfigProvider:       NewMailerConfigProvider(l),
+		invitationConfigProvider:   NewInvitationConfigProvider(l).(*invitationConfigProvider),
+		rateLimitConfigProvider:    NewRateLimitConfigProvider(l).(*rateLimitConfigProvider),
+		logConfigProvider:          NewLogConfigProvider(l).(*logConfigProvider),
+		TracingConfigProvider:      NewTracingConfigProvider(l),
+		meetingConfigProvider:      NewMeetingConfigProvider(l).(*meetingConfigProvider),
+		participantConfigProvider:  NewParticipantConfigProvider(l).(*participantConfigProvider),
+		occurrenceConfigProvider:   NewOccurrenceConfigProvider(l).(*occurrenceConfigProvider),
+		attendanceConfigProvider:   NewAttendanceConfigProvider(l).(*attendanceConfigProvider),
+		breakoutConfigProvider:     NewBreakoutConfigProvider(l).(*breakoutConfigProvider),
+		messageConfigProvider:      NewMessageConfigProvider(l).(*messageConfigProvider),
+		webhookConfigProvider:      NewWebhookConfigProvider(l).(*webhookConfigProvider),
+		SecretConfigProvider:       NewSecretConfigProvider(l),
+		corsConfigProvider:         NewCORSConfigProvider(l).(*corsConfigProvider),
+		TLSConfigProvider:          NewTLSConfigProvider(l),
+		securityConfigProvider:     NewSecurityConfigProvider(l).(*securityConfigProvider),
+		proxyConfigProvider:        NewProxyConfigProvider(l).(*proxyConfigProvider),
 	}
 }
+
+func (c *config) Reload(ctx context.Context) error {
+	for _, s := range c.secrets {
+		if err := s.Refresh(ctx); err != nil {
+			return err
+		}
+	}
+	file, err := readFileSource(c.path)
+	if err != nil {
+		return err
+	}
+
+	l := NewLoader(configSources(c.flags, c.secrets, file)...)
+	next := newConfig(l)
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	// tokens signed with a rotated secret stay valid until they expire
+	rotateLiveKitSecret(c.livekitConfig.Load(), next.livekitConfig.Load())
+	rotateAuthSecret(c.authConfig.Load(), next.authConfig.Load(), max(
+		c.authConfig.Load().TTL, next.authConfig.Load().TTL,
+		c.invitationConfig.Load().TTL, next.invitationConfig.Load().TTL,
+	))
+
+	swap(&c.livekitConfig, &next.livekitConfig)
+	swap(&c.authConfig, &next.authConfig)
+	swap(&c.invitationConfig, &next.invitationConfig)
+	swap(&c.rateLimitConfig, &next.rateLimitConfig)
+	swap(&c.logConfig, &next.logConfig)
+	swap(&c.meetingConfig, &next.meetingConfig)
+	swap(&c.participantConfig, &next.participantConfig)
+	swap(&c.occurrenceConfig, &next.occurrenceConfig)
+	swap(&c.attendanceConfig, &next.attendanceConfig)
+	swap(&c.breakoutConfig, &next.breakoutConfig)
+	swap(&c.messageConfig, &next.messageConfig)
+	swap(&c.webhookConfig, &next.webhookConfig)
+	swap(&c.corsConfig, &next.corsConfig)
+	swap(&c.securityConfig, &next.securityConfig)
+	swap(&c.proxyConfig, &next.proxyConfig)
+	return nil
+}
+
+// stores the snapshot of next in p
+func swap[T any](p *atomic.Pointer[T], next *atomic.Pointer[T]) {
+	p.Store(next.Load())
+}
@@ -1,8 +1,149 @@
 package config
 
-import "testing"
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestNewConfig(t *testing.T) {
 	t.Parallel()
 	var _ = NewConfig()
 }
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("meeting:\n  ttl: 720h\n  refresh_interval: 1h\nparticipant_ttl: 1h\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cf, err := Load([]string{"--config-file", path, "--participant-ttl=2h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// file
+	if ttl := cf.MeetingConfig().TTL; ttl != 720*time.Hour {
+		t.Errorf("expected meeting ttl to be 720h got %v", ttl)
+	}
+
+	// flags override file
+	if ttl := cf.ParticipantConfig().TTL; ttl != 2*time.Hour {
+		t.Errorf("expected participant ttl to be 2h got %v", ttl)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load([]string{
+		"--meeting-ttl=1h",
+		"--meeting-refresh-interval=2h",
+		"--log-level=loud",
+		"--unknown",
+	})
+	if err == nil {
+		t.Fatalf("expected invalid config to fail")
+	}
+	for _, s := range []string{"MEETING_REFRESH_INTERVAL", "LOG_LEVEL", "--unknown"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected problems to include %s got %v", s, err)
+		}
+	}
+}
+
+func TestConfigReload(t *testing.T) {
+	t.Parallel()
+	vault := newVaultDevServer(t, "token")
+	vault.put("secret", "livemeet-server", map[string]any{"PARTICIPANT_TTL": "1h"})
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("http_addr = \":8081\"\nmeeting_ttl = \"720h\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cf, err := Load([]string{
+		"--config-file", path,
+		"--vault-addr", vault.URL,
+		"--vault-token", "token",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl := cf.ParticipantConfig().TTL; ttl != time.Hour {
+		t.Fatalf("expected participant ttl to be 1h got %v", ttl)
+	}
+
+	// change file and rotate secret
+	if err := os.WriteFile(path, []byte("http_addr = \":8082\"\nmeeting_ttl = \"48h\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	vault.put("secret", "livemeet-server", map[string]any{"PARTICIPANT_TTL": "2h"})
+	if err := cf.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := cf.MeetingConfig().TTL; ttl != 48*time.Hour {
+		t.Errorf("expected meeting ttl to be reloaded to 48h got %v", ttl)
+	}
+	if ttl := cf.ParticipantConfig().TTL; ttl != 2*time.Hour {
+		t.Errorf("expected participant ttl to be reloaded to 2h got %v", ttl)
+	}
+	if addr := cf.HttpConfig().Addr; addr != ":8081" {
+		t.Errorf("expected http addr to require a restart got %v", addr)
+	}
+
+	// invalid config is not applied
+	if err := os.WriteFile(path, []byte("meeting_ttl = \"forever\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := cf.Reload(context.Background()); err == nil {
+		t.Errorf("expected invalid config to fail")
+	}
+	if ttl := cf.MeetingConfig().TTL; ttl != 48*time.Hour {
+		t.Errorf("expected meeting ttl to be kept at 48h got %v", ttl)
+	}
+}
+
+func TestConfigWatch(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("log_level: info\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cf, err := Load([]string{"--config-file", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 1)
+	go cf.Watch(ctx, func(err error) {
+		select {
+		case reloaded <- err:
+		default:
+		}
+	})
+
+	// wait for the watcher to start before writing
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("message_ttl: 1h\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected config to be reloaded after the file changed")
+	}
+	if ttl := cf.MessageConfig().TTL; ttl != time.Hour {
+		t.Errorf("expected message ttl to be reloaded to 1h got %v", ttl)
+	}
+}
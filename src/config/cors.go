@@ -0,0 +1,70 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	corsMaxAge = 10 * time.Minute
+)
+
+type CORSConfig struct {
+	// AllowedOrigins are exact origins such as https://app.example.com or
+	// wildcard subdomains such as https://*.example.com, * allows any origin
+	// without credentials
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration // for caching preflight responses
+}
+
+type CORSConfigProvider interface {
+	CORSConfig() CORSConfig
+}
+
+type corsConfigProvider struct {
+	corsConfig atomic.Pointer[CORSConfig] // swapped on reload
+}
+
+func (p *corsConfigProvider) CORSConfig() CORSConfig {
+	return *p.corsConfig.Load()
+}
+
+func NewCORSConfigProvider(l *Loader) CORSConfigProvider {
+	origins := l.StringsWithDefault("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000"})
+	for _, o := range origins {
+		if !validCORSOrigin(o) {
+			l.Errorf("unable to parse config value CORS_ALLOWED_ORIGINS (scheme://host[:port], scheme://*.host[:port] or *) from %s", o)
+		}
+	}
+
+	p := &corsConfigProvider{}
+	p.corsConfig.Store(&CORSConfig{
+		AllowedOrigins:   origins,
+		AllowedMethods:   l.StringsWithDefault("CORS_ALLOWED_METHODS", []string{"GET", "HEAD", "PUT", "POST", "DELETE", "PATCH"}),
+		AllowedHeaders:   l.StringsWithDefault("CORS_ALLOWED_HEADERS", []string{"authorization", "content-type", "x-request-id"}),
+		ExposedHeaders:   l.StringsWithDefault("CORS_EXPOSED_HEADERS", []string{"retry-after", "x-request-id"}),
+		AllowCredentials: l.BoolWithDefault("CORS_ALLOW_CREDENTIALS", true),
+		MaxAge:           l.DurationWithDefault("CORS_MAX_AGE", corsMaxAge),
+	})
+	return p
+}
+
+// origins have no path, query or trailing slash, a wildcard is only allowed
+// as the first label of the host
+func validCORSOrigin(s string) bool {
+	if s == "*" {
+		return true
+	}
+	s = strings.Replace(s, "://*.", "://", 1)
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return u.Scheme != "" && u.Host != "" && !strings.Contains(u.Host, "*") && u.Scheme+"://"+u.Host == s
+}
@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestNewCORSConfigProvider(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(NewEnvSource())
+	var _ = NewCORSConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidCORSOrigin(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"*", "https://example.com", "http://localhost:3000", "https://*.example.com"} {
+		if !validCORSOrigin(s) {
+			t.Errorf("expected %s to be valid", s)
+		}
+	}
+	for _, s := range []string{"", "example.com", "https://example.com/", "https://example.com/path", "https://a.*.example.com", "*.example.com"} {
+		if validCORSOrigin(s) {
+			t.Errorf("expected %s to be invalid", s)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package config
+
+import "os"
+
+type envSource struct{}
+
+// NewEnvSource returns a source of env variables, empty variables are
+// treated as unset
+func NewEnvSource() Source {
+	return envSource{}
+}
+
+func (envSource) Lookup(name string) (string, bool) {
+	s := os.Getenv(name)
+	return s, s != ""
+}
@@ -0,0 +1,37 @@
+package config
+
+const (
+	EventTransport_Memory EventTransport = "memory"
+	EventTransport_Mongo  EventTransport = "mongo"
+)
+
+type EventTransport string
+
+type EventConfig struct {
+	Transport EventTransport
+}
+
+type EventConfigProvider interface {
+	EventConfig() EventConfig
+}
+
+type eventConfigProvider struct {
+	eventConfig EventConfig
+}
+
+func (p *eventConfigProvider) EventConfig() EventConfig {
+	return p.eventConfig
+}
+
+func NewEventConfigProvider(l *Loader) EventConfigProvider {
+	transport := EventTransport(l.StringWithDefault("EVENT_TRANSPORT", string(EventTransport_Memory)))
+	if transport != EventTransport_Memory && transport != EventTransport_Mongo {
+		l.Errorf("unexpected config value EVENT_TRANSPORT (memory or mongo) value %s", transport)
+	}
+
+	return &eventConfigProvider{
+		eventConfig: EventConfig{
+			Transport: transport,
+		},
+	}
+}
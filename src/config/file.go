@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// NewFileSource reads a yaml or toml file, nested keys are joined with
+// underscores and upper cased so that
+//
+//	livekit:
+//	  api_url: https://localhost:7880
+//
+// is looked up as LIVEKIT_API_URL, lists become comma separated values
+func NewFileSource(path string) (Source, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	m := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &m)
+	case ".toml":
+		err = toml.Unmarshal(b, &m)
+	default:
+		return nil, fmt.Errorf("unexpected config file extension %s (yaml, yml or toml)", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	s := MapSource{}
+	flattenFileValues(s, "", m)
+	return s, nil
+}
+
+func flattenFileValues(s MapSource, prefix string, m map[string]any) {
+	for k, v := range m {
+		name := configName(k)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			flattenFileValues(s, name, v)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			s[name] = strings.Join(items, ",")
+		case nil:
+		default:
+			s[name] = fmt.Sprint(v)
+		}
+	}
+}
+
+// configName converts file keys and flag names such as api-url to API_URL
+func configName(key string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
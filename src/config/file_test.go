@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewFileSource(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": "livekit:\n  api_url: https://localhost:7880\nmeeting-ttl: 720h\nrate_limit:\n  enabled: false\n  overrides: [all=10/1s, authCreate=1/1m]\n",
+		"config.toml": "meeting_ttl = \"720h\"\n\n[livekit]\napi_url = \"https://localhost:7880\"\n\n[rate_limit]\nenabled = false\noverrides = [\"all=10/1s\", \"authCreate=1/1m\"]\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := NewFileSource(path)
+		if err != nil {
+			t.Fatalf("expected %s to be read got %v", name, err)
+		}
+
+		for k, v := range map[string]string{
+			"LIVEKIT_API_URL":      "https://localhost:7880",
+			"MEETING_TTL":          "720h",
+			"RATE_LIMIT_ENABLED":   "false",
+			"RATE_LIMIT_OVERRIDES": "all=10/1s,authCreate=1/1m",
+		} {
+			if a, _ := s.Lookup(k); a != v {
+				t.Errorf("expected %s %s to be %v got %v", name, k, v, a)
+			}
+		}
+	}
+}
+
+func TestNewFileSourceInvalid(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.json": "{}",
+		"config.yaml": "livekit: [",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewFileSource(path); err == nil {
+			t.Errorf("expected %s to fail", name)
+		}
+	}
+
+	if _, err := NewFileSource(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Errorf("expected missing file to fail")
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewFlagSource parses command line flags of the form --livekit-api-url=value
+// or --livekit-api-url value, looked up as LIVEKIT_API_URL, a flag without a
+// value is true
+func NewFlagSource(args []string) (MapSource, error) {
+	s := MapSource{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+			return nil, fmt.Errorf("unexpected argument %s", arg)
+		}
+
+		key, value, ok := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !ok {
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				i++
+				value = args[i]
+			} else {
+				value = "true"
+			}
+		}
+		if key == "" {
+			return nil, fmt.Errorf("unexpected argument %s", arg)
+		}
+
+		s[configName(key)] = value
+	}
+	return s, nil
+}
+
+// flagName converts a config name such as LIVEKIT_API_URL to a flag
+func flagName(name string) string {
+	return "--" + strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
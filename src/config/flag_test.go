@@ -0,0 +1,37 @@
+package config
+
+import "testing"
+
+func TestNewFlagSource(t *testing.T) {
+	t.Parallel()
+	s, err := NewFlagSource([]string{
+		"--livekit-api-url=https://localhost:7880",
+		"-meeting-ttl", "720h",
+		"--tracing-enabled",
+		"--log-level", "debug",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range map[string]string{
+		"LIVEKIT_API_URL": "https://localhost:7880",
+		"MEETING_TTL":     "720h",
+		"TRACING_ENABLED": "true",
+		"LOG_LEVEL":       "debug",
+	} {
+		if a, _ := s.Lookup(k); a != v {
+			t.Errorf("expected %s to be %v got %v", k, v, a)
+		}
+	}
+}
+
+func TestNewFlagSourceInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{{"value"}, {"--"}, {"--=value"}} {
+		if _, err := NewFlagSource(args); err == nil {
+			t.Errorf("expected %v to fail", args)
+		}
+	}
+}
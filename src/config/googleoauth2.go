@@ -16,10 +16,10 @@ func (p *googleOAuth2ConfigProvider) GoogleOAuth2Config() GoogleOAuth2Config {
 	return p.googleOAuth2Config
 }
 
-func NewGoogleOAuth2ConfigProvider() GoogleOAuth2ConfigProvider {
+func NewGoogleOAuth2ConfigProvider(l *Loader) GoogleOAuth2ConfigProvider {
 	return &googleOAuth2ConfigProvider{
 		googleOAuth2Config: GoogleOAuth2Config{
-			ClientID: GetenvString("GOOGLE_OAUTH2_CLIENT_ID"),
+			ClientID: l.String("GOOGLE_OAUTH2_CLIENT_ID"),
 		},
 	}
 }
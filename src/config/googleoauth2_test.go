@@ -4,5 +4,9 @@ import "testing"
 
 func TestNewGoogleOAuth2ConfigProvider(t *testing.T) {
 	t.Parallel()
-	var _ = NewGoogleOAuth2ConfigProvider()
+	l := NewLoader(NewEnvSource())
+	var _ = NewGoogleOAuth2ConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
 }
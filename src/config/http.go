@@ -1,7 +1,24 @@
 package config
 
+import "time"
+
+const (
+	httpMetricsAddr       = "127.0.0.1:9090"
+	httpReadHeaderTimeout = 10 * time.Second
+	httpReadTimeout       = 30 * time.Second
+	httpWriteTimeout      = 30 * time.Second
+	httpIdleTimeout       = 120 * time.Second
+	httpShutdownTimeout   = 30 * time.Second
+)
+
 type HttpConfig struct {
-	Addr string
+	Addr              string
+	MetricsAddr       string // internal address serving prometheus metrics, empty disables
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	ShutdownTimeout   time.Duration // for draining requests and releasing resources
 }
 
 type HttpConfigProvider interface {
@@ -16,10 +33,22 @@ func (p *httpConfigProvider) HttpConfig() HttpConfig {
 	return p.httpConfig
 }
 
-func NewHttpConfigProvider() HttpConfigProvider {
+func NewHttpConfigProvider(l *Loader) HttpConfigProvider {
+	// empty config values read as unset so metrics are disabled with a flag
+	var metricsAddr string
+	if l.BoolWithDefault("HTTP_METRICS_ENABLED", true) {
+		metricsAddr = l.StringWithDefault("HTTP_METRICS_ADDR", httpMetricsAddr)
+	}
+
 	return &httpConfigProvider{
 		httpConfig: HttpConfig{
-			Addr: GetenvStringWithDefault("HTTP_ADDR", ":8080"),
+			Addr:              l.StringWithDefault("HTTP_ADDR", ":8080"),
+			MetricsAddr:       metricsAddr,
+			ReadHeaderTimeout: l.DurationWithDefault("HTTP_READ_HEADER_TIMEOUT", httpReadHeaderTimeout),
+			ReadTimeout:       l.DurationWithDefault("HTTP_READ_TIMEOUT", httpReadTimeout),
+			WriteTimeout:      l.DurationWithDefault("HTTP_WRITE_TIMEOUT", httpWriteTimeout),
+			IdleTimeout:       l.DurationWithDefault("HTTP_IDLE_TIMEOUT", httpIdleTimeout),
+			ShutdownTimeout:   l.DurationWithDefault("HTTP_SHUTDOWN_TIMEOUT", httpShutdownTimeout),
 		},
 	}
 }
@@ -4,5 +4,25 @@ import "testing"
 
 func TestNewHttpConfigProvider(t *testing.T) {
 	t.Parallel()
-	var _ = NewHttpConfigProvider()
+	l := NewLoader(NewEnvSource())
+	var _ = NewHttpConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewHttpConfigProviderMetricsAddr(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{})
+	if cf := NewHttpConfigProvider(l).HttpConfig(); cf.MetricsAddr != "127.0.0.1:9090" {
+		t.Errorf("expected metrics addr to default to %q got %q", "127.0.0.1:9090", cf.MetricsAddr)
+	}
+
+	l = NewLoader(MapSource{"HTTP_METRICS_ENABLED": "false"})
+	if cf := NewHttpConfigProvider(l).HttpConfig(); cf.MetricsAddr != "" {
+		t.Errorf("expected metrics addr to be empty got %q", cf.MetricsAddr)
+	}
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
 }
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	invitationTTL         = 7 * 24 * time.Hour
+	invitationTokenIssuer = "https://github.com/aravindanve/livemeet-server/invitation"
+)
+
+type InvitationConfig struct {
+	LinkURL string
+	Issuer  string
+	TTL     time.Duration
+}
+
+type InvitationConfigProvider interface {
+	InvitationConfig() InvitationConfig
+}
+
+type invitationConfigProvider struct {
+	invitationConfig atomic.Pointer[InvitationConfig] // swapped on reload
+}
+
+func (p *invitationConfigProvider) InvitationConfig() InvitationConfig {
+	return *p.invitationConfig.Load()
+}
+
+func NewInvitationConfigProvider(l *Loader) InvitationConfigProvider {
+	p := &invitationConfigProvider{}
+	p.invitationConfig.Store(&InvitationConfig{
+		LinkURL: l.StringWithDefault("INVITATION_LINK_URL", "http://localhost:3000"),
+		Issuer:  invitationTokenIssuer,
+		TTL:     l.DurationWithDefault("INVITATION_TTL", invitationTTL),
+	})
+	return p
+}
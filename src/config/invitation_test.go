@@ -0,0 +1,12 @@
+package config
+
+import "testing"
+
+func TestNewInvitationConfigProvider(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(NewEnvSource())
+	var _ = NewInvitationConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
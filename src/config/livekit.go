@@ -1,6 +1,11 @@
 package config
 
-import "time"
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/util"
+)
 
 const (
 	liveKitRoomTokenTTL = 15 * time.Minute
@@ -11,6 +16,12 @@ type LiveKitConfig struct {
 	APIKey       string
 	APISecret    string
 	RoomTokenTTL time.Duration
+	DataType     util.LiveKitDataType // used for all rooms, cbor requires every client to decode cbor
+
+	// PreviousAPISecret is still accepted when verifying until
+	// PreviousAPISecretExpiresAt so tokens signed before a rotation stay valid
+	PreviousAPISecret          string
+	PreviousAPISecretExpiresAt time.Time
 }
 
 type LiveKitConfigProvider interface {
@@ -18,20 +29,49 @@ type LiveKitConfigProvider interface {
 }
 
 type livekitConfigProvider struct {
-	livekitConfig LiveKitConfig
+	livekitConfig atomic.Pointer[LiveKitConfig] // swapped on reload
 }
 
 func (p *livekitConfigProvider) LiveKitConfig() LiveKitConfig {
-	return p.livekitConfig
+	return *p.livekitConfig.Load()
+}
+
+func NewLiveKitConfigProvider(l *Loader) LiveKitConfigProvider {
+	p := &livekitConfigProvider{}
+	p.livekitConfig.Store(&LiveKitConfig{
+		APIURL:       l.String("LIVEKIT_API_URL"),
+		APIKey:       l.String("LIVEKIT_API_KEY"),
+		APISecret:    l.String("LIVEKIT_API_SECRET"),
+		RoomTokenTTL: l.DurationWithDefault("LIVEKIT_ROOM_TOKEN_TTL", liveKitRoomTokenTTL),
+		DataType:     loadLiveKitDataType(l, "LIVEKIT_DATA_ENCODING"),
+	})
+	return p
+}
+
+// APISecrets returns the secrets accepted when verifying tokens and webhooks,
+// the current secret first
+func (c LiveKitConfig) APISecrets() []string {
+	if c.PreviousAPISecret != "" && time.Now().Before(c.PreviousAPISecretExpiresAt) {
+		return []string{c.APISecret, c.PreviousAPISecret}
+	}
+	return []string{c.APISecret}
+}
+
+// keeps the secret of curr in next for the room token ttl when it was
+// rotated, otherwise carries over the previous secret of curr
+func rotateLiveKitSecret(curr, next *LiveKitConfig) {
+	if curr.APISecret != next.APISecret {
+		next.PreviousAPISecret, next.PreviousAPISecretExpiresAt = curr.APISecret, time.Now().Add(max(curr.RoomTokenTTL, next.RoomTokenTTL))
+	} else {
+		next.PreviousAPISecret, next.PreviousAPISecretExpiresAt = curr.PreviousAPISecret, curr.PreviousAPISecretExpiresAt
+	}
 }
 
-func NewLiveKitConfigProvider() LiveKitConfigProvider {
-	return &livekitConfigProvider{
-		livekitConfig: LiveKitConfig{
-			APIURL:       GetenvString("LIVEKIT_API_URL"),
-			APIKey:       GetenvString("LIVEKIT_API_KEY"),
-			APISecret:    GetenvString("LIVEKIT_API_SECRET"),
-			RoomTokenTTL: liveKitRoomTokenTTL,
-		},
+func loadLiveKitDataType(l *Loader, name string) util.LiveKitDataType {
+	s := l.StringWithDefault(name, "json")
+	t, err := util.ParseLiveKitDataType(s)
+	if err != nil {
+		l.Errorf("unable to parse config value %s (json or cbor) from %s", name, s)
 	}
+	return t
 }
@@ -1,8 +1,38 @@
 package config
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestNewLiveKitConfigProvider(t *testing.T) {
 	t.Parallel()
-	var _ = NewLiveKitConfigProvider()
+	l := NewLoader(NewEnvSource())
+	var _ = NewLiveKitConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRotateLiveKitSecret(t *testing.T) {
+	t.Parallel()
+	curr := &LiveKitConfig{APISecret: "old", RoomTokenTTL: time.Minute}
+	next := &LiveKitConfig{APISecret: "new", RoomTokenTTL: time.Minute}
+	rotateLiveKitSecret(curr, next)
+	if s := next.APISecrets(); len(s) != 2 || s[0] != "new" || s[1] != "old" {
+		t.Errorf("expected new and old secrets to be accepted got %v", s)
+	}
+
+	// carried over on reloads without rotation
+	same := &LiveKitConfig{APISecret: "new", RoomTokenTTL: time.Minute}
+	rotateLiveKitSecret(next, same)
+	if s := same.APISecrets(); len(s) != 2 || s[1] != "old" {
+		t.Errorf("expected old secret to be carried over got %v", s)
+	}
+
+	// dropped after the grace window
+	same.PreviousAPISecretExpiresAt = time.Now().Add(-time.Second)
+	if s := same.APISecrets(); len(s) != 1 || s[0] != "new" {
+		t.Errorf("expected only the new secret to be accepted got %v", s)
+	}
 }
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source looks up raw config values by name, e.g. AUTH_SECRET
+type Source interface {
+	Lookup(name string) (string, bool)
+}
+
+// MapSource looks up values in a map, empty values are treated as unset
+type MapSource map[string]string
+
+func (s MapSource) Lookup(name string) (string, bool) {
+	v, ok := s[name]
+	return v, ok && v != ""
+}
+
+// names returns the sorted names of a source for reporting
+func (s MapSource) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Loader reads typed config values from sources in order of precedence and
+// collects every problem found instead of stopping at the first one
+type Loader struct {
+	sources []Source
+	used    map[string]bool
+	errs    []error
+}
+
+func NewLoader(sources ...Source) *Loader {
+	return &Loader{
+		sources: sources,
+		used:    map[string]bool{},
+	}
+}
+
+// Validate returns all problems found by lookups so far
+func (l *Loader) Validate() error {
+	return errors.Join(l.errs...)
+}
+
+// Errorf records a problem
+func (l *Loader) Errorf(format string, a ...any) {
+	l.errs = append(l.errs, fmt.Errorf(format, a...))
+}
+
+// Used returns whether name was looked up
+func (l *Loader) Used(name string) bool {
+	return l.used[name]
+}
+
+// looks up name in each source, falling back to reading the file at name_FILE
+// for docker and kubernetes secrets
+func (l *Loader) lookup(name string) (string, bool) {
+	l.used[name] = true
+	l.used[name+"_FILE"] = true
+	for _, s := range l.sources {
+		if v, ok := s.Lookup(name); ok {
+			return v, true
+		}
+		if path, ok := s.Lookup(name + "_FILE"); ok {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				l.Errorf("unable to read config value %s from file: %s", name, err.Error())
+				return "", true
+			}
+			return strings.TrimRight(string(b), "\r\n"), true
+		}
+	}
+	return "", false
+}
+
+func (l *Loader) String(name string) string {
+	s, ok := l.lookup(name)
+	if !ok {
+		l.Errorf("config value %s (string) missing", name)
+	}
+	return s
+}
+
+func (l *Loader) StringWithDefault(name string, defaultValue string) string {
+	s, ok := l.lookup(name)
+	if !ok {
+		return defaultValue
+	}
+	return s
+}
+
+func (l *Loader) Int(name string) int {
+	s, ok := l.lookup(name)
+	if !ok {
+		l.Errorf("config value %s (int) missing", name)
+		return 0
+	}
+	return l.parseInt(name, s)
+}
+
+func (l *Loader) IntWithDefault(name string, defaultValue int) int {
+	s, ok := l.lookup(name)
+	if !ok {
+		return defaultValue
+	}
+	return l.parseInt(name, s)
+}
+
+func (l *Loader) parseInt(name string, s string) int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		l.Errorf("unable to parse config value %s (int) from %s", name, s)
+	}
+	return i
+}
+
+// PositiveIntWithDefault is IntWithDefault for counts and capacities
+func (l *Loader) PositiveIntWithDefault(name string, defaultValue int) int {
+	i := l.IntWithDefault(name, defaultValue)
+	if i < 1 {
+		l.Errorf("config value %s (int) must be positive, got %d", name, i)
+	}
+	return i
+}
+
+func (l *Loader) Bool(name string) bool {
+	s, ok := l.lookup(name)
+	if !ok {
+		l.Errorf("config value %s (bool) missing", name)
+		return false
+	}
+	return l.parseBool(name, s)
+}
+
+func (l *Loader) BoolWithDefault(name string, defaultValue bool) bool {
+	s, ok := l.lookup(name)
+	if !ok {
+		return defaultValue
+	}
+	return l.parseBool(name, s)
+}
+
+func (l *Loader) parseBool(name string, s string) bool {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		l.Errorf("unable to parse config value %s (bool) from %s", name, s)
+	}
+	return b
+}
+
+func (l *Loader) FloatWithDefault(name string, defaultValue float64) float64 {
+	s, ok := l.lookup(name)
+	if !ok {
+		return defaultValue
+	}
+	return l.parseFloat(name, s)
+}
+
+func (l *Loader) parseFloat(name string, s string) float64 {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		l.Errorf("unable to parse config value %s (float) from %s", name, s)
+	}
+	return f
+}
+
+// DurationWithDefault reads a positive duration such as 90s, 15m or 720h
+func (l *Loader) DurationWithDefault(name string, defaultValue time.Duration) time.Duration {
+	s, ok := l.lookup(name)
+	if !ok {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		l.Errorf("unable to parse config value %s (positive duration) from %s", name, s)
+	}
+	return d
+}
+
+func (l *Loader) BytesBase64(name string) []byte {
+	s, ok := l.lookup(name)
+	if !ok {
+		l.Errorf("config value %s (base64 string) missing", name)
+		return nil
+	}
+	return l.parseBytesBase64(name, s)
+}
+
+func (l *Loader) BytesBase64WithDefault(name string, defaultValue []byte) []byte {
+	s, ok := l.lookup(name)
+	if !ok {
+		return defaultValue
+	}
+	return l.parseBytesBase64(name, s)
+}
+
+func (l *Loader) parseBytesBase64(name string, s string) []byte {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		l.Errorf("unable to decode config value %s (base64 string): %s", name, err.Error())
+	}
+	return b
+}
+
+// StringsWithDefault reads a comma separated list, empty items are dropped
+func (l *Loader) StringsWithDefault(name string, defaultValue []string) []string {
+	s, ok := l.lookup(name)
+	if !ok {
+		return defaultValue
+	}
+	items := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoaderStringUnset(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{})
+	l.String("UNSET")
+
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected String to report UNSET missing")
+	}
+}
+
+func TestLoaderStringWithDefault(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"EMPTY": ""})
+	a := "hello"
+
+	if b := l.StringWithDefault("UNSET", a); b != a {
+		t.Errorf("expected UNSET to be %v got %v", a, b)
+	}
+	if b := l.StringWithDefault("EMPTY", a); b != a {
+		t.Errorf("expected EMPTY to be %v got %v", a, b)
+	}
+}
+
+func TestLoaderStringPrecedence(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"A": "first"}, MapSource{"A": "second", "B": "second"})
+
+	if a := l.String("A"); a != "first" {
+		t.Errorf("expected A to be first got %v", a)
+	}
+	if b := l.String("B"); b != "second" {
+		t.Errorf("expected B to be second got %v", b)
+	}
+}
+
+func TestLoaderInt(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"TEST_INT": "42"})
+
+	if b := l.Int("TEST_INT"); b != 42 {
+		t.Errorf("expected TEST_INT to be 42 got %v", b)
+	}
+	if b := l.IntWithDefault("UNSET", 7); b != 7 {
+		t.Errorf("expected UNSET to be 7 got %v", b)
+	}
+	if err := l.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoaderIntInvalid(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"TEST_INT": "forty two", "TEST_ZERO": "0"})
+	l.Int("TEST_INT")
+	l.PositiveIntWithDefault("TEST_ZERO", 1)
+
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected invalid ints to be reported")
+	}
+}
+
+func TestLoaderBool(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"TEST_BOOL": "true"})
+
+	if b := l.Bool("TEST_BOOL"); !b {
+		t.Errorf("expected TEST_BOOL to be true")
+	}
+	if b := l.BoolWithDefault("UNSET", true); !b {
+		t.Errorf("expected UNSET to be true")
+	}
+	l.Bool("UNSET")
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected Bool to report UNSET missing")
+	}
+}
+
+func TestLoaderDurationWithDefault(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"TEST_DURATION": "15m"})
+
+	if d := l.DurationWithDefault("TEST_DURATION", time.Hour); d != 15*time.Minute {
+		t.Errorf("expected TEST_DURATION to be 15m got %v", d)
+	}
+	if d := l.DurationWithDefault("UNSET", time.Hour); d != time.Hour {
+		t.Errorf("expected UNSET to be 1h got %v", d)
+	}
+	if err := l.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	l = NewLoader(MapSource{"TEST_DURATION": "-1m"})
+	l.DurationWithDefault("TEST_DURATION", time.Hour)
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected negative duration to be reported")
+	}
+}
+
+func TestLoaderBytesBase64(t *testing.T) {
+	t.Parallel()
+	a := []byte("hello")
+	l := NewLoader(MapSource{"TEST_BYTES": base64.StdEncoding.EncodeToString(a)})
+
+	if b := l.BytesBase64("TEST_BYTES"); !bytes.Equal(b, a) {
+		t.Errorf("expected TEST_BYTES to be %v got %v", string(a), string(b))
+	}
+	if b := l.BytesBase64WithDefault("UNSET", a); !bytes.Equal(b, a) {
+		t.Errorf("expected UNSET to be %v got %v", string(a), string(b))
+	}
+	l.BytesBase64("UNSET")
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected BytesBase64 to report UNSET missing")
+	}
+}
+
+func TestLoaderStringsWithDefault(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"TEST_STRINGS": "a, b,,c"})
+
+	if s := l.StringsWithDefault("TEST_STRINGS", nil); strings.Join(s, "|") != "a|b|c" {
+		t.Errorf("expected TEST_STRINGS to be [a b c] got %v", s)
+	}
+}
+
+func TestLoaderValidateReportsAll(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"TEST_INT": "x"})
+	l.String("UNSET_A")
+	l.String("UNSET_B")
+	l.Int("TEST_INT")
+
+	err := l.Validate()
+	if err == nil {
+		t.Fatalf("expected problems to be reported")
+	}
+	for _, name := range []string{"UNSET_A", "UNSET_B", "TEST_INT"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("expected problems to include %s got %v", name, err)
+		}
+	}
+}
+
+func TestLoaderFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("hello\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l := NewLoader(MapSource{"TEST_SECRET_FILE": path, "TEST_MISSING_FILE": path + "-missing"})
+
+	if s := l.String("TEST_SECRET"); s != "hello" {
+		t.Errorf("expected TEST_SECRET to be hello got %q", s)
+	}
+	if err := l.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	l.String("TEST_MISSING")
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected unreadable TEST_MISSING_FILE to be reported")
+	}
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+type LogConfig struct {
+	Level slog.Level
+}
+
+type LogConfigProvider interface {
+	LogConfig() LogConfig
+}
+
+type logConfigProvider struct {
+	logConfig atomic.Pointer[LogConfig] // swapped on reload
+}
+
+func (p *logConfigProvider) LogConfig() LogConfig {
+	return *p.logConfig.Load()
+}
+
+func NewLogConfigProvider(l *Loader) LogConfigProvider {
+	s := l.StringWithDefault("LOG_LEVEL", "info")
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		l.Errorf("unable to parse config value LOG_LEVEL (debug, info, warn or error) from %s", s)
+	}
+
+	p := &logConfigProvider{}
+	p.logConfig.Store(&LogConfig{
+		Level: level,
+	})
+	return p
+}
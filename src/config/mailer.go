@@ -0,0 +1,82 @@
+package config
+
+const (
+	MailerTransport_SMTP MailerTransport = "smtp"
+	MailerTransport_Log  MailerTransport = "log"
+)
+
+type MailerTransport string
+
+type MailerConfig struct {
+	Transport    MailerTransport
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	LogDir       string
+}
+
+type MailerConfigProvider interface {
+	MailerConfig() MailerConfig
+}
+
+type mailerConfigProvider struct {
+	mailerConfig MailerConfig
+}
+
+func (p *mailerConfigProvider) MailerConfig() MailerConfig {
+	return p.mailerConfig
+}
+
+// NewMailerConfigProvider requires MAILER_TRANSPORT unless APP_ENV is
+// development or testing, the log transport keeps invitation links that skip
+// the waiting room and passcode so it is only allowed in those environments
+func NewMailerConfigProvider(l *Loader) MailerConfigProvider {
+	local := isLocalAppEnv(l)
+
+	var transport MailerTransport
+	if local {
+		transport = MailerTransport(l.StringWithDefault("MAILER_TRANSPORT", string(MailerTransport_Log)))
+	} else {
+		transport = MailerTransport(l.String("MAILER_TRANSPORT"))
+	}
+
+	var mailerConfig MailerConfig
+	switch transport {
+	case MailerTransport_SMTP:
+		mailerConfig = MailerConfig{
+			Transport:    transport,
+			From:         l.String("MAILER_FROM"),
+			SMTPHost:     l.String("MAILER_SMTP_HOST"),
+			SMTPPort:     l.PositiveIntWithDefault("MAILER_SMTP_PORT", 587),
+			SMTPUsername: l.StringWithDefault("MAILER_SMTP_USERNAME", ""),
+			SMTPPassword: l.StringWithDefault("MAILER_SMTP_PASSWORD", ""),
+		}
+	case MailerTransport_Log:
+		if !local {
+			l.Errorf("config value MAILER_TRANSPORT log is only allowed with APP_ENV development or testing")
+		}
+		mailerConfig = MailerConfig{
+			Transport: transport,
+			From:      l.StringWithDefault("MAILER_FROM", "livemeet@localhost"),
+			LogDir:    l.StringWithDefault("MAILER_LOG_DIR", ""),
+		}
+	case "":
+		// reported as missing
+	default:
+		l.Errorf("unexpected config value MAILER_TRANSPORT (smtp or log) value %s", transport)
+	}
+
+	return &mailerConfigProvider{
+		mailerConfig: mailerConfig,
+	}
+}
+
+func isLocalAppEnv(l *Loader) bool {
+	switch l.StringWithDefault("APP_ENV", "") {
+	case "development", "testing":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package config
+
+import "testing"
+
+func TestNewMailerConfigProvider(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(NewEnvSource())
+	var _ = NewMailerConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewMailerConfigProviderLogTransport(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"APP_ENV": "testing"})
+	if cf := NewMailerConfigProvider(l).MailerConfig(); cf.Transport != MailerTransport_Log {
+		t.Errorf("expected transport to default to log got %v", cf.Transport)
+	}
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// required outside development and testing
+	l = NewLoader(MapSource{})
+	var _ = NewMailerConfigProvider(l)
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected missing MAILER_TRANSPORT to fail")
+	}
+
+	l = NewLoader(MapSource{"APP_ENV": "production", "MAILER_TRANSPORT": "log"})
+	var _ = NewMailerConfigProvider(l)
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected log transport to fail in production")
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	meetingTTL             = 365 * 24 * time.Hour
+	meetingRefreshInterval = 24 * time.Hour
+)
+
+type MeetingConfig struct {
+	TTL             time.Duration
+	RefreshInterval time.Duration // meetings in use are extended once per interval
+}
+
+type MeetingConfigProvider interface {
+	MeetingConfig() MeetingConfig
+}
+
+type meetingConfigProvider struct {
+	meetingConfig atomic.Pointer[MeetingConfig] // swapped on reload
+}
+
+func (p *meetingConfigProvider) MeetingConfig() MeetingConfig {
+	return *p.meetingConfig.Load()
+}
+
+func NewMeetingConfigProvider(l *Loader) MeetingConfigProvider {
+	ttl := l.DurationWithDefault("MEETING_TTL", meetingTTL)
+	refreshInterval := l.DurationWithDefault("MEETING_REFRESH_INTERVAL", meetingRefreshInterval)
+	if refreshInterval >= ttl {
+		l.Errorf("config value MEETING_REFRESH_INTERVAL must be less than MEETING_TTL")
+	}
+
+	p := &meetingConfigProvider{}
+	p.meetingConfig.Store(&MeetingConfig{
+		TTL:             ttl,
+		RefreshInterval: refreshInterval,
+	})
+	return p
+}
@@ -0,0 +1,12 @@
+package config
+
+import "testing"
+
+func TestNewMeetingConfigProvider(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(NewEnvSource())
+	var _ = NewMeetingConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
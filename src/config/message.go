@@ -0,0 +1,34 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	messageTTL = 365 * 24 * time.Hour
+)
+
+type MessageConfig struct {
+	TTL time.Duration
+}
+
+type MessageConfigProvider interface {
+	MessageConfig() MessageConfig
+}
+
+type messageConfigProvider struct {
+	messageConfig atomic.Pointer[MessageConfig] // swapped on reload
+}
+
+func (p *messageConfigProvider) MessageConfig() MessageConfig {
+	return *p.messageConfig.Load()
+}
+
+func NewMessageConfigProvider(l *Loader) MessageConfigProvider {
+	p := &messageConfigProvider{}
+	p.messageConfig.Store(&MessageConfig{
+		TTL: l.DurationWithDefault("MESSAGE_TTL", messageTTL),
+	})
+	return p
+}
@@ -0,0 +1,12 @@
+package config
+
+import "testing"
+
+func TestNewMessageConfigProvider(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(NewEnvSource())
+	var _ = NewMessageConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
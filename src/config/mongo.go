@@ -16,10 +16,10 @@ func (p *mongoConfigProvider) MongoConfig() MongoConfig {
 	return p.mongoConfig
 }
 
-func NewMongoConfigProvider() MongoConfigProvider {
+func NewMongoConfigProvider(l *Loader) MongoConfigProvider {
 	return &mongoConfigProvider{
 		mongoConfig: MongoConfig{
-			ConnectionURI: GetenvString("MONGO_CONNECTION_URI"),
+			ConnectionURI: l.String("MONGO_CONNECTION_URI"),
 		},
 	}
 }
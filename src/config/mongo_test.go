@@ -4,5 +4,9 @@ import "testing"
 
 func TestNewMongoConfigProvider(t *testing.T) {
 	t.Parallel()
-	var _ = NewMongoConfigProvider()
+	l := NewLoader(NewEnvSource())
+	var _ = NewMongoConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
 }
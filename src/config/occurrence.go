@@ -0,0 +1,34 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	occurrenceTTL = 30 * 24 * time.Hour
+)
+
+type OccurrenceConfig struct {
+	TTL time.Duration // kept after the occurrence ends
+}
+
+type OccurrenceConfigProvider interface {
+	OccurrenceConfig() OccurrenceConfig
+}
+
+type occurrenceConfigProvider struct {
+	occurrenceConfig atomic.Pointer[OccurrenceConfig] // swapped on reload
+}
+
+func (p *occurrenceConfigProvider) OccurrenceConfig() OccurrenceConfig {
+	return *p.occurrenceConfig.Load()
+}
+
+func NewOccurrenceConfigProvider(l *Loader) OccurrenceConfigProvider {
+	p := &occurrenceConfigProvider{}
+	p.occurrenceConfig.Store(&OccurrenceConfig{
+		TTL: l.DurationWithDefault("OCCURRENCE_TTL", occurrenceTTL),
+	})
+	return p
+}
@@ -0,0 +1,12 @@
+package config
+
+import "testing"
+
+func TestNewOccurrenceConfigProvider(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(NewEnvSource())
+	var _ = NewOccurrenceConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	participantTTL                = 30 * time.Minute
+	passcodeFailuresWindow        = 15 * time.Minute
+	passcodeFailuresMaxPerIP      = 10
+	passcodeFailuresMaxPerMeeting = 100
+)
+
+type ParticipantConfig struct {
+	TTL                           time.Duration
+	PasscodeFailuresWindow        time.Duration
+	PasscodeFailuresMaxPerIP      int // attempts without success per window
+	PasscodeFailuresMaxPerMeeting int // attempts from any ip per window
+}
+
+type ParticipantConfigProvider interface {
+	ParticipantConfig() ParticipantConfig
+}
+
+type participantConfigProvider struct {
+	participantConfig atomic.Pointer[ParticipantConfig] // swapped on reload
+}
+
+func (p *participantConfigProvider) ParticipantConfig() ParticipantConfig {
+	return *p.participantConfig.Load()
+}
+
+func NewParticipantConfigProvider(l *Loader) ParticipantConfigProvider {
+	p := &participantConfigProvider{}
+	p.participantConfig.Store(&ParticipantConfig{
+		TTL:                           l.DurationWithDefault("PARTICIPANT_TTL", participantTTL),
+		PasscodeFailuresWindow:        l.DurationWithDefault("PARTICIPANT_PASSCODE_FAILURES_WINDOW", passcodeFailuresWindow),
+		PasscodeFailuresMaxPerIP:      l.PositiveIntWithDefault("PARTICIPANT_PASSCODE_FAILURES_MAX_PER_IP", passcodeFailuresMaxPerIP),
+		PasscodeFailuresMaxPerMeeting: l.PositiveIntWithDefault("PARTICIPANT_PASSCODE_FAILURES_MAX_PER_MEETING", passcodeFailuresMaxPerMeeting),
+	})
+	return p
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"net/netip"
+	"strings"
+	"sync/atomic"
+)
+
+// ProxyConfig lists the reverse proxies and load balancers whose
+// x-forwarded-for and x-forwarded-proto headers are trusted, as ip addresses
+// or cidr ranges
+type ProxyConfig struct {
+	TrustedProxies []netip.Prefix
+}
+
+type ProxyConfigProvider interface {
+	ProxyConfig() ProxyConfig
+}
+
+type proxyConfigProvider struct {
+	proxyConfig atomic.Pointer[ProxyConfig] // swapped on reload
+}
+
+func (p *proxyConfigProvider) ProxyConfig() ProxyConfig {
+	return *p.proxyConfig.Load()
+}
+
+func NewProxyConfigProvider(l *Loader) ProxyConfigProvider {
+	var trusted []netip.Prefix
+	for _, s := range l.StringsWithDefault("PROXY_TRUSTED_PROXIES", nil) {
+		prefix, ok := parseProxyPrefix(s)
+		if !ok {
+			l.Errorf("unable to parse config value PROXY_TRUSTED_PROXIES (ip or cidr) from %s", s)
+			continue
+		}
+		trusted = append(trusted, prefix)
+	}
+
+	p := &proxyConfigProvider{}
+	p.proxyConfig.Store(&ProxyConfig{
+		TrustedProxies: trusted,
+	})
+	return p
+}
+
+func parseProxyPrefix(s string) (netip.Prefix, bool) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err == nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()), true
+}
@@ -0,0 +1,36 @@
+package config
+
+import "testing"
+
+func TestNewProxyConfigProvider(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(NewEnvSource())
+	var _ = NewProxyConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseProxyPrefix(t *testing.T) {
+	t.Parallel()
+
+	for s, want := range map[string]string{
+		"10.0.0.0/8":       "10.0.0.0/8",
+		"10.1.2.3/8":       "10.0.0.0/8",
+		"127.0.0.1":        "127.0.0.1/32",
+		"::ffff:127.0.0.1": "127.0.0.1/32",
+		"fd00::/8":         "fd00::/8",
+		"::1":              "::1/128",
+	} {
+		prefix, ok := parseProxyPrefix(s)
+		if !ok || prefix.String() != want {
+			t.Errorf("expected %s to be parsed as %s got %v %v", s, want, prefix, ok)
+		}
+	}
+
+	for _, s := range []string{"localhost", "10.0.0.0/33", "10.0.0"} {
+		if _, ok := parseProxyPrefix(s); ok {
+			t.Errorf("expected %s to fail", s)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	RateLimitStore_Memory RateLimitStore = "memory"
+	RateLimitStore_Mongo  RateLimitStore = "mongo"
+)
+
+const (
+	rateLimitStoreTimeout   = 2 * time.Second
+	rateLimitMemoryCapacity = 100000
+)
+
+type RateLimitStore string
+
+// RateLimitOverride replaces the limit of a policy by name, e.g. the value
+// authCreate=10/1m allows 10 requests per minute
+type RateLimitOverride struct {
+	Requests int
+	Per      time.Duration
+}
+
+type RateLimitConfig struct {
+	Enabled        bool
+	Store          RateLimitStore
+	StoreTimeout   time.Duration
+	MemoryCapacity int
+	Overrides      map[string]RateLimitOverride
+}
+
+type RateLimitConfigProvider interface {
+	RateLimitConfig() RateLimitConfig
+}
+
+type rateLimitConfigProvider struct {
+	rateLimitConfig atomic.Pointer[RateLimitConfig] // swapped on reload
+}
+
+func (p *rateLimitConfigProvider) RateLimitConfig() RateLimitConfig {
+	return *p.rateLimitConfig.Load()
+}
+
+func NewRateLimitConfigProvider(l *Loader) RateLimitConfigProvider {
+	store := RateLimitStore(l.StringWithDefault("RATE_LIMIT_STORE", string(RateLimitStore_Memory)))
+	if store != RateLimitStore_Memory && store != RateLimitStore_Mongo {
+		l.Errorf("unexpected config value RATE_LIMIT_STORE (memory or mongo) value %s", store)
+	}
+
+	overrides := map[string]RateLimitOverride{}
+	for _, s := range l.StringsWithDefault("RATE_LIMIT_OVERRIDES", nil) {
+		name, o, ok := parseRateLimitOverride(s)
+		if !ok {
+			l.Errorf("unable to parse config value RATE_LIMIT_OVERRIDES (name=requests/duration) from %s", s)
+			continue
+		}
+		overrides[name] = o
+	}
+
+	p := &rateLimitConfigProvider{}
+	p.rateLimitConfig.Store(&RateLimitConfig{
+		Enabled:        l.BoolWithDefault("RATE_LIMIT_ENABLED", true),
+		Store:          store,
+		StoreTimeout:   l.DurationWithDefault("RATE_LIMIT_STORE_TIMEOUT", rateLimitStoreTimeout),
+		MemoryCapacity: l.PositiveIntWithDefault("RATE_LIMIT_MEMORY_CAPACITY", rateLimitMemoryCapacity),
+		Overrides:      overrides,
+	})
+	return p
+}
+
+func parseRateLimitOverride(s string) (string, RateLimitOverride, bool) {
+	name, limit, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return "", RateLimitOverride{}, false
+	}
+	requests, per, ok := strings.Cut(limit, "/")
+	if !ok {
+		return "", RateLimitOverride{}, false
+	}
+	r, err := strconv.Atoi(requests)
+	if err != nil || r < 1 {
+		return "", RateLimitOverride{}, false
+	}
+	p, err := time.ParseDuration(per)
+	if err != nil || p <= 0 {
+		return "", RateLimitOverride{}, false
+	}
+	return name, RateLimitOverride{Requests: r, Per: p}, true
+}
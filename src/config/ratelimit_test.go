@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRateLimitConfigProvider(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(NewEnvSource())
+	var _ = NewRateLimitConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseRateLimitOverride(t *testing.T) {
+	t.Parallel()
+
+	name, o, ok := parseRateLimitOverride("authCreate=10/1m")
+	if !ok || name != "authCreate" || o.Requests != 10 || o.Per != time.Minute {
+		t.Errorf("expected authCreate=10/1m to be parsed got %v %v %v", name, o, ok)
+	}
+
+	for _, s := range []string{"authCreate", "=10/1m", "authCreate=10", "authCreate=0/1m", "authCreate=10/0s"} {
+		if _, _, ok := parseRateLimitOverride(s); ok {
+			t.Errorf("expected %s to fail", s)
+		}
+	}
+}
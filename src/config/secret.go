@@ -0,0 +1,57 @@
+package config
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	secretRefreshInterval = 5 * time.Minute
+	vaultKVMount          = "secret"
+	vaultKVPath           = "livemeet-server"
+)
+
+// SecretSource is a source backed by a secret manager, values are cached and
+// updated by Refresh
+type SecretSource interface {
+	Source
+	Refresh(ctx context.Context) error
+}
+
+type SecretConfig struct {
+	VaultAddr       string // vault is not used if empty
+	VaultToken      string
+	VaultKVMount    string
+	VaultKVPath     string
+	RefreshInterval time.Duration
+}
+
+type SecretConfigProvider interface {
+	SecretConfig() SecretConfig
+}
+
+type secretConfigProvider struct {
+	secretConfig SecretConfig
+}
+
+func (p *secretConfigProvider) SecretConfig() SecretConfig {
+	return p.secretConfig
+}
+
+func NewSecretConfigProvider(l *Loader) SecretConfigProvider {
+	addr := l.StringWithDefault("VAULT_ADDR", "")
+	token := l.StringWithDefault("VAULT_TOKEN", "")
+	if addr != "" && token == "" {
+		l.Errorf("config value VAULT_TOKEN (string) missing, required with VAULT_ADDR")
+	}
+
+	return &secretConfigProvider{
+		secretConfig: SecretConfig{
+			VaultAddr:       addr,
+			VaultToken:      token,
+			VaultKVMount:    l.StringWithDefault("VAULT_KV_MOUNT", vaultKVMount),
+			VaultKVPath:     l.StringWithDefault("VAULT_KV_PATH", vaultKVPath),
+			RefreshInterval: l.DurationWithDefault("SECRET_REFRESH_INTERVAL", secretRefreshInterval),
+		},
+	}
+}
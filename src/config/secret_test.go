@@ -0,0 +1,21 @@
+package config
+
+import "testing"
+
+func TestNewSecretConfigProvider(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(NewEnvSource())
+	var _ = NewSecretConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewSecretConfigProviderMissingToken(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"VAULT_ADDR": "http://127.0.0.1:8200"})
+	var _ = NewSecretConfigProvider(l)
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected missing VAULT_TOKEN to be reported")
+	}
+}
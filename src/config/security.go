@@ -0,0 +1,48 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	securityHSTSMaxAge = 180 * 24 * time.Hour
+)
+
+// SecurityConfig sets the security headers of every response, hsts is only
+// sent for https requests
+type SecurityConfig struct {
+	HSTSEnabled           bool
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	ReferrerPolicy        string
+	FrameOptions          string
+	ContentSecurityPolicy string
+}
+
+type SecurityConfigProvider interface {
+	SecurityConfig() SecurityConfig
+}
+
+type securityConfigProvider struct {
+	securityConfig atomic.Pointer[SecurityConfig] // swapped on reload
+}
+
+func (p *securityConfigProvider) SecurityConfig() SecurityConfig {
+	return *p.securityConfig.Load()
+}
+
+func NewSecurityConfigProvider(l *Loader) SecurityConfigProvider {
+	p := &securityConfigProvider{}
+	p.securityConfig.Store(&SecurityConfig{
+		HSTSEnabled:           l.BoolWithDefault("SECURITY_HSTS_ENABLED", true),
+		HSTSMaxAge:            l.DurationWithDefault("SECURITY_HSTS_MAX_AGE", securityHSTSMaxAge),
+		HSTSIncludeSubdomains: l.BoolWithDefault("SECURITY_HSTS_INCLUDE_SUBDOMAINS", false),
+		HSTSPreload:           l.BoolWithDefault("SECURITY_HSTS_PRELOAD", false),
+		ReferrerPolicy:        l.StringWithDefault("SECURITY_REFERRER_POLICY", "no-referrer"),
+		FrameOptions:          l.StringWithDefault("SECURITY_FRAME_OPTIONS", "DENY"),
+		ContentSecurityPolicy: l.StringWithDefault("SECURITY_CONTENT_SECURITY_POLICY", "default-src 'none'; frame-ancestors 'none'"),
+	})
+	return p
+}
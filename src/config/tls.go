@@ -0,0 +1,84 @@
+package config
+
+const (
+	TLSMode_Off  TLSMode = "off"
+	TLSMode_File TLSMode = "file"
+	TLSMode_ACME TLSMode = "acme"
+)
+
+const (
+	TLSCertCache_Dir   TLSCertCache = "dir"
+	TLSCertCache_Mongo TLSCertCache = "mongo"
+)
+
+type TLSMode string
+
+// TLSCertCache is where certificates issued by acme are kept
+type TLSCertCache string
+
+type TLSConfig struct {
+	Mode             TLSMode
+	CertFile         string
+	KeyFile          string
+	ACMEDomains      []string
+	ACMEEmail        string
+	ACMEDirectoryURL string // empty for let's encrypt
+	CertCache        TLSCertCache
+	CertCacheDir     string
+	RedirectAddr     string // serves acme http challenges and redirects to https, empty disables
+}
+
+type TLSConfigProvider interface {
+	TLSConfig() TLSConfig
+}
+
+type tlsConfigProvider struct {
+	tlsConfig TLSConfig
+}
+
+func (p *tlsConfigProvider) TLSConfig() TLSConfig {
+	return p.tlsConfig
+}
+
+func NewTLSConfigProvider(l *Loader) TLSConfigProvider {
+	mode := TLSMode(l.StringWithDefault("TLS_MODE", string(TLSMode_Off)))
+
+	var tlsConfig TLSConfig
+	switch mode {
+	case TLSMode_Off:
+		tlsConfig = TLSConfig{
+			Mode: mode,
+		}
+	case TLSMode_File:
+		tlsConfig = TLSConfig{
+			Mode:         mode,
+			CertFile:     l.String("TLS_CERT_FILE"),
+			KeyFile:      l.String("TLS_KEY_FILE"),
+			RedirectAddr: l.StringWithDefault("TLS_REDIRECT_ADDR", ""),
+		}
+	case TLSMode_ACME:
+		cache := TLSCertCache(l.StringWithDefault("TLS_CERT_CACHE", string(TLSCertCache_Dir)))
+		if cache != TLSCertCache_Dir && cache != TLSCertCache_Mongo {
+			l.Errorf("unexpected config value TLS_CERT_CACHE (dir or mongo) value %s", cache)
+		}
+		domains := l.StringsWithDefault("TLS_ACME_DOMAINS", nil)
+		if len(domains) == 0 {
+			l.Errorf("config value TLS_ACME_DOMAINS (string) missing")
+		}
+		tlsConfig = TLSConfig{
+			Mode:             mode,
+			ACMEDomains:      domains,
+			ACMEEmail:        l.StringWithDefault("TLS_ACME_EMAIL", ""),
+			ACMEDirectoryURL: l.StringWithDefault("TLS_ACME_DIRECTORY_URL", ""),
+			CertCache:        cache,
+			CertCacheDir:     l.StringWithDefault("TLS_CERT_CACHE_DIR", "certs"),
+			RedirectAddr:     l.StringWithDefault("TLS_REDIRECT_ADDR", ":80"),
+		}
+	default:
+		l.Errorf("unexpected config value TLS_MODE (off, file or acme) value %s", mode)
+	}
+
+	return &tlsConfigProvider{
+		tlsConfig: tlsConfig,
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import "testing"
+
+func TestNewTLSConfigProvider(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(NewEnvSource())
+	var _ = NewTLSConfigProvider(l)
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewTLSConfigProviderACME(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{
+		"TLS_MODE":         "acme",
+		"TLS_ACME_DOMAINS": "example.com, www.example.com",
+		"TLS_CERT_CACHE":   "mongo",
+	})
+	cf := NewTLSConfigProvider(l).TLSConfig()
+	if err := l.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cf.ACMEDomains) != 2 || cf.ACMEDomains[1] != "www.example.com" {
+		t.Errorf("expected acme domains to be parsed got %v", cf.ACMEDomains)
+	}
+	if cf.RedirectAddr != ":80" {
+		t.Errorf("expected redirect addr to default to :80 got %v", cf.RedirectAddr)
+	}
+
+	// acme requires domains
+	l = NewLoader(MapSource{"TLS_MODE": "acme"})
+	var _ = NewTLSConfigProvider(l)
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected missing domains to fail")
+	}
+}
+
+func TestNewTLSConfigProviderFileMissing(t *testing.T) {
+	t.Parallel()
+	l := NewLoader(MapSource{"TLS_MODE": "file", "TLS_CERT_FILE": "cert.pem"})
+	var _ = NewTLSConfigProvider(l)
+	if err := l.Validate(); err == nil {
+		t.Errorf("expected missing key file to fail")
+	}
+}
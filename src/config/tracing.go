@@ -0,0 +1,38 @@
+package config
+
+type TracingConfig struct {
+	Enabled      bool
+	OTLPEndpoint string
+	OTLPInsecure bool
+	ServiceName  string
+	SampleRatio  float64
+}
+
+type TracingConfigProvider interface {
+	TracingConfig() TracingConfig
+}
+
+type tracingConfigProvider struct {
+	tracingConfig TracingConfig
+}
+
+func (p *tracingConfigProvider) TracingConfig() TracingConfig {
+	return p.tracingConfig
+}
+
+func NewTracingConfigProvider(l *Loader) TracingConfigProvider {
+	ratio := l.FloatWithDefault("TRACING_SAMPLE_RATIO", 1)
+	if ratio < 0 || ratio > 1 {
+		l.Errorf("config value TRACING_SAMPLE_RATIO must be 0 to 1, got %v", ratio)
+	}
+
+	return &tracingConfigProvider{
+		tracingConfig: TracingConfig{
+			Enabled:      l.BoolWithDefault("TRACING_ENABLED", false),
+			OTLPEndpoint: l.StringWithDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318"),
+			OTLPInsecure: l.BoolWithDefault("OTEL_EXPORTER_OTLP_INSECURE", false),
+			ServiceName:  l.StringWithDefault("OTEL_SERVICE_NAME", "livemeet-server"),
+			SampleRatio:  ratio,
+		},
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	vaultTimeout     = 10 * time.Second
+	vaultTokenHeader = "x-vault-token"
+)
+
+type vaultSecretSource struct {
+	addr      string
+	path      string // of the kv v2 secret
+	token     string
+	renewable bool
+	client    *http.Client
+	mut       sync.RWMutex
+	values    map[string]string
+}
+
+// NewVaultSecretSource returns a source that reads a hashicorp vault kv v2
+// secret at mount/path, keys of the secret are config names such as
+// LIVEKIT_API_SECRET. The secret is read before returning. Renewable tokens
+// are renewed on every refresh so they stay valid as long as the refresh
+// interval is shorter than the token ttl, tokens that are not renewable must
+// not expire, use a periodic or root token in that case.
+func NewVaultSecretSource(ctx context.Context, addr, token, mount, path string) (SecretSource, error) {
+	addr = strings.TrimSuffix(addr, "/")
+	s := &vaultSecretSource{
+		addr:   addr,
+		path:   "/v1/" + url.PathEscape(mount) + "/data/" + strings.Trim(path, "/"),
+		token:  token,
+		client: &http.Client{Timeout: vaultTimeout},
+	}
+
+	var body vaultTokenResponse
+	if err := s.do(ctx, http.MethodGet, "/v1/auth/token/lookup-self", &body); err != nil {
+		return nil, fmt.Errorf("error looking up vault token: %w", err)
+	}
+	s.renewable = body.Data.Renewable
+
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *vaultSecretSource) Lookup(name string) (string, bool) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	v, ok := s.values[name]
+	return v, ok && v != ""
+}
+
+type vaultTokenResponse struct {
+	Data struct {
+		Renewable bool `json:"renewable"`
+	} `json:"data"`
+}
+
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]any `json:"data"`
+	} `json:"data"`
+}
+
+// Refresh renews the token if it is renewable and reads the latest version
+// of the secret
+func (s *vaultSecretSource) Refresh(ctx context.Context) error {
+	if s.renewable {
+		if err := s.do(ctx, http.MethodPost, "/v1/auth/token/renew-self", nil); err != nil {
+			return fmt.Errorf("error renewing vault token: %w", err)
+		}
+	}
+
+	var body vaultKVResponse
+	if err := s.do(ctx, http.MethodGet, s.path, &body); err != nil {
+		return fmt.Errorf("error reading vault secret: %w", err)
+	}
+
+	values := make(map[string]string, len(body.Data.Data))
+	for k, v := range body.Data.Data {
+		if v != nil {
+			values[k] = fmt.Sprint(v)
+		}
+	}
+
+	s.mut.Lock()
+	s.values = values
+	s.mut.Unlock()
+	return nil
+}
+
+// sends an authenticated request to path and decodes the response into out
+// when out is not nil
+func (s *vaultSecretSource) do(ctx context.Context, method, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, s.addr+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(vaultTokenHeader, s.token)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// vaultDevServer stands in for a vault dev server with a kv v2 engine
+type vaultDevServer struct {
+	*httptest.Server
+	mut       sync.Mutex
+	secrets   map[string]map[string]any
+	renewable bool
+	renewals  int
+}
+
+func newVaultDevServer(t *testing.T, token string) *vaultDevServer {
+	s := &vaultDevServer{secrets: map[string]map[string]any{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(vaultTokenHeader) != token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/auth/token/lookup-self":
+			s.mut.Lock()
+			renewable := s.renewable
+			s.mut.Unlock()
+			json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{"renewable": renewable, "ttl": 3600},
+			})
+			return
+		case "/v1/auth/token/renew-self":
+			s.mut.Lock()
+			renewable := s.renewable
+			s.renewals++
+			s.mut.Unlock()
+			if !renewable || r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"auth": map[string]any{"renewable": true, "lease_duration": 3600},
+			})
+			return
+		}
+		s.mut.Lock()
+		data, ok := s.secrets[r.URL.Path]
+		s.mut.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"data":     data,
+				"metadata": map[string]any{"version": 1},
+			},
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *vaultDevServer) put(mount, path string, data map[string]any) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.secrets["/v1/"+mount+"/data/"+path] = data
+}
+
+func TestVaultSecretSource(t *testing.T) {
+	t.Parallel()
+	vault := newVaultDevServer(t, "token")
+	vault.put("secret", "livemeet-server", map[string]any{
+		"LIVEKIT_API_SECRET": "secret",
+		"MAILER_SMTP_PORT":   587,
+	})
+
+	s, err := NewVaultSecretSource(context.Background(), vault.URL, "token", "secret", "livemeet-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.Lookup("LIVEKIT_API_SECRET"); v != "secret" {
+		t.Errorf("expected LIVEKIT_API_SECRET to be secret got %v", v)
+	}
+	if v, _ := s.Lookup("MAILER_SMTP_PORT"); v != "587" {
+		t.Errorf("expected MAILER_SMTP_PORT to be 587 got %v", v)
+	}
+
+	// rotate
+	vault.put("secret", "livemeet-server", map[string]any{
+		"LIVEKIT_API_SECRET": "rotated",
+	})
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.Lookup("LIVEKIT_API_SECRET"); v != "rotated" {
+		t.Errorf("expected LIVEKIT_API_SECRET to be rotated got %v", v)
+	}
+	if _, ok := s.Lookup("MAILER_SMTP_PORT"); ok {
+		t.Errorf("expected MAILER_SMTP_PORT to be removed")
+	}
+}
+
+func TestVaultSecretSourceRenew(t *testing.T) {
+	t.Parallel()
+	vault := newVaultDevServer(t, "token")
+	vault.renewable = true
+	vault.put("secret", "livemeet-server", map[string]any{})
+
+	s, err := NewVaultSecretSource(context.Background(), vault.URL, "token", "secret", "livemeet-server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	vault.mut.Lock()
+	defer vault.mut.Unlock()
+	if vault.renewals != 2 {
+		t.Errorf("expected token to be renewed on every refresh got %d renewals", vault.renewals)
+	}
+}
+
+func TestVaultSecretSourceInvalid(t *testing.T) {
+	t.Parallel()
+	vault := newVaultDevServer(t, "token")
+	vault.put("secret", "livemeet-server", map[string]any{})
+
+	if _, err := NewVaultSecretSource(context.Background(), vault.URL, "wrong", "secret", "livemeet-server"); err == nil {
+		t.Errorf("expected invalid token to fail")
+	}
+	if _, err := NewVaultSecretSource(context.Background(), vault.URL, "token", "secret", "missing"); err == nil {
+		t.Errorf("expected missing secret to fail")
+	}
+}
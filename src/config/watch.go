@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	watchDebounce = 100 * time.Millisecond
+)
+
+func (c *config) Watch(ctx context.Context, reloaded func(err error)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	refresh := time.NewTicker(c.SecretConfig().RefreshInterval)
+	defer refresh.Stop()
+
+	var changed <-chan struct{}
+	if c.path != "" {
+		var err error
+		if changed, err = watchFile(ctx, c.path); err != nil {
+			reloaded(err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-refresh.C:
+		case <-changed:
+		}
+		reloaded(c.Reload(ctx))
+	}
+}
+
+// watches the directory of path so that files replaced by editors or
+// kubernetes config map updates are seen, bursts of events are debounced
+func watchFile(ctx context.Context, path string) (<-chan struct{}, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error watching config file: %w", err)
+	}
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		w.Close()
+		return nil, fmt.Errorf("error watching config file: %w", err)
+	}
+
+	changed := make(chan struct{}, 1)
+	go func() {
+		defer w.Close()
+		debounce := time.NewTimer(watchDebounce)
+		debounce.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.Events:
+				debounce.Reset(watchDebounce)
+			case <-w.Errors:
+			case <-debounce.C:
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return changed, nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+const (
+	webhookDeliveryTTL         = 30 * 24 * time.Hour
+	webhookDeliveryAttemptsMax = 5
+	webhookDispatchTimeout     = 10 * time.Minute
+)
+
+type WebhookConfig struct {
+	DeliveryTTL         time.Duration
+	DeliveryAttemptsMax int
+	DispatchTimeout     time.Duration // for delivering an event to all webhooks
+}
+
+type WebhookConfigProvider interface {
+	WebhookConfig() WebhookConfig
+}
+
+type webhookConfigProvider struct {
+	webhookConfig atomic.Pointer[WebhookConfig] // swapped on reload
+}
+
+func (p *webhookConfigProvider) WebhookConfig() WebhookConfig {
+	return *p.webhookConfig.Load()
+}
+
+func NewWebhookConfigProvider(l *Loader) WebhookConfigProvider {
+	p := &webhookConfigProvider{}
+	p.webhookConfig.Store(&WebhookConfig{
+		DeliveryTTL:         l.DurationWithDefault("WEBHOOK_DELIVERY_TTL", webhookDeliveryTTL),
+		DeliveryAttemptsMax: l.PositiveIntWithDefault("WEBHOOK_DELIVERY_ATTEMPTS_MAX", webhookDeliveryAttemptsMax),
+		DispatchTimeout:     l.DurationWithDefault("WEBHOOK_DISPATCH_TIMEOUT", webhookDispatchTimeout),
+	})
+	return p
+}
@@ -0,0 +1,71 @@
+package event
+
+import (
+	"context"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	Operation_Insert Operation = "insert"
+	Operation_Update Operation = "update"
+)
+
+type Operation string
+
+// Event describes a document written to a collection
+type Event struct {
+	Collection string
+	Operation  Operation
+	ID         string
+	Document   bson.Raw
+	CreatedAt  time.Time
+}
+
+func NewEvent(collection string, operation Operation, id string, doc any) (Event, error) {
+	b, err := bson.Marshal(doc)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Collection: collection,
+		Operation:  operation,
+		ID:         id,
+		Document:   b,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+func (e Event) Decode(v any) error {
+	return bson.Unmarshal(e.Document, v)
+}
+
+type BusDeps interface {
+	config.EventConfigProvider
+}
+
+type BusProvider interface {
+	EventBus() Bus
+}
+
+type Bus interface {
+	// Publish delivers the event to subscribers
+	Publish(ctx context.Context, e Event) error
+	// Subscribe returns a channel of events which is closed when ctx is done
+	// or the bus is closed
+	Subscribe(ctx context.Context) <-chan Event
+	Close(ctx context.Context) error
+}
+
+func NewBus(ds BusDeps, db *mongo.Database, collections ...string) Bus {
+	switch ds.EventConfig().Transport {
+	case config.EventTransport_Mongo:
+		return NewMongoBus(db, collections...)
+	default:
+		return NewMemoryBus()
+	}
+}
@@ -0,0 +1,34 @@
+package event
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestNewEvent(t *testing.T) {
+	t.Parallel()
+	a := bson.M{"name": "hello"}
+
+	e, err := NewEvent("collection", Operation_Insert, "some-id", a)
+	if err != nil {
+		t.Fatalf("Error creating event: %#v", err)
+	}
+	if e.Collection != "collection" {
+		t.Fatalf("Unexpected collection: %#v", e.Collection)
+	}
+	if e.Operation != Operation_Insert {
+		t.Fatalf("Unexpected operation: %#v", e.Operation)
+	}
+	if e.ID != "some-id" {
+		t.Fatalf("Unexpected id: %#v", e.ID)
+	}
+
+	var b bson.M
+	if err := e.Decode(&b); err != nil {
+		t.Fatalf("Error decoding event: %#v", err)
+	}
+	if b["name"] != "hello" {
+		t.Fatalf("Unexpected decoded document: %#v", b)
+	}
+}
@@ -0,0 +1,115 @@
+package event
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/aravindanve/livemeet-server/src/metrics"
+)
+
+const (
+	memoryBusBufferSize = 64
+)
+
+type memorySubscriber struct {
+	ch   chan Event
+	done chan struct{} // closed before ch so publishers stop sending
+	once sync.Once
+}
+
+type memoryBus struct {
+	mut         sync.RWMutex // held for reading while publishing
+	closed      bool
+	closeOnce   sync.Once
+	done        chan struct{}
+	subscribers map[*memorySubscriber]struct{}
+}
+
+// NewMemoryBus returns a bus that delivers events within the process
+func NewMemoryBus() Bus {
+	return &memoryBus{
+		done:        make(chan struct{}),
+		subscribers: make(map[*memorySubscriber]struct{}),
+	}
+}
+
+// Publish waits for each subscriber with a full buffer until ctx is done,
+// events that could not be delivered are dropped and counted
+func (b *memoryBus) Publish(ctx context.Context, e Event) error {
+	b.mut.RLock()
+	defer b.mut.RUnlock()
+
+	for s := range b.subscribers {
+		select {
+		case s.ch <- e:
+		case <-s.done:
+		case <-b.done:
+		case <-ctx.Done():
+			metrics.EventsDroppedTotal.Inc()
+			slog.Warn("event dropped for slow subscriber",
+				"collection", e.Collection, "operation", e.Operation, "id", e.ID, "error", ctx.Err())
+		}
+	}
+	return nil
+}
+
+func (b *memoryBus) Subscribe(ctx context.Context) <-chan Event {
+	s := &memorySubscriber{
+		ch:   make(chan Event, memoryBusBufferSize),
+		done: make(chan struct{}),
+	}
+
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if b.closed {
+		close(s.ch)
+		return s.ch
+	}
+
+	b.subscribers[s] = struct{}{}
+
+	// unsubscribe when done
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.unsubscribe(s)
+		case <-b.done:
+		}
+	}()
+
+	return s.ch
+}
+
+func (b *memoryBus) unsubscribe(s *memorySubscriber) {
+	// stop publishers waiting on the subscriber before taking the lock
+	s.once.Do(func() { close(s.done) })
+
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.ch)
+	}
+}
+
+func (b *memoryBus) Close(ctx context.Context) error {
+	// stop publishers waiting on subscribers before taking the lock
+	b.closeOnce.Do(func() { close(b.done) })
+
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if b.closed {
+		return nil
+	}
+
+	b.closed = true
+	for s := range b.subscribers {
+		delete(b.subscribers, s)
+		close(s.ch)
+	}
+	return nil
+}
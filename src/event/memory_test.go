@@ -0,0 +1,152 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryBusPublish(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	b := NewMemoryBus()
+	defer b.Close(ctx)
+
+	ch1 := b.Subscribe(ctx)
+	ch2 := b.Subscribe(ctx)
+
+	e, _ := NewEvent("collection", Operation_Update, "some-id", map[string]string{})
+	if err := b.Publish(ctx, e); err != nil {
+		t.Fatalf("Error publishing event: %#v", err)
+	}
+
+	for _, ch := range []<-chan Event{ch1, ch2} {
+		select {
+		case v := <-ch:
+			if v.ID != "some-id" {
+				t.Fatalf("Unexpected event: %#v", v)
+			}
+		case <-ctx.Done():
+			t.Fatalf("Expected event got timeout")
+		}
+	}
+}
+
+func TestMemoryBusUnsubscribe(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	b := NewMemoryBus()
+	defer b.Close(ctx)
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	ch := b.Subscribe(subCtx)
+	subCancel()
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Fatalf("Expected channel to be closed")
+		}
+	case <-ctx.Done():
+		t.Fatalf("Expected channel to be closed got timeout")
+	}
+}
+
+func TestMemoryBusClose(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	b := NewMemoryBus()
+	ch := b.Subscribe(ctx)
+	b.Close(ctx)
+
+	if _, ok := <-ch; ok {
+		t.Fatalf("Expected channel to be closed")
+	}
+	if _, ok := <-b.Subscribe(ctx); ok {
+		t.Fatalf("Expected channel of closed bus to be closed")
+	}
+}
+
+func TestMemoryBusPublishSlowSubscriber(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	b := NewMemoryBus()
+	defer b.Close(ctx)
+
+	ch := b.Subscribe(ctx)
+
+	// fill the buffer
+	e, _ := NewEvent("collection", Operation_Update, "some-id", map[string]string{})
+	for i := 0; i < memoryBusBufferSize; i++ {
+		if err := b.Publish(ctx, e); err != nil {
+			t.Fatalf("Error publishing event: %#v", err)
+		}
+	}
+
+	// publish waits for the subscriber
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		<-ch
+	}()
+	last, _ := NewEvent("collection", Operation_Update, "last-id", map[string]string{})
+	if err := b.Publish(ctx, last); err != nil {
+		t.Fatalf("Error publishing event: %#v", err)
+	}
+
+	// publish returns when ctx is done
+	pubCtx, pubCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer pubCancel()
+	if err := b.Publish(pubCtx, e); err != nil {
+		t.Fatalf("Error publishing event: %#v", err)
+	}
+
+	for i := 0; i < memoryBusBufferSize-1; i++ {
+		<-ch
+	}
+	if v := <-ch; v.ID != "last-id" {
+		t.Fatalf("Expected last event to be delivered got %#v", v)
+	}
+	select {
+	case v := <-ch:
+		t.Fatalf("Expected event to be dropped got %#v", v)
+	default:
+	}
+}
+
+func TestMemoryBusCloseWhilePublishing(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	b := NewMemoryBus()
+	var _ = b.Subscribe(ctx)
+
+	// fill the buffer
+	e, _ := NewEvent("collection", Operation_Update, "some-id", map[string]string{})
+	for i := 0; i < memoryBusBufferSize; i++ {
+		b.Publish(ctx, e)
+	}
+
+	// close stops a waiting publish
+	done := make(chan struct{})
+	go func() {
+		b.Publish(ctx, e)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	b.Close(ctx)
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatalf("Expected publish to return after close got timeout")
+	}
+}
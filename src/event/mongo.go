@@ -0,0 +1,173 @@
+package event
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	mongoBusRetryDelayMin = 1 * time.Second
+	mongoBusRetryDelayMax = 30 * time.Second
+)
+
+type mongoChangeEvent struct {
+	OperationType string `bson:"operationType"`
+	NS            struct {
+		Coll string `bson:"coll"`
+	} `bson:"ns"`
+	DocumentKey struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument bson.Raw            `bson:"fullDocument"`
+	ClusterTime  primitive.Timestamp `bson:"clusterTime"`
+}
+
+func newEventFromChange(raw bson.Raw) (*Event, error) {
+	var change mongoChangeEvent
+	if err := bson.Unmarshal(raw, &change); err != nil {
+		return nil, err
+	}
+
+	var operation Operation
+	switch change.OperationType {
+	case "insert":
+		operation = Operation_Insert
+	case "update", "replace":
+		operation = Operation_Update
+	default:
+		return nil, nil
+	}
+
+	// skip updates to documents deleted before lookup
+	if change.FullDocument == nil {
+		return nil, nil
+	}
+
+	createdAt := time.Now()
+	if change.ClusterTime.T != 0 {
+		createdAt = time.Unix(int64(change.ClusterTime.T), 0)
+	}
+
+	return &Event{
+		Collection: change.NS.Coll,
+		Operation:  operation,
+		ID:         change.DocumentKey.ID.Hex(),
+		Document:   change.FullDocument,
+		CreatedAt:  createdAt,
+	}, nil
+}
+
+type mongoBus struct {
+	local       Bus
+	db          *mongo.Database
+	collections []string
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+// NewMongoBus returns a bus that delivers writes to the given collections
+// from every instance using mongo change streams. Change streams require
+// mongo to run as a replica set.
+func NewMongoBus(db *mongo.Database, collections ...string) Bus {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &mongoBus{
+		local:       NewMemoryBus(),
+		db:          db,
+		collections: collections,
+		cancel:      cancel,
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.watch(ctx)
+	}()
+
+	return b
+}
+
+// Publish does nothing as writes are picked up by the change stream
+func (b *mongoBus) Publish(ctx context.Context, e Event) error {
+	return nil
+}
+
+func (b *mongoBus) Subscribe(ctx context.Context) <-chan Event {
+	return b.local.Subscribe(ctx)
+}
+
+func (b *mongoBus) Close(ctx context.Context) error {
+	b.cancel()
+	b.wg.Wait()
+	return b.local.Close(ctx)
+}
+
+func (b *mongoBus) watch(ctx context.Context) {
+	var resumeToken bson.Raw
+	delay := mongoBusRetryDelayMin
+
+	for {
+		err := b.watchOnce(ctx, &resumeToken, func() {
+			delay = mongoBusRetryDelayMin
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Error("error watching mongo change stream", "error", err)
+
+		// retry with backoff
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > mongoBusRetryDelayMax {
+			delay = mongoBusRetryDelayMax
+		}
+	}
+}
+
+func (b *mongoBus) watchOnce(ctx context.Context, resumeToken *bson.Raw, onChange func()) error {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "ns.coll", Value: bson.D{{Key: "$in", Value: b.collections}}},
+			{Key: "operationType", Value: bson.D{{Key: "$in", Value: bson.A{"insert", "update", "replace"}}}},
+		}}},
+	}
+
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if *resumeToken != nil {
+		opts.SetResumeAfter(*resumeToken)
+	}
+
+	stream, err := b.db.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		*resumeToken = stream.ResumeToken()
+		onChange()
+
+		e, err := newEventFromChange(stream.Current)
+		if err != nil {
+			slog.Error("error decoding mongo change event", "error", err)
+			continue
+		}
+		if e != nil {
+			b.local.Publish(ctx, *e)
+		}
+	}
+
+	return stream.Err()
+}
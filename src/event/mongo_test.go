@@ -0,0 +1,64 @@
+package event
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewEventFromChange(t *testing.T) {
+	t.Parallel()
+	o := primitive.NewObjectID()
+	raw, _ := bson.Marshal(bson.D{
+		{Key: "operationType", Value: "update"},
+		{Key: "ns", Value: bson.D{{Key: "db", Value: "db"}, {Key: "coll", Value: "participant"}}},
+		{Key: "documentKey", Value: bson.D{{Key: "_id", Value: o}}},
+		{Key: "fullDocument", Value: bson.D{{Key: "_id", Value: o}, {Key: "status", Value: "admitted"}}},
+		{Key: "clusterTime", Value: primitive.Timestamp{T: 1656633600, I: 1}},
+	})
+
+	e, err := newEventFromChange(raw)
+	if err != nil {
+		t.Fatalf("Error decoding change: %#v", err)
+	}
+	if e == nil {
+		t.Fatalf("Expected event got nil")
+	}
+	if e.Collection != "participant" {
+		t.Fatalf("Unexpected collection: %#v", e.Collection)
+	}
+	if e.Operation != Operation_Update {
+		t.Fatalf("Unexpected operation: %#v", e.Operation)
+	}
+	if e.ID != o.Hex() {
+		t.Fatalf("Unexpected id: %#v", e.ID)
+	}
+
+	var doc struct {
+		Status string `bson:"status"`
+	}
+	if err := e.Decode(&doc); err != nil {
+		t.Fatalf("Error decoding document: %#v", err)
+	}
+	if doc.Status != "admitted" {
+		t.Fatalf("Unexpected document: %#v", doc)
+	}
+}
+
+func TestNewEventFromChangeDelete(t *testing.T) {
+	t.Parallel()
+	raw, _ := bson.Marshal(bson.D{
+		{Key: "operationType", Value: "delete"},
+		{Key: "ns", Value: bson.D{{Key: "db", Value: "db"}, {Key: "coll", Value: "participant"}}},
+		{Key: "documentKey", Value: bson.D{{Key: "_id", Value: primitive.NewObjectID()}}},
+	})
+
+	e, err := newEventFromChange(raw)
+	if err != nil {
+		t.Fatalf("Error decoding change: %#v", err)
+	}
+	if e != nil {
+		t.Fatalf("Expected nil event got %#v", e)
+	}
+}
@@ -2,20 +2,116 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // embed time zones for recurring meetings
 
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/metrics"
 	"github.com/aravindanve/livemeet-server/src/provider"
+	"github.com/aravindanve/livemeet-server/src/resource"
 	"github.com/aravindanve/livemeet-server/src/route"
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"github.com/aravindanve/livemeet-server/src/util"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/urfave/negroni"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const (
+	startupTimeout           = time.Minute
+	certificateWatchInterval = time.Minute
 )
 
 func main() {
-	// init context
-	ctx := context.Background()
+	if err := run(); err != nil {
+		slog.Error("error running server", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	// init context, done on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// load config from flags, env, secrets and config file
+	conf, err := config.Load(os.Args[1:])
+	if err != nil {
+		return fmt.Errorf("invalid config:\n%w", err)
+	}
 
 	// init provider
-	p := provider.NewProvider(ctx)
+	p := provider.NewProviderWithConfig(ctx, conf)
+	cf := p.HttpConfig()
+
+	// init json logger, also used by the log package, the level is swapped on
+	// reload
+	level := new(slog.LevelVar)
+	level.Set(p.LogConfig().Level)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: level,
+	})))
+
+	// reload config on SIGHUP, file changes and secret refresh until shutdown
+	go p.Watch(ctx, func(err error) {
+		if err != nil {
+			slog.Error("error reloading config", "error", err)
+			return
+		}
+		level.Set(p.LogConfig().Level)
+		slog.Info("config reloaded")
+	})
+
+	// init tls before starting tracing and the dispatcher, fails on invalid
+	// certificates
+	tlsConfig, redirectHandler, err := newTLSConfig(ctx, p)
+	if err != nil {
+		release(p, cf.ShutdownTimeout)
+		return err
+	}
+
+	// init tracing
+	shutdownTracing, err := tracing.Init(ctx, p.TracingConfig())
+	if err != nil {
+		release(p, cf.ShutdownTimeout)
+		return err
+	}
+
+	// create indexes before serving
+	sctx, cancel := context.WithTimeout(ctx, startupTimeout)
+	err = p.EnsureIndexes(sctx)
+	cancel()
+	if err != nil {
+		release(p, cf.ShutdownTimeout)
+		return err
+	}
+
+	// init webhook dispatcher, stops when the event bus is closed
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		resource.NewWebhookDispatcher(p).Run(context.Background())
+	}()
+
+	// register metrics read from the database
+	prometheus.MustRegister(metrics.NewGaugeCollector(
+		"participants_waiting", "Waiting participants of all meetings.",
+		func(ctx context.Context) (float64, error) {
+			n, err := p.ParticipantCollection().CountWaiting(ctx)
+			return float64(n), err
+		},
+	))
 
 	// init router
 	r := mux.NewRouter()
@@ -23,16 +119,192 @@ func main() {
 	// register routes and middleware
 	route.RegisterRoutes(r, p)
 
-	// init logger
-	l := negroni.NewLogger()
-	l.SetFormat(`{{.Status}} {{.Method}} {{.Request.RequestURI}} - {{.Duration}}`)
+	// init recovery
+	rec := negroni.NewRecovery()
+	rec.Logger = slog.NewLogLogger(slog.Default().Handler(), slog.LevelError)
 
 	// init negroni
-	n := negroni.New(negroni.NewRecovery(), l)
+	n := negroni.New(rec)
 	n.UseHandler(r)
 
+	// init server
+	srv := &http.Server{
+		Addr:              cf.Addr,
+		Handler:           n,
+		ReadHeaderTimeout: cf.ReadHeaderTimeout,
+		ReadTimeout:       cf.ReadTimeout,
+		WriteTimeout:      cf.WriteTimeout,
+		IdleTimeout:       cf.IdleTimeout,
+		TLSConfig:         tlsConfig,
+	}
+
+	// init redirect server for acme challenges and http to https redirects
+	var redirectSrv *http.Server
+	if redirectHandler != nil {
+		redirectSrv = &http.Server{
+			Addr:              p.TLSConfig().RedirectAddr,
+			Handler:           redirectHandler,
+			ReadHeaderTimeout: cf.ReadHeaderTimeout,
+			ReadTimeout:       cf.ReadTimeout,
+			WriteTimeout:      cf.WriteTimeout,
+			IdleTimeout:       cf.IdleTimeout,
+		}
+	}
+
+	// init metrics server on the internal address, not exposed with the api
+	var metricsSrv *http.Server
+	if cf.MetricsAddr != "" {
+		metricsSrv = &http.Server{
+			Addr:              cf.MetricsAddr,
+			Handler:           route.RegisterMetricsRoutes(mux.NewRouter()),
+			ReadHeaderTimeout: cf.ReadHeaderTimeout,
+			ReadTimeout:       cf.ReadTimeout,
+			WriteTimeout:      cf.WriteTimeout,
+			IdleTimeout:       cf.IdleTimeout,
+		}
+	}
+
 	// listen
-	addr := p.HttpConfig().Addr
-	l.Printf("HTTP Server listening on %s\n", addr)
-	http.ListenAndServe(addr, n)
+	serveErr := make(chan error, 3)
+	go func() {
+		slog.Info("http server listening", "addr", cf.Addr, "tls", p.TLSConfig().Mode)
+		if tlsConfig != nil {
+			serveErr <- srv.ListenAndServeTLS("", "")
+		} else {
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+	if metricsSrv != nil {
+		go func() {
+			slog.Info("http metrics server listening", "addr", metricsSrv.Addr)
+			serveErr <- metricsSrv.ListenAndServe()
+		}()
+	}
+	if redirectSrv != nil {
+		go func() {
+			slog.Info("http redirect server listening", "addr", redirectSrv.Addr)
+			serveErr <- redirectSrv.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		slog.Info("http server shutting down")
+	}
+
+	// stop listening for signals, a second signal terminates immediately
+	stop()
+
+	// drain requests
+	sctx, cancel = context.WithTimeout(context.Background(), cf.ShutdownTimeout)
+	defer cancel()
+	if serr := srv.Shutdown(sctx); serr != nil {
+		slog.Error("error shutting down http server", "error", serr)
+	}
+	if metricsSrv != nil {
+		if serr := metricsSrv.Shutdown(sctx); serr != nil {
+			slog.Error("error shutting down http metrics server", "error", serr)
+		}
+	}
+	if redirectSrv != nil {
+		if serr := redirectSrv.Shutdown(sctx); serr != nil {
+			slog.Error("error shutting down http redirect server", "error", serr)
+		}
+	}
+
+	// wait for webhook dispatches in progress
+	if cerr := p.EventBus().Close(sctx); cerr != nil {
+		slog.Error("error closing event bus", "error", cerr)
+	}
+	select {
+	case <-dispatcherDone:
+	case <-sctx.Done():
+		slog.Error("error stopping webhook dispatcher", "error", sctx.Err())
+	}
+
+	// release provider
+	if rerr := p.Release(sctx); rerr != nil {
+		slog.Error("error releasing provider", "error", rerr)
+	}
+
+	// flush pending spans
+	if terr := shutdownTracing(sctx); terr != nil {
+		slog.Error("error shutting down tracing", "error", terr)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// releases the provider after a failed startup
+func release(p provider.Provider, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := p.Release(ctx); err != nil {
+		slog.Error("error releasing provider", "error", err)
+	}
+}
+
+// returns the tls config of the server and the handler of the redirect
+// server, both nil when tls is off, certificate files are watched until ctx
+// is done
+func newTLSConfig(ctx context.Context, p provider.Provider) (*tls.Config, http.Handler, error) {
+	cf := p.TLSConfig()
+
+	var redirectHandler http.Handler
+	switch cf.Mode {
+	case config.TLSMode_File:
+		certs, err := util.NewCertificateFiles(cf.CertFile, cf.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		go certs.Watch(ctx, certificateWatchInterval, func(err error) {
+			if err != nil {
+				slog.Error("error reloading tls certificate", "error", err)
+				return
+			}
+			slog.Info("tls certificate reloaded")
+		})
+		if cf.RedirectAddr != "" {
+			redirectHandler = http.HandlerFunc(redirectHTTPS)
+		}
+		return &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}, redirectHandler, nil
+
+	case config.TLSMode_ACME:
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      p.CertCache(),
+			HostPolicy: autocert.HostWhitelist(cf.ACMEDomains...),
+			Email:      cf.ACMEEmail,
+		}
+		if cf.ACMEDirectoryURL != "" {
+			m.Client = &acme.Client{DirectoryURL: cf.ACMEDirectoryURL}
+		}
+		if cf.RedirectAddr != "" {
+			// http-01 challenges, tls-alpn-01 challenges are served by the
+			// tls config
+			redirectHandler = m.HTTPHandler(nil)
+		}
+		tlsConfig := m.TLSConfig()
+		tlsConfig.MinVersion = tls.VersionTLS12
+		return tlsConfig, redirectHandler, nil
+
+	default:
+		return nil, nil, nil
+	}
+}
+
+// redirects to https on the default port
+func redirectHTTPS(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
 }
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const gaugeTimeout = 5 * time.Second
+
+type gaugeCollector struct {
+	desc  *prometheus.Desc
+	value func(ctx context.Context) (float64, error)
+}
+
+// NewGaugeCollector returns a gauge that is read with value on every scrape,
+// for values kept in the database. The gauge is left out of a scrape if value
+// fails.
+func NewGaugeCollector(name, help string, value func(ctx context.Context) (float64, error)) prometheus.Collector {
+	return &gaugeCollector{
+		desc:  prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, nil, nil),
+		value: value,
+	}
+}
+
+func (c *gaugeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *gaugeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), gaugeTimeout)
+	defer cancel()
+
+	v, err := c.value(ctx)
+	if err != nil {
+		slog.Error("error collecting metric", "metric", c.desc.String(), "error", err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, v)
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGaugeCollector(t *testing.T) {
+	t.Parallel()
+
+	c := NewGaugeCollector("test_gauge", "Test gauge.", func(ctx context.Context) (float64, error) {
+		return 3, nil
+	})
+	expected := `
+# HELP livemeet_test_gauge Test gauge.
+# TYPE livemeet_test_gauge gauge
+livemeet_test_gauge 3
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+}
+
+func TestGaugeCollectorError(t *testing.T) {
+	t.Parallel()
+
+	c := NewGaugeCollector("test_gauge_error", "Test gauge.", func(ctx context.Context) (float64, error) {
+		return 0, errors.New("unavailable")
+	})
+	if n := testutil.CollectAndCount(c); n != 0 {
+		t.Errorf("expected no metrics got %d", n)
+		return
+	}
+}
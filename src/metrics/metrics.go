@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "livemeet"
+
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	MongoCommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_command_duration_seconds",
+		Help:      "Mongo command latency by collection and command.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"collection", "command"})
+
+	MongoCommandErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mongo_command_errors_total",
+		Help:      "Failed mongo commands by collection and command.",
+	}, []string{"collection", "command"})
+
+	LiveKitRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "livekit_request_duration_seconds",
+		Help:      "LiveKit API latency by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	LiveKitRequestErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "livekit_request_errors_total",
+		Help:      "Failed LiveKit API requests by method.",
+	}, []string{"method"})
+
+	MeetingsCreatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "meetings_created_total",
+		Help:      "Meetings created.",
+	})
+
+	ParticipantAdmissionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "participant_admissions_total",
+		Help:      "Participants admitted or denied by status.",
+	}, []string{"status"})
+
+	EventsDroppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_dropped_total",
+		Help:      "Events not delivered to a subscriber before the publish context was done.",
+	})
+)
+
+// ObserveLiveKitRequest records the latency and error of a LiveKit API request
+// started at start
+func ObserveLiveKitRequest(method string, start time.Time, err error) {
+	LiveKitRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		LiveKitRequestErrorsTotal.WithLabelValues(method).Inc()
+	}
+}
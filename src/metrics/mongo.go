@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+type mongoCommand struct {
+	collection string
+	command    string
+}
+
+// NewMongoMonitor returns a command monitor that records command latency per
+// collection
+func NewMongoMonitor() *event.CommandMonitor {
+	started := sync.Map{} // request id to mongoCommand
+
+	finished := func(requestID int64, d time.Duration, failed bool) {
+		v, ok := started.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		c := v.(mongoCommand)
+		MongoCommandDuration.WithLabelValues(c.collection, c.command).Observe(d.Seconds())
+		if failed {
+			MongoCommandErrorsTotal.WithLabelValues(c.collection, c.command).Inc()
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			// the value of the first element is the collection for
+			// collection commands except getMore
+			collection := ""
+			if e.CommandName == "getMore" {
+				collection, _ = e.Command.Lookup("collection").StringValueOK()
+			} else if el, err := e.Command.IndexErr(0); err == nil {
+				collection, _ = el.Value().StringValueOK()
+			}
+			started.Store(e.RequestID, mongoCommand{collection: collection, command: e.CommandName})
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			finished(e.RequestID, time.Duration(e.DurationNanos), false)
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			finished(e.RequestID, time.Duration(e.DurationNanos), true)
+		},
+	}
+}
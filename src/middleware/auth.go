@@ -63,10 +63,11 @@ func (a *authContext) Token() (*AuthToken, error) {
 		signed := s[1]
 
 		// parse and verify jwt
-		token, err := jwt.Parse(
-			[]byte(signed),
-			jwt.WithKey(a.config.Algorithm, a.config.Secret),
-		)
+		var opts []jwt.ParseOption
+		for _, secret := range a.config.Secrets() {
+			opts = append(opts, jwt.WithKey(a.config.Algorithm, secret))
+		}
+		token, err := jwt.Parse([]byte(signed), opts...)
 		if err != nil {
 			a.err = err
 			break
@@ -117,6 +118,11 @@ func AuthMiddleware(ds AuthMiddlewareDeps) mux.MiddlewareFunc {
 			a := NewAuthContext(ds.AuthConfig(), r.Header.Get("authorization"))
 			n := r.WithContext(context.WithValue(r.Context(), authContextKey{}, a))
 
+			// add user to request log, the token is not verified again
+			if token, _ := a.Token(); token != nil {
+				AddLogAttrs(n.Context(), "userId", token.UserID)
+			}
+
 			handler.ServeHTTP(w, n)
 		})
 	}
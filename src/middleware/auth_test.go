@@ -12,7 +12,7 @@ import (
 
 func TestAuthMiddleware(t *testing.T) {
 	t.Parallel()
-	p := config.NewAuthConfigProvider()
+	p := config.NewConfig()
 	cf := p.AuthConfig()
 
 	// create the token
@@ -60,7 +60,7 @@ func TestAuthMiddleware(t *testing.T) {
 
 func TestAuthMiddlewareBadToken(t *testing.T) {
 	t.Parallel()
-	p := config.NewAuthConfigProvider()
+	p := config.NewConfig()
 	cf := p.AuthConfig()
 
 	// create the token
@@ -96,7 +96,7 @@ func TestAuthMiddlewareBadToken(t *testing.T) {
 
 func TestGetAuthTokenParsed(t *testing.T) {
 	t.Parallel()
-	p := config.NewAuthConfigProvider()
+	p := config.NewConfig()
 	cf := p.AuthConfig()
 
 	// create request
@@ -2,27 +2,144 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/util"
 	"github.com/gorilla/mux"
 )
 
-func CORSMiddleware() mux.MiddlewareFunc {
+// CORSPolicy overrides the cors config for requests under PathPrefix, empty
+// fields fall back to the config
+type CORSPolicy struct {
+	PathPrefix     string
+	Disabled       bool // rejects cross origin requests
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	MaxAge         time.Duration
+}
+
+func (p CORSPolicy) matches(path string) bool {
+	prefix := strings.TrimRight(p.PathPrefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// returns the config for path with the first matching policy applied
+func getCORSConfig(cf config.CORSConfig, path string, policies []CORSPolicy) config.CORSConfig {
+	for _, p := range policies {
+		if !p.matches(path) {
+			continue
+		}
+		if p.Disabled {
+			cf.AllowedOrigins = nil
+		} else if p.AllowedOrigins != nil {
+			cf.AllowedOrigins = p.AllowedOrigins
+		}
+		if p.AllowedMethods != nil {
+			cf.AllowedMethods = p.AllowedMethods
+		}
+		if p.AllowedHeaders != nil {
+			cf.AllowedHeaders = p.AllowedHeaders
+		}
+		if p.ExposedHeaders != nil {
+			cf.ExposedHeaders = p.ExposedHeaders
+		}
+		if p.MaxAge != 0 {
+			cf.MaxAge = p.MaxAge
+		}
+		break
+	}
+	return cf
+}
+
+// returns the value of access-control-allow-origin for origin, wildcard
+// subdomains match any depth of subdomain but not the domain itself
+func matchCORSOrigin(allowed []string, origin string) (string, bool) {
+	for _, a := range allowed {
+		if a == "*" {
+			return "*", true
+		}
+		if strings.EqualFold(a, origin) {
+			return origin, true
+		}
+		if prefix, suffix, ok := strings.Cut(a, "://*."); ok {
+			scheme, host, ok := strings.Cut(origin, "://")
+			if ok && strings.EqualFold(scheme, prefix) && strings.HasSuffix(strings.ToLower(host), "."+strings.ToLower(suffix)) {
+				return origin, true
+			}
+		}
+	}
+	return "", false
+}
+
+func containsFold(values []string, v string) bool {
+	for _, s := range values {
+		if s == "*" || strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORSMiddleware allows cross origin requests from the origins allowed by
+// config, preflight requests from other origins or for methods or headers
+// that are not allowed are rejected, other requests are handled without cors
+// headers so browsers do not expose the response
+func CORSMiddleware(ds config.CORSConfigProvider, policies ...CORSPolicy) mux.MiddlewareFunc {
 	return func(handler http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if h := r.Header.Get("origin"); h != "" {
-				w.Header().Set("vary", "Origin")
-				w.Header().Set("access-control-allow-origin", h)
+			origin := r.Header.Get("origin")
+			if origin == "" {
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("vary", "Origin")
+			cf := getCORSConfig(ds.CORSConfig(), r.URL.Path, policies)
+			preflight := r.Method == http.MethodOptions
+
+			allowOrigin, ok := matchCORSOrigin(cf.AllowedOrigins, origin)
+			if !ok {
+				if preflight {
+					util.WriteJSONError(w, http.StatusForbidden, "Origin not allowed")
+					return
+				}
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("access-control-allow-origin", allowOrigin)
+			if cf.AllowCredentials && allowOrigin != "*" {
 				w.Header().Set("access-control-allow-credentials", "true")
+			}
 
+			if preflight {
+				if m := r.Header.Get("access-control-request-method"); m != "" && !containsFold(cf.AllowedMethods, m) {
+					util.WriteJSONError(w, http.StatusForbidden, "Method not allowed")
+					return
+				}
 				if h := r.Header.Get("access-control-request-headers"); h != "" {
+					for _, name := range strings.Split(h, ",") {
+						if name = strings.TrimSpace(name); name != "" && !containsFold(cf.AllowedHeaders, name) {
+							util.WriteJSONError(w, http.StatusForbidden, "Header not allowed")
+							return
+						}
+					}
 					w.Header().Set("access-control-allow-headers", h)
 				}
 
-				if m := r.Method; m == http.MethodOptions {
-					w.Header().Set("access-control-allow-methods", "GET,HEAD,PUT,POST,DELETE,PATCH")
-					w.WriteHeader(http.StatusNoContent)
-					return
-				}
+				w.Header().Set("access-control-allow-methods", strings.Join(cf.AllowedMethods, ","))
+				w.Header().Set("access-control-max-age", strconv.Itoa(int(cf.MaxAge.Seconds())))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if len(cf.ExposedHeaders) > 0 {
+				w.Header().Set("access-control-expose-headers", strings.Join(cf.ExposedHeaders, ","))
 			}
 
 			handler.ServeHTTP(w, r)
@@ -4,8 +4,39 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
 )
 
+type mockCORSConfigProvider struct {
+	corsConfig config.CORSConfig
+}
+
+func (m *mockCORSConfigProvider) CORSConfig() config.CORSConfig {
+	return m.corsConfig
+}
+
+func newMockCORSConfigProvider() *mockCORSConfigProvider {
+	return &mockCORSConfigProvider{config.CORSConfig{
+		AllowedOrigins:   []string{"https://example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "HEAD", "PUT", "POST", "DELETE", "PATCH"},
+		AllowedHeaders:   []string{"authorization", "content-type"},
+		ExposedHeaders:   []string{"x-request-id"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}}
+}
+
+// serves r with the cors middleware in front of a handler responding 404
+func serveCORS(m *mockCORSConfigProvider, r *http.Request, policies ...CORSPolicy) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	CORSMiddleware(m, policies...).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})).ServeHTTP(w, r)
+	return w
+}
+
 func TestCORSMiddlewareWithPreflightRequest(t *testing.T) {
 	t.Parallel()
 
@@ -17,7 +48,7 @@ func TestCORSMiddlewareWithPreflightRequest(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// create middleware
-	m := CORSMiddleware()
+	m := CORSMiddleware(newMockCORSConfigProvider())
 	m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
 	})).ServeHTTP(w, r)
@@ -46,6 +77,10 @@ func TestCORSMiddlewareWithPreflightRequest(t *testing.T) {
 		t.Errorf(`expected access-control-allow-methods to be "GET,HEAD,PUT,POST,DELETE,PATCH" got %q`, h)
 		return
 	}
+	if h := w.Header().Get("access-control-max-age"); h != "600" {
+		t.Errorf(`expected access-control-max-age to be "600" got %q`, h)
+		return
+	}
 }
 
 func TestCORSMiddlewareWithCORSRequest(t *testing.T) {
@@ -59,7 +94,7 @@ func TestCORSMiddlewareWithCORSRequest(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// create middleware
-	m := CORSMiddleware()
+	m := CORSMiddleware(newMockCORSConfigProvider())
 	m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
 	})).ServeHTTP(w, r)
@@ -88,6 +123,10 @@ func TestCORSMiddlewareWithCORSRequest(t *testing.T) {
 		t.Errorf(`expected access-control-allow-methods to be "" got %q`, h)
 		return
 	}
+	if h := w.Header().Get("access-control-expose-headers"); h != "x-request-id" {
+		t.Errorf(`expected access-control-expose-headers to be "x-request-id" got %q`, h)
+		return
+	}
 }
 
 func TestCORSMiddlewareWithSameOriginRequest(t *testing.T) {
@@ -100,7 +139,7 @@ func TestCORSMiddlewareWithSameOriginRequest(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// create middleware
-	m := CORSMiddleware()
+	m := CORSMiddleware(newMockCORSConfigProvider())
 	m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
 	})).ServeHTTP(w, r)
@@ -130,3 +169,161 @@ func TestCORSMiddlewareWithSameOriginRequest(t *testing.T) {
 		return
 	}
 }
+
+func TestCORSMiddlewareWithWildcardSubdomain(t *testing.T) {
+	t.Parallel()
+	m := newMockCORSConfigProvider()
+
+	for _, origin := range []string{"https://app.example.org", "https://a.b.example.org"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("origin", origin)
+		w := serveCORS(m, r)
+		if h := w.Header().Get("access-control-allow-origin"); h != origin {
+			t.Errorf(`expected access-control-allow-origin to be %q got %q`, origin, h)
+		}
+	}
+
+	// the domain itself, other schemes and ports are not subdomains
+	for _, origin := range []string{"https://example.org", "http://app.example.org", "https://app.example.org:8443", "https://appexample.org"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("origin", origin)
+		w := serveCORS(m, r)
+		if h := w.Header().Get("access-control-allow-origin"); h != "" {
+			t.Errorf(`expected access-control-allow-origin for %s to be "" got %q`, origin, h)
+		}
+	}
+}
+
+func TestCORSMiddlewareRejectsOrigin(t *testing.T) {
+	t.Parallel()
+	m := newMockCORSConfigProvider()
+
+	// preflight requests are rejected
+	r := httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set("origin", "https://evil.com")
+	r.Header.Set("access-control-request-method", http.MethodPost)
+	w := serveCORS(m, r)
+	if s := w.Result().StatusCode; s != http.StatusForbidden {
+		t.Errorf("expected status to be %#v got %#v", http.StatusForbidden, s)
+	}
+	if h := w.Header().Get("access-control-allow-origin"); h != "" {
+		t.Errorf(`expected access-control-allow-origin to be "" got %q`, h)
+	}
+
+	// other requests are handled without cors headers
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("origin", "https://evil.com")
+	w = serveCORS(m, r)
+	if s := w.Result().StatusCode; s != http.StatusNotFound {
+		t.Errorf("expected status to be %#v got %#v", http.StatusNotFound, s)
+	}
+	if h := w.Header().Get("access-control-allow-origin"); h != "" {
+		t.Errorf(`expected access-control-allow-origin to be "" got %q`, h)
+	}
+	if h := w.Header().Get("access-control-allow-credentials"); h != "" {
+		t.Errorf(`expected access-control-allow-credentials to be "" got %q`, h)
+	}
+	if h := w.Header().Get("vary"); h != "Origin" {
+		t.Errorf(`expected vary to be "Origin" got %q`, h)
+	}
+}
+
+func TestCORSMiddlewareRejectsMethodAndHeaders(t *testing.T) {
+	t.Parallel()
+	m := newMockCORSConfigProvider()
+
+	r := httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set("origin", "https://example.com")
+	r.Header.Set("access-control-request-method", "TRACE")
+	if s := serveCORS(m, r).Result().StatusCode; s != http.StatusForbidden {
+		t.Errorf("expected status for method to be %#v got %#v", http.StatusForbidden, s)
+	}
+
+	r = httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set("origin", "https://example.com")
+	r.Header.Set("access-control-request-method", http.MethodPost)
+	r.Header.Set("access-control-request-headers", "content-type, x-custom")
+	if s := serveCORS(m, r).Result().StatusCode; s != http.StatusForbidden {
+		t.Errorf("expected status for headers to be %#v got %#v", http.StatusForbidden, s)
+	}
+
+	r = httptest.NewRequest(http.MethodOptions, "/", nil)
+	r.Header.Set("origin", "https://example.com")
+	r.Header.Set("access-control-request-method", http.MethodPost)
+	r.Header.Set("access-control-request-headers", "Content-Type, Authorization")
+	w := serveCORS(m, r)
+	if s := w.Result().StatusCode; s != http.StatusNoContent {
+		t.Errorf("expected status for allowed headers to be %#v got %#v", http.StatusNoContent, s)
+	}
+	if h := w.Header().Get("access-control-allow-headers"); h != "Content-Type, Authorization" {
+		t.Errorf(`expected access-control-allow-headers to be "Content-Type, Authorization" got %q`, h)
+	}
+}
+
+func TestCORSMiddlewareWithAnyOrigin(t *testing.T) {
+	t.Parallel()
+	m := newMockCORSConfigProvider()
+	m.corsConfig.AllowedOrigins = []string{"*"}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("origin", "https://other.com")
+	w := serveCORS(m, r)
+	if h := w.Header().Get("access-control-allow-origin"); h != "*" {
+		t.Errorf(`expected access-control-allow-origin to be "*" got %q`, h)
+	}
+
+	// credentials are never allowed for any origin
+	if h := w.Header().Get("access-control-allow-credentials"); h != "" {
+		t.Errorf(`expected access-control-allow-credentials to be "" got %q`, h)
+	}
+}
+
+func TestCORSMiddlewareWithPolicy(t *testing.T) {
+	t.Parallel()
+	m := newMockCORSConfigProvider()
+	policies := []CORSPolicy{{
+		PathPrefix: "/metrics",
+		Disabled:   true,
+	}, {
+		PathPrefix:     "/public",
+		AllowedOrigins: []string{"https://other.com"},
+		AllowedMethods: []string{"GET"},
+		MaxAge:         time.Hour,
+	}}
+
+	// disabled routes reject allowed origins
+	r := httptest.NewRequest(http.MethodOptions, "/metrics", nil)
+	r.Header.Set("origin", "https://example.com")
+	if s := serveCORS(m, r, policies...).Result().StatusCode; s != http.StatusForbidden {
+		t.Errorf("expected status to be %#v got %#v", http.StatusForbidden, s)
+	}
+
+	// overrides replace the config
+	r = httptest.NewRequest(http.MethodOptions, "/public/meetings", nil)
+	r.Header.Set("origin", "https://other.com")
+	r.Header.Set("access-control-request-method", http.MethodGet)
+	w := serveCORS(m, r, policies...)
+	if s := w.Result().StatusCode; s != http.StatusNoContent {
+		t.Errorf("expected status to be %#v got %#v", http.StatusNoContent, s)
+	}
+	if h := w.Header().Get("access-control-allow-methods"); h != "GET" {
+		t.Errorf(`expected access-control-allow-methods to be "GET" got %q`, h)
+	}
+	if h := w.Header().Get("access-control-max-age"); h != "3600" {
+		t.Errorf(`expected access-control-max-age to be "3600" got %q`, h)
+	}
+
+	r = httptest.NewRequest(http.MethodOptions, "/public", nil)
+	r.Header.Set("origin", "https://other.com")
+	r.Header.Set("access-control-request-method", http.MethodPost)
+	if s := serveCORS(m, r, policies...).Result().StatusCode; s != http.StatusForbidden {
+		t.Errorf("expected status to be %#v got %#v", http.StatusForbidden, s)
+	}
+
+	// other routes use the config
+	r = httptest.NewRequest(http.MethodOptions, "/publication", nil)
+	r.Header.Set("origin", "https://other.com")
+	if s := serveCORS(m, r, policies...).Result().StatusCode; s != http.StatusForbidden {
+		t.Errorf("expected status to be %#v got %#v", http.StatusForbidden, s)
+	}
+}
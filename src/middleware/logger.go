@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	RequestIDHeader = "x-request-id"
+)
+
+// accepted request ids from clients or proxies
+var requestIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type requestLog struct {
+	mut    sync.Mutex
+	logger *slog.Logger
+}
+
+type requestLogKey struct{}
+
+// Logger returns the logger of the request with the request id, route and
+// other request attributes, or the default logger outside of requests
+func Logger(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		l.mut.Lock()
+		defer l.mut.Unlock()
+		return l.logger
+	}
+	return slog.Default()
+}
+
+// AddLogAttrs adds attributes to the logger of the request, they are included
+// in later logs and the request log
+func AddLogAttrs(ctx context.Context, attrs ...any) {
+	if l, ok := ctx.Value(requestLogKey{}).(*requestLog); ok {
+		l.mut.Lock()
+		defer l.mut.Unlock()
+		l.logger = l.logger.With(attrs...)
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// LoggerMiddleware accepts or generates a request id, returns it in the
+// x-request-id header and logs every request as json
+func LoggerMiddleware() mux.MiddlewareFunc {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// get request id
+			requestID := r.Header.Get(RequestIDHeader)
+			if !requestIDRegexp.MatchString(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			// get route
+			route := "unknown"
+			if cr := mux.CurrentRoute(r); cr != nil {
+				if t, err := cr.GetPathTemplate(); err == nil {
+					route = t
+				}
+			}
+
+			attrs := []any{
+				slog.String("requestId", requestID),
+				slog.String("method", r.Method),
+				slog.String("route", route),
+			}
+			if meetingID := mux.Vars(r)["meetingId"]; meetingID != "" {
+				attrs = append(attrs, slog.String("meetingId", meetingID))
+			}
+			l := &requestLog{logger: slog.Default().With(attrs...)}
+			n := r.WithContext(context.WithValue(r.Context(), requestLogKey{}, l))
+
+			rec := &statusRecorder{ResponseWriter: w}
+			handler.ServeHTTP(rec, n)
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+
+			level := slog.LevelInfo
+			if rec.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			Logger(n.Context()).LogAttrs(n.Context(), level, "request",
+				slog.String("uri", r.RequestURI),
+				slog.Int("status", rec.status),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remoteIp", GetRemoteIP(r)),
+			)
+		})
+	}
+}
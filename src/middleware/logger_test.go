@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestLoggerMiddlewareRequestID(t *testing.T) {
+	t.Parallel()
+
+	// create router
+	r := mux.NewRouter()
+	r.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if Logger(r.Context()) == nil {
+			t.Errorf("expected request logger to be set got nil")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.Use(LoggerMiddleware())
+
+	for _, tc := range []struct {
+		header   string
+		expected string
+	}{
+		{header: "", expected: ""},
+		{header: "abc-123", expected: "abc-123"},
+		{header: "not valid\n", expected: ""},
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tc.header != "" {
+			req.Header.Set(RequestIDHeader, tc.header)
+		}
+		r.ServeHTTP(w, req)
+
+		h := w.Result().Header.Get(RequestIDHeader)
+		if tc.expected != "" && h != tc.expected {
+			t.Errorf("expected request id to be %q got %q", tc.expected, h)
+			return
+		}
+		if tc.expected == "" && len(h) != 32 {
+			t.Errorf("expected generated request id got %q", h)
+			return
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aravindanve/livemeet-server/src/metrics"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddlewareUsesRouteTemplate(t *testing.T) {
+	t.Parallel()
+
+	// create router
+	r := mux.NewRouter()
+	r.HandleFunc("/metricstest/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	r.Use(MetricsMiddleware())
+
+	for _, id := range []string{"a", "b"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metricstest/"+id, nil))
+	}
+
+	// test counter
+	c := metrics.HTTPRequestsTotal.WithLabelValues(http.MethodGet, "/metricstest/{id}", "418")
+	if v := testutil.ToFloat64(c); v != 2 {
+		t.Errorf("expected request count to be 2 got %v", v)
+		return
+	}
+}
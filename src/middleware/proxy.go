@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/gorilla/mux"
+)
+
+type trustedProxyKey struct{}
+
+// FromTrustedProxy returns whether the request was received from a trusted
+// proxy, whose headers such as traceparent may be trusted
+func FromTrustedProxy(r *http.Request) bool {
+	trusted, _ := r.Context().Value(trustedProxyKey{}).(bool)
+	return trusted
+}
+
+func isTrustedProxy(trusted []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the client address by walking x-forwarded-for from the nearest hop
+// while the hop before it is a trusted proxy, so that addresses added by
+// clients are ignored, and the number of hops walked
+func getForwardedAddr(trusted []netip.Prefix, remote netip.Addr, forwardedFor []string) (netip.Addr, int) {
+	hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+	addr, n := remote, 0
+	for i := len(hops) - 1; i >= 0 && isTrustedProxy(trusted, addr); i-- {
+		next, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr, n = next.Unmap(), n+1
+	}
+	return addr, n
+}
+
+// returns the scheme from x-forwarded-proto set by the proxy at the hop where
+// the x-forwarded-for walk stopped, proxies that do not append a value pass
+// on the value of the first proxy
+func getForwardedProto(forwardedProto []string, hops int) string {
+	protos := strings.Split(strings.Join(forwardedProto, ","), ",")
+	i := max(len(protos)-max(hops, 1), 0)
+	return strings.ToLower(strings.TrimSpace(protos[i]))
+}
+
+// IsHTTPS returns whether the client connected over https, directly or to a
+// trusted proxy
+func IsHTTPS(r *http.Request) bool {
+	return r.TLS != nil || r.URL.Scheme == "https"
+}
+
+// ProxyMiddleware sets the remote address and scheme of requests from trusted
+// proxies to those of the client from x-forwarded-for and x-forwarded-proto,
+// must be used before middleware reading the remote address such as logging
+// and rate limiting
+func ProxyMiddleware(ds config.ProxyConfigProvider) mux.MiddlewareFunc {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			trusted := ds.ProxyConfig().TrustedProxies
+			remote, err := netip.ParseAddr(GetRemoteIP(r))
+			if err != nil || !isTrustedProxy(trusted, remote.Unmap()) {
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			n := r.Clone(context.WithValue(r.Context(), trustedProxyKey{}, true))
+			var hops int
+			if forwardedFor := r.Header.Values("x-forwarded-for"); len(forwardedFor) > 0 {
+				var addr netip.Addr
+				addr, hops = getForwardedAddr(trusted, remote.Unmap(), forwardedFor)
+				n.RemoteAddr = net.JoinHostPort(addr.String(), "0")
+			}
+
+			// values before the hop of the client may be set by the client
+			if forwardedProto := r.Header.Values("x-forwarded-proto"); len(forwardedProto) > 0 {
+				if proto := getForwardedProto(forwardedProto, hops); proto == "http" || proto == "https" {
+					n.URL.Scheme = proto
+				}
+			}
+
+			handler.ServeHTTP(w, n)
+		})
+	}
+}
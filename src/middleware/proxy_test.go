@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+)
+
+type mockProxyConfigProvider struct {
+	proxyConfig config.ProxyConfig
+}
+
+func (m *mockProxyConfigProvider) ProxyConfig() config.ProxyConfig {
+	return m.proxyConfig
+}
+
+// serves r with the proxy middleware and returns the remote ip and https seen
+// by the handler
+func serveProxy(r *http.Request, trusted ...string) (string, bool) {
+	m := &mockProxyConfigProvider{}
+	for _, s := range trusted {
+		m.proxyConfig.TrustedProxies = append(m.proxyConfig.TrustedProxies, netip.MustParsePrefix(s))
+	}
+
+	var ip string
+	var https bool
+	ProxyMiddleware(m).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, https = GetRemoteIP(r), IsHTTPS(r)
+	})).ServeHTTP(httptest.NewRecorder(), r)
+	return ip, https
+}
+
+func TestProxyMiddlewareWithTrustedProxy(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.Header.Set("x-forwarded-for", "203.0.113.7, 10.0.0.1")
+	r.Header.Set("x-forwarded-proto", "https")
+
+	ip, https := serveProxy(r, "10.0.0.0/8")
+	if ip != "203.0.113.7" {
+		t.Errorf("expected remote ip to be 203.0.113.7 got %s", ip)
+	}
+	if !https {
+		t.Errorf("expected request to be https")
+	}
+	if r.RemoteAddr != "10.0.0.2:1234" {
+		t.Errorf("expected original request to be unchanged got %s", r.RemoteAddr)
+	}
+}
+
+func TestProxyMiddlewareWithUntrustedProxy(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "198.51.100.1:1234"
+	r.Header.Set("x-forwarded-for", "203.0.113.7")
+	r.Header.Set("x-forwarded-proto", "https")
+
+	ip, https := serveProxy(r, "10.0.0.0/8")
+	if ip != "198.51.100.1" {
+		t.Errorf("expected remote ip to be 198.51.100.1 got %s", ip)
+	}
+	if https {
+		t.Errorf("expected request not to be https")
+	}
+}
+
+func TestProxyMiddlewareWithSpoofedForwardedFor(t *testing.T) {
+	t.Parallel()
+
+	// the client prepends an address, only the hop added by the trusted proxy
+	// is used
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.Header.Add("x-forwarded-for", "127.0.0.1")
+	r.Header.Add("x-forwarded-for", "203.0.113.7")
+
+	if ip, _ := serveProxy(r, "10.0.0.0/8"); ip != "203.0.113.7" {
+		t.Errorf("expected remote ip to be 203.0.113.7 got %s", ip)
+	}
+
+	// invalid hops stop the walk at the last valid address
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.Header.Set("x-forwarded-for", "203.0.113.7, not-an-ip, 10.0.0.1")
+
+	if ip, _ := serveProxy(r, "10.0.0.0/8"); ip != "10.0.0.1" {
+		t.Errorf("expected remote ip to be 10.0.0.1 got %s", ip)
+	}
+}
+
+func TestProxyMiddlewareWithSpoofedForwardedProto(t *testing.T) {
+	t.Parallel()
+
+	// the client sends https over http, the trusted proxy appends http
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.Header.Set("x-forwarded-for", "203.0.113.7")
+	r.Header.Set("x-forwarded-proto", "https, http")
+
+	if _, https := serveProxy(r, "10.0.0.0/8"); https {
+		t.Errorf("expected request not to be https")
+	}
+
+	// the scheme is taken from the hop of the client
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.Header.Set("x-forwarded-for", "127.0.0.1, 203.0.113.7, 10.0.0.1")
+	r.Header.Set("x-forwarded-proto", "http, https, http")
+
+	if _, https := serveProxy(r, "10.0.0.0/8"); !https {
+		t.Errorf("expected request to be https")
+	}
+}
+
+func TestProxyMiddlewareWithoutTrustedProxies(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.2:1234"
+	r.Header.Set("x-forwarded-for", "203.0.113.7")
+
+	if ip, _ := serveProxy(r); ip != "10.0.0.2" {
+		t.Errorf("expected remote ip to be 10.0.0.2 got %s", ip)
+	}
+}
@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/util"
+	"github.com/gorilla/mux"
+	"github.com/jellydator/ttlcache/v3"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	RateLimitKey_IP           = RateLimitKey("ip")
+	RateLimitKey_User         = RateLimitKey("user")
+	rateLimitRetryAfterHeader = "retry-after"
+)
+
+// RateLimitKey defines what requests are counted together
+type RateLimitKey string
+
+// RateLimit allows bursts of Requests that refill evenly over Per
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// rate returns the refill rate in tokens per second
+func (l RateLimit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// RateLimitPolicy applies a limit to requests matching method and the mux
+// path template, empty method or path match any
+type RateLimitPolicy struct {
+	Name     string
+	Method   string
+	Path     string
+	By       RateLimitKey
+	Limit    RateLimit
+	Disabled bool // exempts matching requests from all policies
+}
+
+func (p RateLimitPolicy) matches(method, path string) bool {
+	return (p.Method == "" || p.Method == method) && (p.Path == "" || p.Path == path)
+}
+
+type RateLimitStoreProvider interface {
+	RateLimitStore() RateLimitStore
+}
+
+type RateLimitStore interface {
+	// Take removes a token from the bucket of key and returns whether it was
+	// allowed, and if not, the duration until a token becomes available
+	Take(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error)
+	// Reset refills the bucket of key
+	Reset(ctx context.Context, key string) error
+}
+
+type RateLimitMiddlewareDeps interface {
+	config.RateLimitConfigProvider
+	RateLimitStoreProvider
+}
+
+func NewRateLimitStore(cf config.RateLimitConfigProvider, db *mongo.Database) RateLimitStore {
+	switch cf.RateLimitConfig().Store {
+	case config.RateLimitStore_Mongo:
+		return NewMongoRateLimitStore(db)
+	default:
+		return NewMemoryRateLimitStore(cf.RateLimitConfig().MemoryCapacity)
+	}
+}
+
+// GetRemoteIP returns the ip address of the client
+func GetRemoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// returns the key identifying the client for a policy, falls back to ip
+// for anonymous requests when keyed by user
+func getRateLimitClientKey(r *http.Request, by RateLimitKey) string {
+	if by == RateLimitKey_User {
+		if token, err := GetAuthToken(r); err == nil && token != nil && token.UserID != "" {
+			return "user:" + token.UserID
+		}
+	}
+	return "ip:" + GetRemoteIP(r)
+}
+
+// RateLimitMiddleware limits requests by policy, must be used after the auth
+// middleware for policies keyed by user
+func RateLimitMiddleware(ds RateLimitMiddlewareDeps, policies ...RateLimitPolicy) mux.MiddlewareFunc {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cf := ds.RateLimitConfig()
+			if !cf.Enabled {
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			var path string
+			if route := mux.CurrentRoute(r); route != nil {
+				path, _ = route.GetPathTemplate()
+			}
+
+			for _, p := range policies {
+				if p.Disabled && p.matches(r.Method, path) {
+					handler.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			for _, p := range policies {
+				if p.Disabled || !p.matches(r.Method, path) {
+					continue
+				}
+
+				limit := p.Limit
+				if o, ok := cf.Overrides[p.Name]; ok {
+					limit = RateLimit{Requests: o.Requests, Per: o.Per}
+				}
+
+				ctx, cancel := context.WithTimeout(r.Context(), cf.StoreTimeout)
+				key := p.Name + ":" + getRateLimitClientKey(r, p.By)
+				allowed, retryAfter, err := ds.RateLimitStore().Take(ctx, key, limit)
+				cancel()
+
+				// fail open if the store is unavailable
+				if err != nil {
+					Logger(r.Context()).Warn("error taking rate limit token", "error", err)
+					continue
+				}
+				if !allowed {
+					seconds := int(math.Ceil(retryAfter.Seconds()))
+					if seconds < 1 {
+						seconds = 1
+					}
+					w.Header().Set(rateLimitRetryAfterHeader, strconv.Itoa(seconds))
+					util.WriteJSONError(w, http.StatusTooManyRequests, fmt.Sprintf("Too many requests, retry after %d seconds", seconds))
+					return
+				}
+			}
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+type rateLimitBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// takes a token after refilling the bucket and returns whether it was
+// allowed and the duration until a token becomes available
+func (b *rateLimitBucket) take(now time.Time, limit RateLimit) (bool, time.Duration) {
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	b.tokens = math.Min(float64(limit.Requests), b.tokens+elapsed*limit.rate())
+	b.updatedAt = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / limit.rate() * float64(time.Second))
+}
+
+type memoryRateLimitStore struct {
+	mut   sync.Mutex
+	cache *ttlcache.Cache[string, *rateLimitBucket]
+}
+
+// NewMemoryRateLimitStore returns a store that keeps buckets in memory, limits
+// apply per instance and at most capacity keys are tracked
+func NewMemoryRateLimitStore(capacity int) RateLimitStore {
+	return &memoryRateLimitStore{
+		cache: ttlcache.New(
+			ttlcache.WithCapacity[string, *rateLimitBucket](uint64(capacity)),
+			ttlcache.WithDisableTouchOnHit[string, *rateLimitBucket](),
+		),
+	}
+}
+
+func (s *memoryRateLimitStore) Take(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	now := time.Now()
+	var bucket *rateLimitBucket
+	if item := s.cache.Get(key); item != nil {
+		bucket = item.Value()
+	} else {
+		bucket = &rateLimitBucket{tokens: float64(limit.Requests), updatedAt: now}
+	}
+
+	allowed, retryAfter := bucket.take(now, limit)
+
+	// buckets are full again after limit.Per so they can be dropped
+	s.cache.Set(key, bucket, limit.Per)
+
+	return allowed, retryAfter, nil
+}
+
+func (s *memoryRateLimitStore) Reset(ctx context.Context, key string) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.cache.Delete(key)
+	return nil
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRateLimitBucket struct {
+	Key       string    `bson:"_id"`
+	Tokens    float64   `bson:"tokens"`
+	Allowed   bool      `bson:"allowed"`
+	UpdatedAt time.Time `bson:"updatedAt"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+type mongoRateLimitStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoRateLimitStore returns a store that keeps buckets in mongo, limits
+// apply across instances. Buckets are updated atomically with pipeline
+// updates which require mongo 4.2 or later.
+func NewMongoRateLimitStore(db *mongo.Database) RateLimitStore {
+	collection := db.Collection("rateLimit")
+
+	return &mongoRateLimitStore{collection: collection}
+}
+
+// EnsureIndexes creates the indexes of the collection
+func (s *mongoRateLimitStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+func (s *mongoRateLimitStore) Take(ctx context.Context, key string, limit RateLimit) (bool, time.Duration, error) {
+	capacity := float64(limit.Requests)
+	ratePerMilli := limit.rate() / 1000
+
+	// refill and take a token using the server clock so that all instances
+	// agree on elapsed time
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "tokens", Value: bson.D{{Key: "$min", Value: bson.A{
+				capacity,
+				bson.D{{Key: "$add", Value: bson.A{
+					bson.D{{Key: "$ifNull", Value: bson.A{"$tokens", capacity}}},
+					bson.D{{Key: "$multiply", Value: bson.A{
+						ratePerMilli,
+						bson.D{{Key: "$max", Value: bson.A{0, bson.D{{Key: "$subtract", Value: bson.A{
+							"$$NOW",
+							bson.D{{Key: "$ifNull", Value: bson.A{"$updatedAt", "$$NOW"}}},
+						}}}}}},
+					}}},
+				}}},
+			}}}},
+		}}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "allowed", Value: bson.D{{Key: "$gte", Value: bson.A{"$tokens", 1}}}},
+			{Key: "tokens", Value: bson.D{{Key: "$cond", Value: bson.A{
+				bson.D{{Key: "$gte", Value: bson.A{"$tokens", 1}}},
+				bson.D{{Key: "$subtract", Value: bson.A{"$tokens", 1}}},
+				"$tokens",
+			}}}},
+			{Key: "updatedAt", Value: "$$NOW"},
+			{Key: "expiresAt", Value: bson.D{{Key: "$add", Value: bson.A{"$$NOW", limit.Per.Milliseconds()}}}},
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var bucket mongoRateLimitBucket
+	err := s.collection.FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: key}}, pipeline, opts).Decode(&bucket)
+	if mongo.IsDuplicateKeyError(err) {
+		// retry once if a concurrent request inserted the bucket first
+		err = s.collection.FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: key}}, pipeline, opts).Decode(&bucket)
+	}
+	if err != nil {
+		return false, 0, err
+	}
+
+	if bucket.Allowed {
+		return true, 0, nil
+	}
+	return false, time.Duration((1 - bucket.Tokens) / limit.rate() * float64(time.Second)), nil
+}
+
+func (s *mongoRateLimitStore) Reset(ctx context.Context, key string) error {
+	_, err := s.collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: key}})
+	return err
+}
@@ -0,0 +1,214 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/gorilla/mux"
+)
+
+type mockRateLimitDeps struct {
+	config.RateLimitConfigProvider
+	store RateLimitStore
+}
+
+func (m *mockRateLimitDeps) RateLimitStore() RateLimitStore {
+	return m.store
+}
+
+func TestRateLimitBucketTake(t *testing.T) {
+	t.Parallel()
+	limit := RateLimit{Requests: 2, Per: 2 * time.Second}
+	now := time.Now()
+	b := &rateLimitBucket{tokens: 2, updatedAt: now}
+
+	for i := 0; i < 2; i++ {
+		if allowed, _ := b.take(now, limit); !allowed {
+			t.Fatalf("expected take %d to be allowed", i)
+		}
+	}
+
+	allowed, retryAfter := b.take(now, limit)
+	if allowed {
+		t.Fatalf("expected take to be limited")
+	}
+	if retryAfter != time.Second {
+		t.Fatalf("expected retry after to be %v got %v", time.Second, retryAfter)
+	}
+
+	// refills one token per second
+	if allowed, _ := b.take(now.Add(time.Second), limit); !allowed {
+		t.Fatalf("expected take after refill to be allowed")
+	}
+}
+
+func TestMemoryRateLimitStoreTake(t *testing.T) {
+	t.Parallel()
+	s := NewMemoryRateLimitStore(100)
+	limit := RateLimit{Requests: 1, Per: time.Minute}
+
+	if allowed, _, _ := s.Take(context.Background(), "a", limit); !allowed {
+		t.Fatalf("expected first take to be allowed")
+	}
+	if allowed, _, _ := s.Take(context.Background(), "a", limit); allowed {
+		t.Fatalf("expected second take to be limited")
+	}
+	if allowed, _, _ := s.Take(context.Background(), "b", limit); !allowed {
+		t.Fatalf("expected take for other key to be allowed")
+	}
+
+	if err := s.Reset(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if allowed, _, _ := s.Take(context.Background(), "a", limit); !allowed {
+		t.Fatalf("expected take after reset to be allowed")
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	t.Parallel()
+	ds := &mockRateLimitDeps{
+		RateLimitConfigProvider: config.NewConfig(),
+		store:                   NewMemoryRateLimitStore(100),
+	}
+
+	// create router
+	r := mux.NewRouter()
+	r.HandleFunc("/meetings/{meetingId}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.HandleFunc("/other", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.Use(AuthMiddleware(config.NewConfig()))
+	r.Use(RateLimitMiddleware(ds, RateLimitPolicy{
+		Name:   "meeting",
+		Method: http.MethodGet,
+		Path:   "/meetings/{meetingId}",
+		By:     RateLimitKey_User,
+		Limit:  RateLimit{Requests: 1, Per: time.Minute},
+	}))
+
+	// test limited route
+	for i, expected := range []int{http.StatusNoContent, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/meetings/"+string(rune('a'+i)), nil))
+
+		if s := w.Result().StatusCode; s != expected {
+			t.Errorf("expected status to be %#v got %#v", expected, s)
+			return
+		}
+		if expected == http.StatusTooManyRequests && w.Header().Get("retry-after") != "60" {
+			t.Errorf(`expected retry-after to be "60" got %q`, w.Header().Get("retry-after"))
+			return
+		}
+	}
+
+	// test other route
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/other", nil))
+
+	if s := w.Result().StatusCode; s != http.StatusNoContent {
+		t.Errorf("expected status to be %#v got %#v", http.StatusNoContent, s)
+		return
+	}
+}
+
+type mockRateLimitConfigProvider struct {
+	rateLimitConfig config.RateLimitConfig
+}
+
+func (m *mockRateLimitConfigProvider) RateLimitConfig() config.RateLimitConfig {
+	return m.rateLimitConfig
+}
+
+func TestRateLimitMiddlewareOverride(t *testing.T) {
+	t.Parallel()
+	ds := &mockRateLimitDeps{
+		RateLimitConfigProvider: &mockRateLimitConfigProvider{config.RateLimitConfig{
+			Enabled:      true,
+			StoreTimeout: time.Second,
+			Overrides: map[string]config.RateLimitOverride{
+				"other": {Requests: 2, Per: time.Minute},
+			},
+		}},
+		store: NewMemoryRateLimitStore(100),
+	}
+
+	// create router
+	r := mux.NewRouter()
+	r.HandleFunc("/other", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.Use(RateLimitMiddleware(ds, RateLimitPolicy{
+		Name:  "other",
+		By:    RateLimitKey_IP,
+		Limit: RateLimit{Requests: 1, Per: time.Minute},
+	}))
+
+	// overridden limit allows two requests
+	for i, expected := range []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/other", nil))
+
+		if s := w.Result().StatusCode; s != expected {
+			t.Errorf("expected status of request %d to be %#v got %#v", i, expected, s)
+			return
+		}
+	}
+}
+
+func TestRateLimitMiddlewareDisabled(t *testing.T) {
+	t.Parallel()
+	ds := &mockRateLimitDeps{
+		RateLimitConfigProvider: &mockRateLimitConfigProvider{config.RateLimitConfig{
+			Enabled:      true,
+			StoreTimeout: time.Second,
+		}},
+		store: NewMemoryRateLimitStore(100),
+	}
+
+	// create router
+	r := mux.NewRouter()
+	r.HandleFunc("/webhook", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.HandleFunc("/other", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.Use(RateLimitMiddleware(ds, RateLimitPolicy{
+		Name:  "all",
+		By:    RateLimitKey_IP,
+		Limit: RateLimit{Requests: 1, Per: time.Minute},
+	}, RateLimitPolicy{
+		Name:     "webhook",
+		Path:     "/webhook",
+		Disabled: true,
+	}))
+
+	// disabled route is not limited
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", nil))
+
+		if s := w.Result().StatusCode; s != http.StatusNoContent {
+			t.Errorf("expected status of request %d to be %#v got %#v", i, http.StatusNoContent, s)
+			return
+		}
+	}
+
+	// other routes are limited
+	for i, expected := range []int{http.StatusNoContent, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/other", nil))
+
+		if s := w.Result().StatusCode; s != expected {
+			t.Errorf("expected status of request %d to be %#v got %#v", i, expected, s)
+			return
+		}
+	}
+}
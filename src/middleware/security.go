@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/gorilla/mux"
+)
+
+// SecurityHeadersMiddleware sets security headers on every response, hsts is
+// only set for https requests as browsers ignore it otherwise
+func SecurityHeadersMiddleware(ds config.SecurityConfigProvider) mux.MiddlewareFunc {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cf := ds.SecurityConfig()
+
+			w.Header().Set("x-content-type-options", "nosniff")
+			w.Header().Set("referrer-policy", cf.ReferrerPolicy)
+			w.Header().Set("x-frame-options", cf.FrameOptions)
+			w.Header().Set("content-security-policy", cf.ContentSecurityPolicy)
+
+			if cf.HSTSEnabled && IsHTTPS(r) {
+				hsts := "max-age=" + strconv.Itoa(int(cf.HSTSMaxAge.Seconds()))
+				if cf.HSTSIncludeSubdomains {
+					hsts += "; includeSubDomains"
+				}
+				if cf.HSTSPreload {
+					hsts += "; preload"
+				}
+				w.Header().Set("strict-transport-security", hsts)
+			}
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+)
+
+type mockSecurityConfigProvider struct {
+	securityConfig config.SecurityConfig
+}
+
+func (m *mockSecurityConfigProvider) SecurityConfig() config.SecurityConfig {
+	return m.securityConfig
+}
+
+func newMockSecurityConfigProvider() *mockSecurityConfigProvider {
+	return &mockSecurityConfigProvider{config.SecurityConfig{
+		HSTSEnabled:           true,
+		HSTSMaxAge:            time.Hour,
+		HSTSIncludeSubdomains: true,
+		ReferrerPolicy:        "no-referrer",
+		FrameOptions:          "DENY",
+		ContentSecurityPolicy: "default-src 'none'",
+	}}
+}
+
+func serveSecurityHeaders(m *mockSecurityConfigProvider, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	SecurityHeadersMiddleware(m).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})).ServeHTTP(w, r)
+	return w
+}
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := serveSecurityHeaders(newMockSecurityConfigProvider(), r)
+
+	for name, want := range map[string]string{
+		"x-content-type-options":    "nosniff",
+		"referrer-policy":           "no-referrer",
+		"x-frame-options":           "DENY",
+		"content-security-policy":   "default-src 'none'",
+		"strict-transport-security": "",
+	} {
+		if h := w.Header().Get(name); h != want {
+			t.Errorf(`expected %s to be %q got %q`, name, want, h)
+		}
+	}
+}
+
+func TestSecurityHeadersMiddlewareWithHTTPS(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.TLS = &tls.ConnectionState{}
+	w := serveSecurityHeaders(newMockSecurityConfigProvider(), r)
+	if h := w.Header().Get("strict-transport-security"); h != "max-age=3600; includeSubDomains" {
+		t.Errorf(`expected strict-transport-security to be "max-age=3600; includeSubDomains" got %q`, h)
+	}
+
+	// disabled
+	m := newMockSecurityConfigProvider()
+	m.securityConfig.HSTSEnabled = false
+	w = serveSecurityHeaders(m, r)
+	if h := w.Header().Get("strict-transport-security"); h != "" {
+		t.Errorf(`expected strict-transport-security to be "" got %q`, h)
+	}
+}
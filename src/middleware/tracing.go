@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware continues the trace of the traceparent header of requests
+// from trusted proxies and starts a new trace for other requests so that
+// clients cannot choose sampling, with a server span per route template. Must
+// be used after the proxy middleware.
+func TracingMiddleware() mux.MiddlewareFunc {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := "unknown"
+			if cr := mux.CurrentRoute(r); cr != nil {
+				if t, err := cr.GetPathTemplate(); err == nil {
+					route = t
+				}
+			}
+
+			ctx := r.Context()
+			opts := []trace.SpanStartOption{
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.method", r.Method),
+					attribute.String("http.route", route),
+				),
+			}
+			if FromTrustedProxy(r) {
+				ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+			} else {
+				opts = append(opts, trace.WithNewRoot())
+			}
+			ctx, span := tracing.Start(ctx, r.Method+" "+route, opts...)
+			defer span.End()
+
+			// include trace in logs
+			if sc := span.SpanContext(); sc.IsValid() {
+				AddLogAttrs(ctx, "traceId", sc.TraceID().String(), "spanId", sc.SpanID().String())
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+			handler.ServeHTTP(rec, r.WithContext(ctx))
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+
+			span.SetAttributes(attribute.Int("http.status_code", rec.status))
+			if rec.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rec.status))
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	// not parallel, sets the global tracer provider
+
+	// record spans
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	prevTP, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	// create router
+	r := mux.NewRouter()
+	r.HandleFunc("/tracingtest/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	r.Use(ProxyMiddleware(&mockProxyConfigProvider{config.ProxyConfig{
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")},
+	}}))
+	r.Use(TracingMiddleware())
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/tracingtest/a", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(w, req)
+
+	// test span
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Errorf("expected 1 span got %d", len(spans))
+		return
+	}
+	s := spans[0]
+	if n := s.Name(); n != "GET /tracingtest/{id}" {
+		t.Errorf(`expected span name to be "GET /tracingtest/{id}" got %q`, n)
+		return
+	}
+	if id := s.SpanContext().TraceID().String(); id != traceID {
+		t.Errorf("expected trace id to be %q got %q", traceID, id)
+		return
+	}
+	if c := s.Status().Code; c != codes.Error {
+		t.Errorf("expected span status to be error got %v", c)
+		return
+	}
+	found := false
+	for _, a := range s.Attributes() {
+		if a == attribute.Int("http.status_code", http.StatusInternalServerError) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected http.status_code attribute to be 500")
+		return
+	}
+}
+
+func TestTracingMiddlewareUntrustedParent(t *testing.T) {
+	// not parallel, sets the global tracer provider
+
+	// record spans
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	prevTP, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevPropagator)
+	}()
+
+	// create router without trusted proxies
+	r := mux.NewRouter()
+	r.HandleFunc("/tracingtest", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	r.Use(ProxyMiddleware(&mockProxyConfigProvider{}))
+	r.Use(TracingMiddleware())
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/tracingtest", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	r.ServeHTTP(w, req)
+
+	// test span starts a new trace
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Errorf("expected 1 span got %d", len(spans))
+		return
+	}
+	if id := spans[0].SpanContext().TraceID().String(); id == traceID {
+		t.Errorf("expected trace id not to be %q", traceID)
+		return
+	}
+	if spans[0].Parent().IsValid() {
+		t.Errorf("expected span to be a root span")
+		return
+	}
+}
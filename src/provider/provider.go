@@ -2,11 +2,15 @@ package provider
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aravindanve/livemeet-server/src/client"
 	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/event"
+	"github.com/aravindanve/livemeet-server/src/middleware"
 	"github.com/aravindanve/livemeet-server/src/resource"
 	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 type Provider interface {
@@ -14,46 +18,143 @@ type Provider interface {
 	client.MongoClientProvider
 	client.GoogleOAuth2ClientProvider
 	client.LiveKitClientProvider
+	client.WebhookClientProvider
+	client.MailerProvider
+	client.CertCacheProvider
+	event.BusProvider
+	middleware.RateLimitStoreProvider
 	resource.UserCollectionProvider
 	resource.AuthCollectionProvider
 	resource.MeetingCollectionProvider
 	resource.ParticipantCollectionProvider
-	Release(ctx context.Context)
+	resource.WebhookCollectionProvider
+	resource.WebhookDeliveryCollectionProvider
+	resource.InvitationCollectionProvider
+	resource.OccurrenceCollectionProvider
+	resource.AttendanceCollectionProvider
+	resource.BreakoutCollectionProvider
+	resource.MessageCollectionProvider
+	resource.RecordingCollectionProvider
+	EnsureIndexes(ctx context.Context) error
+	Release(ctx context.Context) error
 }
 
 type provider struct {
 	config.Config
-	mongoClient           *mongo.Client
-	mongoDatabase         *mongo.Database
-	googleOAuth2Client    client.GoogleOAuth2Client
-	livekitClient         client.LiveKitClient
-	authCollection        *resource.AuthCollection
-	userCollection        *resource.UserCollection
-	meetingCollection     *resource.MeetingCollection
-	participantCollection *resource.ParticipantCollection
+	mongoClient               *mongo.Client
+	mongoDatabase             *mongo.Database
+	googleOAuth2Client        client.GoogleOAuth2Client
+	livekitClient             client.LiveKitClient
+	webhookClient             client.WebhookClient
+	mailer                    client.Mailer
+	certCache                 autocert.Cache
+	eventBus                  event.Bus
+	rateLimitStore            middleware.RateLimitStore
+	authCollection            *resource.AuthCollection
+	userCollection            *resource.UserCollection
+	meetingCollection         *resource.MeetingCollection
+	participantCollection     *resource.ParticipantCollection
+	webhookCollection         *resource.WebhookCollection
+	webhookDeliveryCollection *resource.WebhookDeliveryCollection
+	invitationCollection      *resource.InvitationCollection
+	occurrenceCollection      *resource.OccurrenceCollection
+	attendanceCollection      *resource.AttendanceCollection
+	breakoutCollection        *resource.BreakoutCollection
+	messageCollection         *resource.MessageCollection
+	recordingCollection       *resource.RecordingCollection
 }
 
+// NewProvider creates a provider with config from env and CONFIG_FILE, panics
+// if the config is invalid
 func NewProvider(ctx context.Context) Provider {
-	cf := config.NewConfig()
+	return NewProviderWithConfig(ctx, config.NewConfig())
+}
 
+func NewProviderWithConfig(ctx context.Context, cf config.Config) Provider {
 	mongoClient := client.NewMongoClient(ctx, cf)
 	mongoDatabase := client.GetMongoDatabaseDefault(mongoClient, cf)
+	eventBus := event.NewBus(cf, mongoDatabase,
+		resource.MeetingCollectionName,
+		resource.ParticipantCollectionName,
+		resource.RecordingCollectionName,
+	)
 
 	return &provider{
-		Config:                cf,
-		mongoClient:           mongoClient,
-		mongoDatabase:         mongoDatabase,
-		googleOAuth2Client:    client.NewGoogleOAuth2Client(cf),
-		livekitClient:         client.NewLiveKitClient(cf),
-		authCollection:        resource.NewAuthCollection(ctx, mongoDatabase),
-		userCollection:        resource.NewUserCollection(ctx, mongoDatabase),
-		meetingCollection:     resource.NewMeetingCollection(ctx, mongoDatabase),
-		participantCollection: resource.NewParticipantCollection(ctx, mongoDatabase),
+		Config:                    cf,
+		mongoClient:               mongoClient,
+		mongoDatabase:             mongoDatabase,
+		googleOAuth2Client:        client.NewGoogleOAuth2Client(cf),
+		livekitClient:             client.NewLiveKitClient(cf),
+		webhookClient:             client.NewWebhookClient(nil),
+		mailer:                    client.NewMailer(cf),
+		certCache:                 client.NewCertCache(cf, mongoDatabase),
+		eventBus:                  eventBus,
+		rateLimitStore:            middleware.NewRateLimitStore(cf, mongoDatabase),
+		authCollection:            resource.NewAuthCollection(mongoDatabase),
+		userCollection:            resource.NewUserCollection(mongoDatabase),
+		meetingCollection:         resource.NewMeetingCollection(mongoDatabase, eventBus, cf),
+		participantCollection:     resource.NewParticipantCollection(mongoDatabase, eventBus),
+		webhookCollection:         resource.NewWebhookCollection(mongoDatabase),
+		webhookDeliveryCollection: resource.NewWebhookDeliveryCollection(mongoDatabase),
+		invitationCollection:      resource.NewInvitationCollection(mongoDatabase),
+		occurrenceCollection:      resource.NewOccurrenceCollection(mongoDatabase, cf),
+		attendanceCollection:      resource.NewAttendanceCollection(mongoDatabase, cf),
+		breakoutCollection:        resource.NewBreakoutCollection(mongoDatabase, cf),
+		messageCollection:         resource.NewMessageCollection(mongoDatabase, cf),
+		recordingCollection:       resource.NewRecordingCollection(mongoDatabase, eventBus, cf),
 	}
 }
 
-func (p *provider) Release(ctx context.Context) {
-	p.mongoClient.Disconnect(ctx)
+type indexer interface {
+	EnsureIndexes(ctx context.Context) error
+}
+
+// EnsureIndexes creates the indexes of all collections
+func (p *provider) EnsureIndexes(ctx context.Context) error {
+	indexers := []struct {
+		name string
+		indexer
+	}{
+		{"auth", p.authCollection},
+		{"user", p.userCollection},
+		{"meeting", p.meetingCollection},
+		{"participant", p.participantCollection},
+		{"webhook", p.webhookCollection},
+		{"webhookDelivery", p.webhookDeliveryCollection},
+		{"invitation", p.invitationCollection},
+		{"occurrence", p.occurrenceCollection},
+		{"attendance", p.attendanceCollection},
+		{"breakout", p.breakoutCollection},
+		{"message", p.messageCollection},
+		{"recording", p.recordingCollection},
+	}
+	if i, ok := p.rateLimitStore.(indexer); ok {
+		indexers = append(indexers, struct {
+			name string
+			indexer
+		}{"rateLimit", i})
+	}
+
+	for _, i := range indexers {
+		if err := i.EnsureIndexes(ctx); err != nil {
+			return fmt.Errorf("error creating mongo indexes for %s: %w", i.name, err)
+		}
+	}
+	return nil
+}
+
+// Release stops background work and disconnects from mongo, the event bus is
+// closed first so that subscribers such as the webhook dispatcher finish.
+// Returns the first error.
+func (p *provider) Release(ctx context.Context) error {
+	err := p.eventBus.Close(ctx)
+	if werr := p.authCollection.Wait(ctx); err == nil {
+		err = werr
+	}
+	if derr := p.mongoClient.Disconnect(ctx); err == nil {
+		err = derr
+	}
+	return err
 }
 
 func (p *provider) MongoClient() *mongo.Client {
@@ -72,6 +173,26 @@ func (p *provider) LiveKitClient() client.LiveKitClient {
 	return p.livekitClient
 }
 
+func (p *provider) WebhookClient() client.WebhookClient {
+	return p.webhookClient
+}
+
+func (p *provider) Mailer() client.Mailer {
+	return p.mailer
+}
+
+func (p *provider) CertCache() autocert.Cache {
+	return p.certCache
+}
+
+func (p *provider) EventBus() event.Bus {
+	return p.eventBus
+}
+
+func (p *provider) RateLimitStore() middleware.RateLimitStore {
+	return p.rateLimitStore
+}
+
 func (p *provider) AuthCollection() *resource.AuthCollection {
 	return p.authCollection
 }
@@ -87,3 +208,35 @@ func (p *provider) MeetingCollection() *resource.MeetingCollection {
 func (p *provider) ParticipantCollection() *resource.ParticipantCollection {
 	return p.participantCollection
 }
+
+func (p *provider) WebhookCollection() *resource.WebhookCollection {
+	return p.webhookCollection
+}
+
+func (p *provider) WebhookDeliveryCollection() *resource.WebhookDeliveryCollection {
+	return p.webhookDeliveryCollection
+}
+
+func (p *provider) InvitationCollection() *resource.InvitationCollection {
+	return p.invitationCollection
+}
+
+func (p *provider) OccurrenceCollection() *resource.OccurrenceCollection {
+	return p.occurrenceCollection
+}
+
+func (p *provider) AttendanceCollection() *resource.AttendanceCollection {
+	return p.attendanceCollection
+}
+
+func (p *provider) BreakoutCollection() *resource.BreakoutCollection {
+	return p.breakoutCollection
+}
+
+func (p *provider) MessageCollection() *resource.MessageCollection {
+	return p.messageCollection
+}
+
+func (p *provider) RecordingCollection() *resource.RecordingCollection {
+	return p.recordingCollection
+}
@@ -0,0 +1,468 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"github.com/aravindanve/livemeet-server/src/util"
+	"github.com/gorilla/mux"
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	attendanceWebhookBodyBytesMax = 1 << 20
+)
+
+type AttendanceDeps interface {
+	config.LiveKitConfigProvider
+	MeetingCollectionProvider
+	AttendanceCollectionProvider
+	RecordingCollectionProvider
+}
+
+// Attendance records the sessions of a participant in the conference room
+type Attendance struct {
+	ID            ResourceID          `json:"id" bson:"_id,omitempty"`
+	MeetingID     ResourceID          `json:"meetingId" bson:"meetingId"`
+	OccurrenceID  *string             `json:"occurrenceId" bson:"occurrenceId"`
+	ParticipantID ResourceID          `json:"participantId" bson:"participantId"`
+	UserID        *ResourceID         `json:"userId" bson:"userId,omitempty"` // nil for guests
+	Name          string              `json:"name" bson:"name"`
+	Sessions      []AttendanceSession `json:"sessions" bson:"sessions"`
+	CreatedAt     time.Time           `json:"createdAt" bson:"createdAt"`
+	UpdatedAt     time.Time           `json:"updatedAt" bson:"updatedAt"`
+	ExpiresAt     time.Time           `json:"expiresAt" bson:"expiresAt"`
+}
+
+// AttendanceSession is the time between joining and leaving the room
+type AttendanceSession struct {
+	SessionID string     `json:"sessionId" bson:"sessionId"` // livekit participant sid
+	JoinedAt  time.Time  `json:"joinedAt" bson:"joinedAt"`
+	LeftAt    *time.Time `json:"leftAt" bson:"leftAt"`
+}
+
+// AttendanceReportRow sums up the attendance of a signed in user or of a
+// guest participant
+type AttendanceReportRow struct {
+	UserID        *ResourceID `json:"userId"`
+	Name          string      `json:"name"`
+	Sessions      int         `json:"sessions"`
+	FirstJoinedAt *time.Time  `json:"firstJoinedAt"`
+	LastLeftAt    *time.Time  `json:"lastLeftAt"`
+	Duration      int64       `json:"duration"` // seconds
+}
+
+// returns report rows in order of first join, sessions still in progress
+// count until now
+func newAttendanceReport(attendances []*Attendance, now time.Time) []*AttendanceReportRow {
+	rows := make([]*AttendanceReportRow, 0)
+	rowsByKey := make(map[string]*AttendanceReportRow)
+
+	for _, a := range attendances {
+		key := "participant:" + string(a.ParticipantID)
+		if a.UserID != nil {
+			key = "user:" + string(*a.UserID)
+		}
+
+		row, ok := rowsByKey[key]
+		if !ok {
+			row = &AttendanceReportRow{UserID: a.UserID, Name: a.Name}
+			rowsByKey[key] = row
+			rows = append(rows, row)
+		}
+
+		for _, s := range a.Sessions {
+			joinedAt, leftAt := s.JoinedAt, now
+			if s.LeftAt != nil {
+				leftAt = *s.LeftAt
+			}
+
+			row.Sessions++
+			if row.FirstJoinedAt == nil || joinedAt.Before(*row.FirstJoinedAt) {
+				row.FirstJoinedAt = &joinedAt
+			}
+			if s.LeftAt != nil && (row.LastLeftAt == nil || s.LeftAt.After(*row.LastLeftAt)) {
+				row.LastLeftAt = s.LeftAt
+			}
+			if leftAt.After(joinedAt) {
+				row.Duration += int64(leftAt.Sub(joinedAt).Seconds())
+			}
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].FirstJoinedAt == nil || rows[j].FirstJoinedAt == nil {
+			return rows[j].FirstJoinedAt == nil && rows[i].FirstJoinedAt != nil
+		}
+		return rows[i].FirstJoinedAt.Before(*rows[j].FirstJoinedAt)
+	})
+
+	return rows
+}
+
+type AttendanceCollectionProvider interface {
+	AttendanceCollection() *AttendanceCollection
+}
+
+type AttendanceCollection struct {
+	collection *mongo.Collection
+	cf         config.AttendanceConfigProvider
+}
+
+func NewAttendanceCollection(db *mongo.Database, cf config.AttendanceConfigProvider) *AttendanceCollection {
+	collection := db.Collection("attendance")
+
+	return &AttendanceCollection{collection: collection, cf: cf}
+}
+
+// EnsureIndexes creates the indexes of the collection
+func (c *AttendanceCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "AttendanceCollection.EnsureIndexes")
+	defer span.End()
+
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "participantId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}, {
+		Keys: bson.D{{Key: "meetingId", Value: 1}, {Key: "occurrenceId", Value: 1}},
+	}, {
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}})
+	return err
+}
+
+func (c *AttendanceCollection) FindAllByMeetingID(
+	ctx context.Context, meetingID ResourceID, occurrenceID *string,
+) ([]*Attendance, error) {
+	ctx, span := tracing.Start(ctx, "AttendanceCollection.FindAllByMeetingID")
+	defer span.End()
+
+	filter := bson.D{{Key: "meetingId", Value: meetingID}}
+	if occurrenceID != nil {
+		filter = append(filter, bson.E{Key: "occurrenceId", Value: *occurrenceID})
+	}
+
+	cur, err := c.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	attendances := make([]*Attendance, 0)
+	err = cur.All(ctx, &attendances)
+	if err != nil {
+		return nil, err
+	}
+
+	return attendances, nil
+}
+
+// Register creates the attendance of a participant issued a conference room
+// token, does nothing if it exists
+func (c *AttendanceCollection) Register(
+	ctx context.Context, participant *Participant,
+) error {
+	ctx, span := tracing.Start(ctx, "AttendanceCollection.Register")
+	defer span.End()
+
+	now := time.Now()
+	_, err := c.collection.UpdateOne(ctx, bson.D{
+		{Key: "participantId", Value: participant.ID},
+	}, bson.D{
+		{Key: "$setOnInsert", Value: &Attendance{
+			MeetingID:     participant.MeetingID,
+			OccurrenceID:  participant.OccurrenceID,
+			ParticipantID: participant.ID,
+			UserID:        participant.UserID,
+			Name:          participant.Name,
+			Sessions:      []AttendanceSession{},
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			ExpiresAt:     now.Add(c.cf.AttendanceConfig().TTL),
+		}},
+	}, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil // registered concurrently
+	}
+	return err
+}
+
+// Join starts a session of a registered participant, sessions are recorded
+// once even if the webhook is delivered again
+func (c *AttendanceCollection) Join(
+	ctx context.Context, participantID ResourceID, sessionID string, at time.Time,
+) error {
+	ctx, span := tracing.Start(ctx, "AttendanceCollection.Join")
+	defer span.End()
+
+	_, err := c.collection.UpdateOne(ctx, bson.D{
+		{Key: "participantId", Value: participantID},
+		{Key: "sessions.sessionId", Value: bson.D{{Key: "$ne", Value: sessionID}}},
+	}, bson.D{
+		{Key: "$push", Value: bson.D{{Key: "sessions", Value: AttendanceSession{
+			SessionID: sessionID,
+			JoinedAt:  at,
+		}}}},
+		{Key: "$set", Value: bson.D{{Key: "updatedAt", Value: time.Now()}}},
+	})
+	return err
+}
+
+// Leave ends a session of a registered participant
+func (c *AttendanceCollection) Leave(
+	ctx context.Context, participantID ResourceID, sessionID string, at time.Time,
+) error {
+	ctx, span := tracing.Start(ctx, "AttendanceCollection.Leave")
+	defer span.End()
+
+	_, err := c.collection.UpdateOne(ctx, bson.D{
+		{Key: "participantId", Value: participantID},
+		{Key: "sessions.sessionId", Value: sessionID},
+	}, bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "sessions.$.leftAt", Value: at},
+			{Key: "updatedAt", Value: time.Now()},
+		}},
+	})
+	return err
+}
+
+type AttendanceController struct {
+	AttendanceDeps
+}
+
+func NewAttendanceController(ds AttendanceDeps) *AttendanceController {
+	return &AttendanceController{AttendanceDeps: ds}
+}
+
+func (c *AttendanceController) AttendanceSearchHandler(w http.ResponseWriter, r *http.Request) {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return
+	}
+
+	// get format
+	format := r.URL.Query().Get("format")
+	if format == "" && strings.Contains(r.Header.Get("accept"), "text/csv") {
+		format = "csv"
+	}
+	if format != "" && format != "json" && format != "csv" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Unexpected format in request query")
+		return
+	}
+
+	// get occurrence id
+	var occurrenceID *string
+	if q := r.URL.Query().Get("occurrenceId"); q != "" {
+		occurrenceID = &q
+	}
+
+	// find meeting
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	// ensure auth user is the meeting admin
+	if auth.UserID != string(meeting.UserID) {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Only meeting admins can view attendance")
+		return
+	}
+
+	// find attendance
+	attendances, err := c.AttendanceCollection().FindAllByMeetingID(r.Context(), meeting.ID, occurrenceID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rows := newAttendanceReport(attendances, time.Now())
+
+	if format == "csv" {
+		w.Header().Set("content-type", "text/csv")
+		w.Header().Set("content-disposition", fmt.Sprintf(`attachment; filename="attendance-%s.csv"`, meeting.Code))
+		w.WriteHeader(http.StatusOK)
+
+		formatTime := func(t *time.Time) string {
+			if t == nil {
+				return ""
+			}
+			return t.UTC().Format(time.RFC3339)
+		}
+
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"userId", "name", "sessions", "firstJoinedAt", "lastLeftAt", "duration"})
+		for _, row := range rows {
+			var userID string
+			if row.UserID != nil {
+				userID = string(*row.UserID)
+			}
+			cw.Write([]string{
+				escapeCSVField(userID),
+				escapeCSVField(row.Name),
+				strconv.Itoa(row.Sessions),
+				formatTime(row.FirstJoinedAt),
+				formatTime(row.LastLeftAt),
+				strconv.FormatInt(row.Duration, 10),
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			// headers are sent, the client sees a truncated file
+			middleware.Logger(r.Context()).Warn("error writing attendance csv", "error", err)
+		}
+		return
+	}
+
+	res := map[string]any{
+		"attendees": rows,
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, res)
+}
+
+// prefixes values that spreadsheet applications would evaluate as formulas
+// with a quote so names such as =HYPERLINK(...) are shown as text
+func escapeCSVField(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// AttendanceWebhookHandler receives participant and egress events from
+// livekit, requests are signed with the livekit api key
+func (c *AttendanceController) AttendanceWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	// verify and decode event
+	// the body is read by every attempt so it is buffered to try each secret
+	// the request is not authenticated until the body is read so it is capped
+	cf := c.LiveKitConfig()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, attendanceWebhookBodyBytesMax))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			util.WriteJSONError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var e *livekit.WebhookEvent
+	for _, secret := range cf.APISecrets() {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if e, err = webhook.ReceiveWebhookEvent(r, auth.NewSimpleKeyProvider(cf.APIKey, secret)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// record egress of the conference room
+	if e.Event == webhook.EventEgressEnded && e.EgressInfo != nil {
+		c.recordEgress(w, r, e.EgressInfo)
+		return
+	}
+
+	// ignore other events and waiting rooms
+	if e.Participant == nil || e.Room == nil || strings.HasSuffix(e.Room.Name, participantWaitingRoomSuffix) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	at := time.Now()
+	if e.CreatedAt > 0 {
+		at = time.Unix(e.CreatedAt, 0)
+	}
+
+	participantID := ResourceID(e.Participant.Identity)
+	switch e.Event {
+	case webhook.EventParticipantJoined:
+		if e.Participant.JoinedAt > 0 {
+			at = time.Unix(e.Participant.JoinedAt, 0)
+		}
+		err = c.AttendanceCollection().Join(r.Context(), participantID, e.Participant.Sid, at)
+	case webhook.EventParticipantLeft:
+		err = c.AttendanceCollection().Leave(r.Context(), participantID, e.Participant.Sid, at)
+	}
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *AttendanceController) recordEgress(w http.ResponseWriter, r *http.Request, info *livekit.EgressInfo) {
+	// ignore waiting rooms and breakout rooms
+	roomName := egressRoomName(info)
+	if roomName == "" || strings.HasSuffix(roomName, participantWaitingRoomSuffix) ||
+		strings.Contains(roomName, breakoutRoomSuffix) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// find meeting
+	meetings, err := c.MeetingCollection().FindAnyByCode(r.Context(), roomName)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(meetings) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	err = c.RecordingCollection().Insert(r.Context(), newRecording(meetings[0].ID, info))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func RegisterAttendanceRoutes(r *mux.Router, ds AttendanceDeps) *mux.Router {
+	c := NewAttendanceController(ds)
+
+	r.HandleFunc("/meetings/{meetingId}/attendance", c.AttendanceSearchHandler).Methods(http.MethodGet)
+	r.HandleFunc("/livekit/webhook", c.AttendanceWebhookHandler).Methods(http.MethodPost)
+
+	return r
+}
@@ -0,0 +1,61 @@
+package resource
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewAttendanceReport(t *testing.T) {
+	t.Parallel()
+	var t0, _ = time.Parse(time.RFC3339, "2022-01-01T00:00:00.000Z")
+	var t1, t2 = t0.Add(10 * time.Minute), t0.Add(30 * time.Minute)
+	var userID ResourceID = "some-user-id"
+
+	rows := newAttendanceReport([]*Attendance{{
+		ParticipantID: "participant-1",
+		UserID:        &userID,
+		Name:          "Aravindan",
+		Sessions:      []AttendanceSession{{SessionID: "a", JoinedAt: t0, LeftAt: &t1}},
+	}, {
+		ParticipantID: "participant-2",
+		Name:          "Guest",
+		Sessions:      []AttendanceSession{{SessionID: "b", JoinedAt: t1}},
+	}, {
+		ParticipantID: "participant-3",
+		UserID:        &userID,
+		Name:          "Aravindan",
+		Sessions:      []AttendanceSession{{SessionID: "c", JoinedAt: t1, LeftAt: &t2}},
+	}}, t2)
+
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows got %d", len(rows))
+	}
+	if rows[0].UserID == nil || *rows[0].UserID != userID || rows[0].Sessions != 2 {
+		t.Fatalf("Unexpected first row: %#v", rows[0])
+	}
+	if rows[0].Duration != 30*60 || !rows[0].LastLeftAt.Equal(t2) {
+		t.Fatalf("Unexpected first row duration %d or last left at %v", rows[0].Duration, rows[0].LastLeftAt)
+	}
+	if rows[1].UserID != nil || rows[1].Duration != 20*60 || rows[1].LastLeftAt != nil {
+		t.Fatalf("Unexpected second row: %#v", rows[1])
+	}
+}
+
+func TestEscapeCSVField(t *testing.T) {
+	t.Parallel()
+	for in, out := range map[string]string{
+		"":                  "",
+		"Aravindan":         "Aravindan",
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"+1":                "'+1",
+		"-1":                "'-1",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"\tname":            "'\tname",
+		"\rname":            "'\rname",
+		"a=b":               "a=b",
+	} {
+		if v := escapeCSVField(in); v != out {
+			t.Errorf("Expected %q to be escaped to %q got %q", in, out, v)
+		}
+	}
+}
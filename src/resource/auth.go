@@ -5,15 +5,14 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
-	"log"
 	"net/http"
-	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aravindanve/livemeet-server/src/client"
 	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/tracing"
 	"github.com/aravindanve/livemeet-server/src/util"
 	"github.com/gorilla/mux"
 	"github.com/lestrrat-go/jwx/v2/jwt"
@@ -24,7 +23,6 @@ import (
 )
 
 const (
-	authRefreshTokenTTL      = 90 * 24 * time.Hour
 	AuthRefreshTokenCountMax = 50
 )
 
@@ -50,7 +48,7 @@ type Auth struct {
 	UpdatedAt             time.Time  `json:"updatedAt" bson:"updatedAt"`
 }
 
-func newAuth(userID ResourceID) (*Auth, error) {
+func newAuth(cf config.AuthConfig, userID ResourceID) (*Auth, error) {
 	// create refresh token
 	buf := make([]byte, 128)
 	_, err := rand.Read(buf)
@@ -59,7 +57,7 @@ func newAuth(userID ResourceID) (*Auth, error) {
 	}
 
 	refreshToken := base64.RawURLEncoding.EncodeToString(buf)
-	refreshTokenExpiresAt := time.Now().Add(authRefreshTokenTTL)
+	refreshTokenExpiresAt := time.Now().Add(cf.RefreshTokenTTL)
 
 	// create auth
 	return &Auth{
@@ -114,45 +112,44 @@ type AuthCollectionProvider interface {
 
 type AuthCollection struct {
 	collection *mongo.Collection
+	wg         sync.WaitGroup
 }
 
-func NewAuthCollection(ctx context.Context, db *mongo.Database) *AuthCollection {
+func NewAuthCollection(db *mongo.Database) *AuthCollection {
 	collection := db.Collection("auth")
 
-	// create indexes
-	go func() {
-		// index for expire
-		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
-			Keys:    bson.D{{Key: "refreshTokenExpiresAt", Value: 1}},
-			Options: options.Index().SetExpireAfterSeconds(0),
-		})
-		// index for gc sort
-		if err == nil {
-			_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
-				Keys: bson.D{
-					{Key: "userId", Value: 1},
-					{Key: "refreshTokenExpiresAt", Value: 1},
-					{Key: "_id", Value: 1},
-				},
-			})
-		}
+	return &AuthCollection{collection: collection}
+}
 
-		if err != nil {
-			msg := fmt.Sprintf("error creating mongo indexes: %s", err.Error())
-			if os.Getenv("APP_ENV") == "testing" {
-				log.Println(msg) // do not panic in tests
-			} else {
-				panic(msg)
-			}
-		}
-	}()
+// EnsureIndexes creates the indexes of the collection
+func (c *AuthCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "AuthCollection.EnsureIndexes")
+	defer span.End()
 
-	return &AuthCollection{collection: collection}
+	// index for expire
+	_, err := c.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "refreshTokenExpiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	// index for gc sort
+	if err == nil {
+		_, err = c.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "refreshTokenExpiresAt", Value: 1},
+				{Key: "_id", Value: 1},
+			},
+		})
+	}
+	return err
 }
 
 func (c *AuthCollection) FindOneByIDAndRefreshToken(
 	ctx context.Context, id ResourceID, refreshToken string,
 ) (*Auth, error) {
+	ctx, span := tracing.Start(ctx, "AuthCollection.FindOneByIDAndRefreshToken")
+	defer span.End()
+
 	_id, err := id.ObjectID()
 	if err != nil {
 		return nil, err
@@ -177,6 +174,9 @@ func (c *AuthCollection) FindOneByIDAndRefreshToken(
 func (c *AuthCollection) DeleteOneByID(
 	ctx context.Context, id ResourceID, refreshToken string,
 ) (*Auth, error) {
+	ctx, span := tracing.Start(ctx, "AuthCollection.DeleteOneByID")
+	defer span.End()
+
 	_id, err := id.ObjectID()
 	if err != nil {
 		return nil, err
@@ -198,6 +198,9 @@ func (c *AuthCollection) DeleteOneByID(
 func (c *AuthCollection) Save(
 	ctx context.Context, auth *Auth,
 ) error {
+	ctx, span := tracing.Start(ctx, "AuthCollection.Save")
+	defer span.End()
+
 	if auth.ID == "" {
 		now := time.Now()
 		auth.CreatedAt = now
@@ -226,6 +229,33 @@ func (c *AuthCollection) Save(
 	}
 }
 
+// runs gc in the background, see Wait
+func (c *AuthCollection) gcAsync(userID ResourceID, maxCount int) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		c.gc(ctx, userID, maxCount)
+	}()
+}
+
+// Wait waits for gc running in the background or until ctx is done
+func (c *AuthCollection) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+		return nil
+	}
+}
+
 // keeps latest auths defined by max count
 func (c *AuthCollection) gc(
 	ctx context.Context, userID ResourceID, countMax int,
@@ -336,7 +366,9 @@ func (c *AuthController) AuthCreateHandler(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
-	// update user name and image url
+	// update user email, name and image url
+	user.Email = NormalizeUserEmail(gtoken.Email)
+	user.EmailDomain = newUserEmailDomain(user.Email, gtoken.HostedDomain)
 	if name := strings.Trim(gtoken.GivenName+" "+gtoken.FamilyName, " "); name != "" {
 		user.Name = name
 	}
@@ -352,7 +384,7 @@ func (c *AuthController) AuthCreateHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	// create auth
-	auth, err := newAuth(user.ID)
+	auth, err := newAuth(c.AuthConfig(), user.ID)
 	if err != nil {
 		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -375,11 +407,7 @@ func (c *AuthController) AuthCreateHandler(w http.ResponseWriter, r *http.Reques
 	util.WriteJSONResponse(w, http.StatusOK, res)
 
 	// run gc
-	go func(userID ResourceID) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		c.AuthCollection().gc(ctx, userID, AuthRefreshTokenCountMax)
-	}(auth.UserID)
+	c.AuthCollection().gcAsync(auth.UserID, AuthRefreshTokenCountMax)
 }
 
 type AuthRefreshBody struct {
@@ -417,7 +445,7 @@ func (c *AuthController) AuthRefreshHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	// create auth next
-	authNext, err := newAuth(authCurr.UserID)
+	authNext, err := newAuth(c.AuthConfig(), authCurr.UserID)
 	if err != nil {
 		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
 		return
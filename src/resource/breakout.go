@@ -0,0 +1,595 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/client"
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"github.com/aravindanve/livemeet-server/src/util"
+	"github.com/gorilla/mux"
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	BreakoutCountMax        = 50
+	BreakoutNameLengthMax   = 100
+	breakoutRoomSuffix      = "_breakout_"
+	breakoutParticipantsMax = 500
+)
+
+type BreakoutDeps interface {
+	config.LiveKitConfigProvider
+	client.LiveKitClientProvider
+	MeetingCollectionProvider
+	BreakoutCollectionProvider
+}
+
+// Breakout is a child room of a meeting that hosts assign admitted
+// participants to
+type Breakout struct {
+	ID             ResourceID   `json:"id" bson:"_id,omitempty"`
+	MeetingID      ResourceID   `json:"meetingId" bson:"meetingId"`
+	Name           string       `json:"name" bson:"name"`
+	RoomName       string       `json:"roomName" bson:"roomName"`
+	ParticipantIDs []ResourceID `json:"participantIds" bson:"participantIds"`
+	CreatedAt      time.Time    `json:"createdAt" bson:"createdAt"`
+	UpdatedAt      time.Time    `json:"updatedAt" bson:"updatedAt"`
+	ExpiresAt      time.Time    `json:"expiresAt" bson:"expiresAt"`
+}
+
+// returns breakout room names in the format <meeting code>_breakout_<n>
+func newBreakoutRoomName(meeting *Meeting, n int) string {
+	return meeting.Code + breakoutRoomSuffix + strconv.Itoa(n)
+}
+
+func (b *Breakout) hasParticipant(id ResourceID) bool {
+	for _, p := range b.ParticipantIDs {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
+
+// issues a breakout room token for the participant of a conference room token
+func newBreakoutRoomToken(cf config.LiveKitConfig, claims *auth.ClaimGrants, breakout *Breakout) (*RoomToken, error) {
+	at := auth.NewAccessToken(cf.APIKey, cf.APISecret)
+	tr := true
+	grant := &auth.VideoGrant{
+		Room:           breakout.RoomName,
+		RoomAdmin:      claims.Video.RoomAdmin,
+		RoomCreate:     true,
+		RoomJoin:       true,
+		CanPublish:     &tr,
+		CanPublishData: &tr,
+		CanSubscribe:   &tr,
+	}
+
+	at.AddGrant(grant).
+		SetIdentity(claims.Identity).
+		SetMetadata(claims.Metadata).
+		SetValidFor(cf.RoomTokenTTL)
+
+	token, err := at.ToJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RoomToken{
+		RoomName:             breakout.RoomName,
+		RoomType:             RoomType_Breakout,
+		AccessToken:          token,
+		AccessTokenExpiresAt: time.Now().Add(cf.RoomTokenTTL),
+	}, nil
+}
+
+type BreakoutCollectionProvider interface {
+	BreakoutCollection() *BreakoutCollection
+}
+
+type BreakoutCollection struct {
+	collection *mongo.Collection
+	cf         config.BreakoutConfigProvider
+}
+
+func NewBreakoutCollection(db *mongo.Database, cf config.BreakoutConfigProvider) *BreakoutCollection {
+	collection := db.Collection("breakout")
+
+	return &BreakoutCollection{collection: collection, cf: cf}
+}
+
+// EnsureIndexes creates the indexes of the collection
+func (c *BreakoutCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "BreakoutCollection.EnsureIndexes")
+	defer span.End()
+
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys: bson.D{{Key: "meetingId", Value: 1}},
+	}, {
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}})
+	return err
+}
+
+func (c *BreakoutCollection) FindAllByMeetingID(
+	ctx context.Context, meetingID ResourceID,
+) ([]*Breakout, error) {
+	ctx, span := tracing.Start(ctx, "BreakoutCollection.FindAllByMeetingID")
+	defer span.End()
+
+	cur, err := c.collection.Find(ctx, bson.D{
+		{Key: "meetingId", Value: meetingID},
+		notExpired(),
+	}, options.Find().SetSort(bson.D{{Key: "roomName", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	breakouts := make([]*Breakout, 0)
+	err = cur.All(ctx, &breakouts)
+	if err != nil {
+		return nil, err
+	}
+
+	return breakouts, nil
+}
+
+func (c *BreakoutCollection) InsertMany(
+	ctx context.Context, breakouts []*Breakout,
+) error {
+	ctx, span := tracing.Start(ctx, "BreakoutCollection.InsertMany")
+	defer span.End()
+
+	now := time.Now()
+	docs := make([]any, 0, len(breakouts))
+	for _, b := range breakouts {
+		b.ID = ResourceIDFromObjectID(primitive.NewObjectID())
+		b.CreatedAt = now
+		b.UpdatedAt = now
+		b.ExpiresAt = now.Add(c.cf.BreakoutConfig().TTL)
+		docs = append(docs, b)
+	}
+
+	_, err := c.collection.InsertMany(ctx, docs)
+	return err
+}
+
+// Assign moves participants into a breakout, removing them from other
+// breakouts of the meeting
+func (c *BreakoutCollection) Assign(
+	ctx context.Context, breakout *Breakout, participantIDs []ResourceID,
+) error {
+	ctx, span := tracing.Start(ctx, "BreakoutCollection.Assign")
+	defer span.End()
+
+	_id, err := breakout.ID.ObjectID()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = c.collection.UpdateMany(ctx, bson.D{
+		{Key: "meetingId", Value: breakout.MeetingID},
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: _id}}},
+	}, bson.D{
+		{Key: "$pull", Value: bson.D{{Key: "participantIds", Value: bson.D{{Key: "$in", Value: participantIDs}}}}},
+		{Key: "$set", Value: bson.D{{Key: "updatedAt", Value: now}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.collection.UpdateOne(ctx, bson.D{
+		{Key: "_id", Value: _id},
+	}, bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "participantIds", Value: participantIDs},
+			{Key: "updatedAt", Value: now},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	breakout.ParticipantIDs = participantIDs
+	breakout.UpdatedAt = now
+	return nil
+}
+
+func (c *BreakoutCollection) DeleteAllByMeetingID(
+	ctx context.Context, meetingID ResourceID,
+) error {
+	ctx, span := tracing.Start(ctx, "BreakoutCollection.DeleteAllByMeetingID")
+	defer span.End()
+
+	_, err := c.collection.DeleteMany(ctx, bson.D{
+		{Key: "meetingId", Value: meetingID},
+	})
+	return err
+}
+
+type BreakoutController struct {
+	BreakoutDeps
+}
+
+func NewBreakoutController(ds BreakoutDeps) *BreakoutController {
+	return &BreakoutController{BreakoutDeps: ds}
+}
+
+// finds the meeting in the request path and ensures the auth user is its
+// admin, writes an error response and returns nil otherwise
+func (c *BreakoutController) findAdminMeeting(w http.ResponseWriter, r *http.Request) *Meeting {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return nil
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil
+	}
+
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return nil
+	}
+
+	// find one by id
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return nil
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return nil
+	}
+
+	// ensure auth user is the meeting admin
+	if auth.UserID != string(meeting.UserID) {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Only meeting admins can manage breakouts")
+		return nil
+	}
+
+	return meeting
+}
+
+// sends data to the conference room and all breakout rooms of the meeting
+func (c *BreakoutController) notify(ctx context.Context, meeting *Meeting, breakouts []*Breakout, payload any) error {
+	data, err := EncodeData(c.LiveKitConfig(), payload)
+	if err != nil {
+		return err
+	}
+
+	rooms := make([]string, 0, len(breakouts)+1)
+	rooms = append(rooms, meeting.Code)
+	for _, b := range breakouts {
+		rooms = append(rooms, b.RoomName)
+	}
+
+	// rooms that are not found have no one to notify
+	var firstErr error
+	for _, room := range rooms {
+		_, err = c.LiveKitClient().SendData(ctx, &livekit.SendDataRequest{
+			Room: room,
+			Data: data,
+			Kind: livekit.DataPacket_RELIABLE,
+		})
+		if err != nil && !client.IsLiveKitNotFound(err) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+type BreakoutCreateBody struct {
+	Count int      `json:"count"`
+	Names []string `json:"names"`
+}
+
+func (c *BreakoutController) BreakoutCreateHandler(w http.ResponseWriter, r *http.Request) {
+	// find meeting
+	meeting := c.findAdminMeeting(w, r)
+	if meeting == nil {
+		return
+	}
+
+	// decode body
+	b := &BreakoutCreateBody{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if b.Count < 1 || b.Count > BreakoutCountMax {
+		util.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Count in request body must be 1 to %d", BreakoutCountMax))
+		return
+	}
+	if len(b.Names) > b.Count {
+		util.WriteJSONError(w, http.StatusBadRequest, "Names in request body exceed count")
+		return
+	}
+
+	// ensure meeting has no breakouts
+	existing, err := c.BreakoutCollection().FindAllByMeetingID(r.Context(), meeting.ID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(existing) > 0 {
+		util.WriteJSONError(w, http.StatusConflict, "Meeting already has breakouts")
+		return
+	}
+
+	// create breakouts
+	breakouts := make([]*Breakout, 0, b.Count)
+	for i := 1; i <= b.Count; i++ {
+		name := fmt.Sprintf("Room %d", i)
+		if i <= len(b.Names) && strings.TrimSpace(b.Names[i-1]) != "" {
+			name = strings.TrimSpace(b.Names[i-1])
+		}
+		if len(name) > BreakoutNameLengthMax {
+			util.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Name in request body exceeds %d characters", BreakoutNameLengthMax))
+			return
+		}
+
+		breakouts = append(breakouts, &Breakout{
+			MeetingID:      meeting.ID,
+			Name:           name,
+			RoomName:       newBreakoutRoomName(meeting, i),
+			ParticipantIDs: []ResourceID{},
+		})
+	}
+
+	// save breakouts
+	err = c.BreakoutCollection().InsertMany(r.Context(), breakouts)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, map[string]any{
+		"breakouts": breakouts,
+	})
+}
+
+func (c *BreakoutController) BreakoutSearchHandler(w http.ResponseWriter, r *http.Request) {
+	// find meeting
+	meeting := c.findAdminMeeting(w, r)
+	if meeting == nil {
+		return
+	}
+
+	// find breakouts
+	breakouts, err := c.BreakoutCollection().FindAllByMeetingID(r.Context(), meeting.ID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, map[string]any{
+		"breakouts": breakouts,
+	})
+}
+
+type BreakoutUpdateBody struct {
+	ParticipantIDs []ResourceID `json:"participantIds"`
+}
+
+func (c *BreakoutController) BreakoutUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	// find meeting
+	meeting := c.findAdminMeeting(w, r)
+	if meeting == nil {
+		return
+	}
+
+	// decode body
+	b := &BreakoutUpdateBody{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if b.ParticipantIDs == nil {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing participantIds in request body")
+		return
+	}
+	if len(b.ParticipantIDs) > breakoutParticipantsMax {
+		util.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("ParticipantIds in request body exceed %d items", breakoutParticipantsMax))
+		return
+	}
+
+	// find breakouts
+	breakouts, err := c.BreakoutCollection().FindAllByMeetingID(r.Context(), meeting.ID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var breakout *Breakout
+	for _, bo := range breakouts {
+		if string(bo.ID) == mux.Vars(r)["breakoutId"] {
+			breakout = bo
+		}
+	}
+	if breakout == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Breakout not found")
+		return
+	}
+
+	// find admitted participants, in the conference room or a breakout
+	admitted := make(map[ResourceID]bool)
+	// the conference room is not found when empty or closed
+	lp, err := c.LiveKitClient().ListParticipants(r.Context(), &livekit.ListParticipantsRequest{
+		Room: meeting.Code,
+	})
+	if err != nil && !client.IsLiveKitNotFound(err) {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, p := range lp.GetParticipants() {
+		admitted[ResourceID(p.Identity)] = true
+	}
+	for _, bo := range breakouts {
+		for _, id := range bo.ParticipantIDs {
+			admitted[id] = true
+		}
+	}
+
+	// ensure participants are admitted
+	participantIDs := make([]ResourceID, 0, len(b.ParticipantIDs))
+	seen := make(map[ResourceID]bool)
+	for _, id := range b.ParticipantIDs {
+		if !admitted[id] {
+			util.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Participant %q is not in the meeting", id))
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			participantIDs = append(participantIDs, id)
+		}
+	}
+
+	// assign participants
+	err = c.BreakoutCollection().Assign(r.Context(), breakout, participantIDs)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// notify participants to move, the assignment is saved so failures are
+	// logged rather than reported
+	err = c.notify(r.Context(), meeting, breakouts, NewBreakoutAssignedData(breakout))
+	if err != nil {
+		middleware.Logger(r.Context()).Warn("error notifying breakout assignment", "error", err)
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, breakout)
+}
+
+func (c *BreakoutController) BreakoutCloseHandler(w http.ResponseWriter, r *http.Request) {
+	// find meeting
+	meeting := c.findAdminMeeting(w, r)
+	if meeting == nil {
+		return
+	}
+
+	// find breakouts
+	breakouts, err := c.BreakoutCollection().FindAllByMeetingID(r.Context(), meeting.ID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// delete breakouts
+	err = c.BreakoutCollection().DeleteAllByMeetingID(r.Context(), meeting.ID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// notify participants to return, the breakouts are deleted so failures
+	// are logged rather than reported
+	err = c.notify(r.Context(), meeting, breakouts, NewBreakoutsClosedData())
+	if err != nil {
+		middleware.Logger(r.Context()).Warn("error notifying breakouts closed", "error", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BreakoutRoomTokenHandler exchanges a conference room token for a token of
+// the breakout the participant is assigned to, meeting admins may join any
+// breakout
+func (c *BreakoutController) BreakoutRoomTokenHandler(w http.ResponseWriter, r *http.Request) {
+	// decode room token
+	authClaims, err := verifyRoomToken(c.LiveKitConfig(), r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return
+	}
+
+	// find meeting
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	// ensure token is for the conference room
+	if authClaims.Video == nil || authClaims.Video.Room != meeting.Code {
+		util.WriteJSONError(w, http.StatusUnauthorized, "The authorized room does not match meeting")
+		return
+	}
+
+	// find breakout
+	breakouts, err := c.BreakoutCollection().FindAllByMeetingID(r.Context(), meeting.ID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var breakout *Breakout
+	for _, bo := range breakouts {
+		if string(bo.ID) == mux.Vars(r)["breakoutId"] {
+			breakout = bo
+		}
+	}
+	if breakout == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Breakout not found")
+		return
+	}
+
+	// ensure participant is assigned
+	if !authClaims.Video.RoomAdmin && !breakout.hasParticipant(ResourceID(authClaims.Identity)) {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Participant is not assigned to breakout")
+		return
+	}
+
+	// create response
+	roomToken, err := newBreakoutRoomToken(c.LiveKitConfig(), authClaims, breakout)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, roomToken)
+}
+
+func RegisterBreakoutRoutes(r *mux.Router, ds BreakoutDeps) *mux.Router {
+	c := NewBreakoutController(ds)
+
+	r.HandleFunc("/meetings/{meetingId}/breakouts", c.BreakoutSearchHandler).Methods(http.MethodGet)
+	r.HandleFunc("/meetings/{meetingId}/breakouts", c.BreakoutCreateHandler).Methods(http.MethodPost)
+	r.HandleFunc("/meetings/{meetingId}/breakouts:close", c.BreakoutCloseHandler).Methods(http.MethodPost)
+	r.HandleFunc("/meetings/{meetingId}/breakouts/{breakoutId}", c.BreakoutUpdateHandler).Methods(http.MethodPut)
+	r.HandleFunc("/meetings/{meetingId}/breakouts/{breakoutId}/roomTokens", c.BreakoutRoomTokenHandler).Methods(http.MethodPost)
+
+	return r
+}
@@ -0,0 +1,26 @@
+package resource
+
+import (
+	"testing"
+)
+
+func TestNewBreakoutRoomName(t *testing.T) {
+	t.Parallel()
+	meeting := &Meeting{Code: "abcd-efgh-ijkl"}
+
+	if name := newBreakoutRoomName(meeting, 2); name != "abcd-efgh-ijkl_breakout_2" {
+		t.Fatalf("Unexpected breakout room name: %q", name)
+	}
+}
+
+func TestBreakoutHasParticipant(t *testing.T) {
+	t.Parallel()
+	breakout := &Breakout{ParticipantIDs: []ResourceID{"some-id"}}
+
+	if !breakout.hasParticipant("some-id") {
+		t.Fatalf("Expected breakout to have participant")
+	}
+	if breakout.hasParticipant("other-id") {
+		t.Fatalf("Unexpected participant in breakout")
+	}
+}
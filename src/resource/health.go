@@ -0,0 +1,119 @@
+package resource
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/client"
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const (
+	HealthStatus_OK          HealthStatus = "ok"
+	HealthStatus_Unavailable HealthStatus = "unavailable"
+	healthCheckTimeout                    = 5 * time.Second
+)
+
+type HealthDeps interface {
+	client.MongoClientProvider
+	client.LiveKitClientProvider
+	client.GoogleOAuth2ClientProvider
+}
+
+type HealthStatus string
+
+type Health struct {
+	Status HealthStatus           `json:"status"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+// HealthCheck reports only the status, errors are logged so that internal
+// addresses and versions are not exposed
+type HealthCheck struct {
+	Status HealthStatus `json:"status"`
+}
+
+type healthCheck struct {
+	check    func(ctx context.Context) error
+	required bool // readiness fails when unavailable
+}
+
+type HealthController struct {
+	HealthDeps
+}
+
+func NewHealthController(ds HealthDeps) *HealthController {
+	return &HealthController{HealthDeps: ds}
+}
+
+// runs checks concurrently and reports unavailable if any required check
+// fails, google jwks are fetched again on verification so they do not gate
+// readiness
+func (c *HealthController) check(ctx context.Context) *Health {
+	checks := map[string]healthCheck{
+		"mongo": {required: true, check: func(ctx context.Context) error {
+			return c.MongoClient().Ping(ctx, readpref.Primary())
+		}},
+		"livekit":    {required: true, check: c.LiveKitClient().Ping},
+		"googleJwks": {check: c.GoogleOAuth2Client().EnsureKeySet},
+	}
+
+	health := &Health{Status: HealthStatus_OK, Checks: make(map[string]HealthCheck)}
+	mut := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check healthCheck) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			res := HealthCheck{Status: HealthStatus_OK}
+			if err := check.check(ctx); err != nil {
+				middleware.Logger(ctx).Warn("health check failed", "check", name, "required", check.required, "error", err)
+				res = HealthCheck{Status: HealthStatus_Unavailable}
+			}
+
+			mut.Lock()
+			defer mut.Unlock()
+			health.Checks[name] = res
+			if res.Status != HealthStatus_OK && check.required {
+				health.Status = HealthStatus_Unavailable
+			}
+		}(name, check)
+	}
+	wg.Wait()
+
+	return health
+}
+
+// HealthLiveHandler responds when the process is up, for liveness probes
+func (c *HealthController) HealthLiveHandler(w http.ResponseWriter, r *http.Request) {
+	util.WriteJSONResponse(w, http.StatusOK, &Health{Status: HealthStatus_OK})
+}
+
+// HealthReadyHandler responds when required dependencies are reachable, for
+// readiness probes
+func (c *HealthController) HealthReadyHandler(w http.ResponseWriter, r *http.Request) {
+	health := c.check(r.Context())
+	if health.Status != HealthStatus_OK {
+		util.WriteJSONResponse(w, http.StatusServiceUnavailable, health)
+		return
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, health)
+}
+
+func RegisterHealthRoutes(r *mux.Router, ds HealthDeps) *mux.Router {
+	c := NewHealthController(ds)
+
+	r.HandleFunc("/healthz", c.HealthLiveHandler).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", c.HealthReadyHandler).Methods(http.MethodGet)
+
+	return r
+}
@@ -0,0 +1,393 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/client"
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"github.com/aravindanve/livemeet-server/src/util"
+	"github.com/gorilla/mux"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	InvitationEmailCountMax = 50
+)
+
+type InvitationDeps interface {
+	config.AuthConfigProvider
+	config.InvitationConfigProvider
+	client.MailerProvider
+	UserCollectionProvider
+	MeetingCollectionProvider
+	InvitationCollectionProvider
+}
+
+type Invitation struct {
+	ID        ResourceID `json:"id" bson:"_id,omitempty"`
+	MeetingID ResourceID `json:"meetingId" bson:"meetingId"`
+	UserID    ResourceID `json:"userId" bson:"userId"`
+	Email     string     `json:"email" bson:"email"`
+	UsedAt    *time.Time `json:"usedAt" bson:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt" bson:"updatedAt"`
+	ExpiresAt time.Time  `json:"expiresAt" bson:"expiresAt"`
+}
+
+type InvitationClaims struct {
+	InvitationID ResourceID
+	MeetingID    ResourceID
+}
+
+func newInvitationToken(cf config.AuthConfig, icf config.InvitationConfig, invitation *Invitation) (string, error) {
+	// create invitation token
+	token, err := jwt.NewBuilder().
+		Issuer(icf.Issuer).
+		Subject(string(invitation.ID)).
+		Expiration(invitation.ExpiresAt).
+		Claim("meetingId", invitation.MeetingID).
+		Build()
+
+	if err != nil {
+		return "", err
+	}
+
+	// sign invitation token
+	signed, err := jwt.Sign(token, jwt.WithKey(cf.Algorithm, cf.Secret))
+	if err != nil {
+		return "", err
+	}
+
+	return string(signed), nil
+}
+
+func parseInvitationToken(cf config.AuthConfig, icf config.InvitationConfig, signed string) (*InvitationClaims, error) {
+	// parse and verify jwt
+	opts := []jwt.ParseOption{jwt.WithIssuer(icf.Issuer)}
+	for _, secret := range cf.Secrets() {
+		opts = append(opts, jwt.WithKey(cf.Algorithm, secret))
+	}
+	token, err := jwt.Parse([]byte(signed), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	meetingID, _ := token.PrivateClaims()["meetingId"].(string)
+	if token.Subject() == "" || meetingID == "" {
+		return nil, fmt.Errorf("missing claims in invitation token")
+	}
+
+	return &InvitationClaims{
+		InvitationID: ResourceID(token.Subject()),
+		MeetingID:    ResourceID(meetingID),
+	}, nil
+}
+
+func newInvitationLink(icf config.InvitationConfig, meeting *Meeting, token string) string {
+	return strings.TrimRight(icf.LinkURL, "/") + "/" + meeting.Code + "?invitation=" + url.QueryEscape(token)
+}
+
+func newInvitationMail(invitation *Invitation, meeting *Meeting, inviter *User, link string) *client.Mail {
+	name := "Someone"
+	if inviter != nil {
+		name = inviter.Name
+	}
+
+	return &client.Mail{
+		To:      []string{invitation.Email},
+		Subject: fmt.Sprintf("%s invited you to a meeting", name),
+		Text: fmt.Sprintf(
+			"%s invited you to join a meeting.\n\n"+
+				"Join the meeting: %s\n\n"+
+				"Meeting code: %s\n"+
+				"This link can be used once and expires on %s.\n",
+			name, link, meeting.Code, invitation.ExpiresAt.UTC().Format(time.RFC1123),
+		),
+	}
+}
+
+type InvitationCollectionProvider interface {
+	InvitationCollection() *InvitationCollection
+}
+
+type InvitationCollection struct {
+	collection *mongo.Collection
+}
+
+func NewInvitationCollection(db *mongo.Database) *InvitationCollection {
+	collection := db.Collection("invitation")
+
+	return &InvitationCollection{collection: collection}
+}
+
+// EnsureIndexes creates the indexes of the collection
+func (c *InvitationCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "InvitationCollection.EnsureIndexes")
+	defer span.End()
+
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}, {
+		Keys: bson.D{{Key: "meetingId", Value: 1}},
+	}})
+	return err
+}
+
+func (c *InvitationCollection) FindOneByID(
+	ctx context.Context, id ResourceID,
+) (*Invitation, error) {
+	ctx, span := tracing.Start(ctx, "InvitationCollection.FindOneByID")
+	defer span.End()
+
+	_id, err := id.ObjectID()
+	if err != nil {
+		return nil, err
+	}
+
+	var invitation Invitation
+	err = c.collection.FindOne(ctx, bson.D{
+		{Key: "_id", Value: _id},
+	}).Decode(&invitation)
+
+	if err != nil && err == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// UseOneByID atomically marks an unused and unexpired invitation as used,
+// returns nil if no such invitation exists
+func (c *InvitationCollection) UseOneByID(
+	ctx context.Context, id ResourceID,
+) (*Invitation, error) {
+	ctx, span := tracing.Start(ctx, "InvitationCollection.UseOneByID")
+	defer span.End()
+
+	_id, err := id.ObjectID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var invitation Invitation
+	err = c.collection.FindOneAndUpdate(ctx, bson.D{
+		{Key: "_id", Value: _id},
+		{Key: "usedAt", Value: nil},
+		{Key: "expiresAt", Value: bson.D{{Key: "$gt", Value: now}}},
+	}, bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "usedAt", Value: now},
+			{Key: "updatedAt", Value: now},
+		}},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&invitation)
+
+	if err != nil && err == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+func (c *InvitationCollection) Save(
+	ctx context.Context, invitation *Invitation,
+) error {
+	ctx, span := tracing.Start(ctx, "InvitationCollection.Save")
+	defer span.End()
+
+	if invitation.ID == "" {
+		now := time.Now()
+		invitation.CreatedAt = now
+		invitation.UpdatedAt = now
+
+		r, err := c.collection.InsertOne(ctx, invitation)
+		if err != nil {
+			return err
+		}
+		invitation.ID = ResourceIDFromObjectID(r.InsertedID.(primitive.ObjectID))
+		return nil
+	} else {
+		_id, err := invitation.ID.ObjectID()
+		if err != nil {
+			return err
+		}
+
+		invitation.UpdatedAt = time.Now()
+
+		_, err = c.collection.UpdateOne(ctx, bson.D{
+			{Key: "_id", Value: _id},
+		}, bson.D{
+			{Key: "$set", Value: invitation},
+		})
+		return err
+	}
+}
+
+type InvitationController struct {
+	InvitationDeps
+}
+
+func NewInvitationController(ds InvitationDeps) *InvitationController {
+	return &InvitationController{InvitationDeps: ds}
+}
+
+type InvitationCreateBody struct {
+	Emails []string `json:"emails"`
+}
+
+// InvitationFailure is a recipient that could not be invited
+type InvitationFailure struct {
+	Email string `json:"email"`
+	Error string `json:"error"`
+}
+
+func (c *InvitationController) InvitationCreateHandler(w http.ResponseWriter, r *http.Request) {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return
+	}
+
+	// find one meeting by id
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	// ensure auth user is the meeting admin
+	if auth.UserID != string(meeting.UserID) {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Only meeting admins can invite participants")
+		return
+	}
+
+	// decode body
+	b := &InvitationCreateBody{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(b.Emails) == 0 {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing emails in request body")
+		return
+	}
+	if len(b.Emails) > InvitationEmailCountMax {
+		util.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Emails in request body exceed %d items", InvitationEmailCountMax))
+		return
+	}
+
+	// get unique emails
+	var emails []string
+	seen := map[string]bool{}
+	for _, e := range b.Emails {
+		a, err := mail.ParseAddress(e)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Unexpected email %q in request body", e))
+			return
+		}
+		email := NormalizeUserEmail(a.Address)
+		if !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+
+	// find inviter
+	user, err := c.UserCollection().FindOneByID(r.Context(), ResourceID(auth.UserID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// get expiry
+	expiresAt := time.Now().Add(c.InvitationConfig().TTL)
+	if meeting.ExpiresAt != nil && meeting.ExpiresAt.Before(expiresAt) {
+		expiresAt = *meeting.ExpiresAt
+	}
+
+	// send each invitation separately so that one failed recipient does not
+	// hide the invitations already sent to others
+	invitations := []*Invitation{}
+	failed := []*InvitationFailure{}
+	for _, email := range emails {
+		// create invitation
+		invitation := &Invitation{
+			MeetingID: meeting.ID,
+			UserID:    ResourceID(auth.UserID),
+			Email:     email,
+			ExpiresAt: expiresAt,
+		}
+
+		// save invitation
+		err = c.InvitationCollection().Save(r.Context(), invitation)
+		if err != nil {
+			failed = append(failed, &InvitationFailure{Email: email, Error: err.Error()})
+			continue
+		}
+
+		// create invitation token
+		token, err := newInvitationToken(c.AuthConfig(), c.InvitationConfig(), invitation)
+		if err != nil {
+			failed = append(failed, &InvitationFailure{Email: email, Error: err.Error()})
+			continue
+		}
+
+		// send invitation mail
+		link := newInvitationLink(c.InvitationConfig(), meeting, token)
+		err = c.Mailer().Send(r.Context(), newInvitationMail(invitation, meeting, user, link))
+		if err != nil {
+			middleware.Logger(r.Context()).Warn("error sending invitation", "invitationId", invitation.ID, "error", err)
+			failed = append(failed, &InvitationFailure{Email: email, Error: "Unable to send invitation"})
+			continue
+		}
+
+		invitations = append(invitations, invitation)
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, map[string]any{
+		"invitations": invitations,
+		"failed":      failed,
+	})
+}
+
+func RegisterInvitationRoutes(r *mux.Router, ds InvitationDeps) *mux.Router {
+	c := NewInvitationController(ds)
+
+	r.HandleFunc("/meetings/{meetingId}/invitations", c.InvitationCreateHandler).Methods(http.MethodPost)
+
+	return r
+}
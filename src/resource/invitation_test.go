@@ -0,0 +1,175 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newInvitationAndJSON() (Invitation, []byte) {
+	var t, _ = time.Parse(time.RFC3339, "2022-01-01T00:00:00.000Z")
+	var m = Invitation{
+		ID:        "some-id",
+		MeetingID: "some-id",
+		UserID:    "some-id",
+		Email:     "user@example.com",
+		UsedAt:    &t,
+		CreatedAt: t,
+		UpdatedAt: t,
+		ExpiresAt: t,
+	}
+
+	var j = []byte(`{"id":"some-id","meetingId":"some-id","userId":"some-id",` +
+		`"email":"user@example.com","usedAt":"2022-01-01T00:00:00Z",` +
+		`"createdAt":"2022-01-01T00:00:00Z","updatedAt":"2022-01-01T00:00:00Z",` +
+		`"expiresAt":"2022-01-01T00:00:00Z"}`)
+
+	return m, j
+}
+
+func TestInvitationMarshalJSON(t *testing.T) {
+	t.Parallel()
+	m, j := newInvitationAndJSON()
+
+	value, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Error marshalling json: %#v", err)
+	}
+	if !bytes.Equal(j, value) {
+		t.Fatalf("Unexpected marshalled json: %#v", string(value))
+	}
+}
+
+func TestInvitationUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+	m, j := newInvitationAndJSON()
+
+	var value Invitation
+	err := json.Unmarshal([]byte(j), &value)
+	if err != nil {
+		t.Fatalf("Error unmarshalling json: %#v", err)
+	}
+	if !reflect.DeepEqual(m, value) {
+		t.Fatalf("Unexpected unmarshalled json: %#v", value)
+	}
+}
+
+func newInvitationAndBSON() (Invitation, []byte) {
+	var o = primitive.NewObjectID()
+	var t, _ = time.Parse(time.RFC3339, "2022-01-01T00:00:00.000Z")
+	var m = Invitation{
+		ID:        ResourceIDFromObjectID(o),
+		MeetingID: ResourceIDFromObjectID(o),
+		UserID:    ResourceIDFromObjectID(o),
+		Email:     "user@example.com",
+		UsedAt:    nil,
+		CreatedAt: t,
+		UpdatedAt: t,
+		ExpiresAt: t,
+	}
+
+	var d = primitive.NewDateTimeFromTime(t)
+	var b, _ = bson.Marshal(bson.D{
+		{Key: "_id", Value: o},
+		{Key: "meetingId", Value: o},
+		{Key: "userId", Value: o},
+		{Key: "email", Value: "user@example.com"},
+		{Key: "usedAt", Value: nil},
+		{Key: "createdAt", Value: d},
+		{Key: "updatedAt", Value: d},
+		{Key: "expiresAt", Value: d},
+	})
+
+	return m, b
+}
+
+func TestInvitationMarshalBSON(t *testing.T) {
+	t.Parallel()
+	m, b := newInvitationAndBSON()
+
+	value, err := bson.Marshal(m)
+	if err != nil {
+		t.Fatalf("Error marshalling bson: %#v", err)
+	}
+	if !bytes.Equal(b, value) {
+		t.Fatalf("Unexpected marshalled bson: %#v", string(value))
+	}
+}
+
+func TestInvitationUnmarshalBSON(t *testing.T) {
+	t.Parallel()
+	m, b := newInvitationAndBSON()
+
+	var value Invitation
+	err := bson.Unmarshal(b, &value)
+	if err != nil {
+		t.Fatalf("Error unmarshalling bson: %#v", err)
+	}
+	if !reflect.DeepEqual(m, value) {
+		t.Fatalf("Unexpected unmarshalled bson: %#v", value)
+	}
+}
+
+func TestInvitationToken(t *testing.T) {
+	t.Parallel()
+	p := config.NewConfig()
+	cf := p.AuthConfig()
+	icf := p.InvitationConfig()
+	invitation := &Invitation{
+		ID:        ResourceIDFromObjectID(primitive.NewObjectID()),
+		MeetingID: ResourceIDFromObjectID(primitive.NewObjectID()),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	signed, err := newInvitationToken(cf, icf, invitation)
+	if err != nil {
+		t.Fatalf("Error creating token: %#v", err)
+	}
+
+	claims, err := parseInvitationToken(cf, icf, signed)
+	if err != nil {
+		t.Fatalf("Error parsing token: %#v", err)
+	}
+	if claims.InvitationID != invitation.ID || claims.MeetingID != invitation.MeetingID {
+		t.Fatalf("Unexpected claims: %#v", claims)
+	}
+
+	// tokens from other issuers must not be accepted
+	other := icf
+	other.Issuer = cf.Issuer
+	if _, err := parseInvitationToken(cf, icf, mustSignInvitationToken(t, cf, other, invitation)); err == nil {
+		t.Fatalf("Expected error parsing token with unexpected issuer")
+	}
+
+	// tokens signed with the previous secret are accepted until it expires
+	rotated := cf
+	rotated.Secret = []byte("rotated")
+	rotated.PreviousSecret, rotated.PreviousSecretExpiresAt = cf.Secret, time.Now().Add(time.Hour)
+	if _, err := parseInvitationToken(rotated, icf, signed); err != nil {
+		t.Fatalf("Error parsing token signed with previous secret: %#v", err)
+	}
+	rotated.PreviousSecretExpiresAt = time.Now().Add(-time.Second)
+	if _, err := parseInvitationToken(rotated, icf, signed); err == nil {
+		t.Fatalf("Expected error parsing token signed with expired previous secret")
+	}
+
+	// expired tokens must not be accepted
+	invitation.ExpiresAt = time.Now().Add(-time.Hour)
+	if _, err := parseInvitationToken(cf, icf, mustSignInvitationToken(t, cf, icf, invitation)); err == nil {
+		t.Fatalf("Expected error parsing expired token")
+	}
+}
+
+func mustSignInvitationToken(t *testing.T, cf config.AuthConfig, icf config.InvitationConfig, invitation *Invitation) string {
+	signed, err := newInvitationToken(cf, icf, invitation)
+	if err != nil {
+		t.Fatalf("Error creating token: %#v", err)
+	}
+	return signed
+}
@@ -0,0 +1,133 @@
+package resource
+
+import (
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/util"
+)
+
+// DataVersion is incremented on breaking changes to data messages, fields may
+// be added to messages without a new version
+const DataVersion = 1
+
+const (
+	DataType_ParticipantAdmitted DataType = "participantAdmitted"
+	DataType_ParticipantDenied   DataType = "participantDenied"
+	DataType_BreakoutAssigned    DataType = "breakoutAssigned"
+	DataType_BreakoutsClosed     DataType = "breakoutsClosed"
+	DataType_Message             DataType = "message"
+)
+
+// DataType identifies a data message sent to livekit rooms
+type DataType string
+
+// DataHeader is included in every data message
+type DataHeader struct {
+	Type    DataType `json:"type"`
+	Version int      `json:"version"`
+}
+
+func newDataHeader(t DataType) DataHeader {
+	return DataHeader{Type: t, Version: DataVersion}
+}
+
+// ParticipantAdmittedData is sent to the waiting room when a participant is
+// admitted
+type ParticipantAdmittedData struct {
+	DataHeader
+	ID ResourceID `json:"id"`
+}
+
+func NewParticipantAdmittedData(id ResourceID) *ParticipantAdmittedData {
+	return &ParticipantAdmittedData{DataHeader: newDataHeader(DataType_ParticipantAdmitted), ID: id}
+}
+
+// ParticipantDeniedData is sent to the waiting room when a participant is
+// denied
+type ParticipantDeniedData struct {
+	DataHeader
+	ID      ResourceID `json:"id"`
+	Message *string    `json:"message,omitempty"`
+}
+
+func NewParticipantDeniedData(id ResourceID, message *string) *ParticipantDeniedData {
+	return &ParticipantDeniedData{DataHeader: newDataHeader(DataType_ParticipantDenied), ID: id, Message: message}
+}
+
+// BreakoutAssignedData is sent to the conference room and all breakout rooms
+// when participants are assigned to a breakout
+type BreakoutAssignedData struct {
+	DataHeader
+	BreakoutID     ResourceID   `json:"breakoutId"`
+	RoomName       string       `json:"roomName"`
+	ParticipantIDs []ResourceID `json:"participantIds"`
+}
+
+func NewBreakoutAssignedData(breakout *Breakout) *BreakoutAssignedData {
+	return &BreakoutAssignedData{
+		DataHeader:     newDataHeader(DataType_BreakoutAssigned),
+		BreakoutID:     breakout.ID,
+		RoomName:       breakout.RoomName,
+		ParticipantIDs: breakout.ParticipantIDs,
+	}
+}
+
+// BreakoutsClosedData is sent to the conference room and all breakout rooms
+// when breakouts are closed
+type BreakoutsClosedData struct {
+	DataHeader
+}
+
+func NewBreakoutsClosedData() *BreakoutsClosedData {
+	return &BreakoutsClosedData{DataHeader: newDataHeader(DataType_BreakoutsClosed)}
+}
+
+// MessageData is sent to a room when a chat message is created
+type MessageData struct {
+	DataHeader
+	Message *Message `json:"message"`
+}
+
+func NewMessageData(message *Message) *MessageData {
+	return &MessageData{DataHeader: newDataHeader(DataType_Message), Message: message}
+}
+
+// UnknownData is decoded from data messages of unknown types, such as those
+// added by newer servers
+type UnknownData struct {
+	DataHeader
+}
+
+// EncodeData encodes a data message with the encoding configured for livekit
+func EncodeData(cf config.LiveKitConfig, d any) ([]byte, error) {
+	return util.EncodeLiveKitData(cf.DataType, d)
+}
+
+// DecodeData decodes a data message of any encoding into its typed struct,
+// messages of unknown types are decoded to *UnknownData
+func DecodeData(b []byte) (any, error) {
+	var h DataHeader
+	if err := util.DecodeLiveKitData(b, &h); err != nil {
+		return nil, err
+	}
+
+	var d any
+	switch h.Type {
+	case DataType_ParticipantAdmitted:
+		d = &ParticipantAdmittedData{}
+	case DataType_ParticipantDenied:
+		d = &ParticipantDeniedData{}
+	case DataType_BreakoutAssigned:
+		d = &BreakoutAssignedData{}
+	case DataType_BreakoutsClosed:
+		d = &BreakoutsClosedData{}
+	case DataType_Message:
+		d = &MessageData{}
+	default:
+		return &UnknownData{DataHeader: h}, nil
+	}
+
+	if err := util.DecodeLiveKitData(b, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
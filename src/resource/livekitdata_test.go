@@ -0,0 +1,59 @@
+package resource
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/util"
+)
+
+func TestEncodeDecodeData(t *testing.T) {
+	t.Parallel()
+	message := &Message{
+		ID:        "62b3d5ec4f0d0de5a0b2c1d0",
+		MeetingID: "62b3d5ec4f0d0de5a0b2c1d1",
+		RoomName:  "abcd-efgh-ijkl",
+		Name:      "Jane",
+		Text:      "hello",
+		CreatedAt: time.Date(2022, 6, 23, 10, 0, 0, 123, time.UTC),
+		ExpiresAt: time.Date(2023, 6, 23, 10, 0, 0, 123, time.UTC),
+	}
+
+	for _, dataType := range []util.LiveKitDataType{util.LiveKitDataType_JSON, util.LiveKitDataType_CBOR} {
+		b, err := EncodeData(config.LiveKitConfig{DataType: dataType}, NewMessageData(message))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		d, err := DecodeData(b)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		data, ok := d.(*MessageData)
+		if !ok {
+			t.Fatalf("Unexpected data: %#v", d)
+		}
+		if data.Version != DataVersion || !reflect.DeepEqual(data.Message, message) {
+			t.Fatalf("Unexpected message data: %#v", data)
+		}
+	}
+}
+
+func TestDecodeDataUnknown(t *testing.T) {
+	t.Parallel()
+
+	b, err := util.EncodeLiveKitDataCBOR(map[string]any{"type": "somethingNew", "version": 2, "some": "field"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	d, err := DecodeData(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if data, ok := d.(*UnknownData); !ok || data.Type != "somethingNew" || data.Version != 2 {
+		t.Fatalf("Unexpected data: %#v", d)
+	}
+}
@@ -4,37 +4,264 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base32"
+	"encoding/json"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
-	"os"
+	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/event"
+	"github.com/aravindanve/livemeet-server/src/metrics"
 	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/tracing"
 	"github.com/aravindanve/livemeet-server/src/util"
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
 )
 
 const (
-	meetingTTL = 365 * 24 * time.Hour
+	MeetingCollectionName        = "meeting"
+	MeetingLobbyDomainsCountMax  = 20
+	MeetingPasscodeLengthMin     = 6
+	MeetingPasscodeLengthMax     = 64
+	MeetingCodeLengthMin         = 3
+	MeetingCodeLengthMax         = 32
+	MeetingRecurrenceDurationMax = 24 * 60 // minutes
+	meetingPersonalIndexName     = "userId_1"
 )
 
+var (
+	meetingCodeRegexp          = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	meetingGeneratedCodeRegexp = regexp.MustCompile(`^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{3,4}$`)
+	meetingReservedCodes       = map[string]bool{
+		"admin": true, "api": true, "app": true, "auth": true, "help": true,
+		"join": true, "login": true, "logout": true, "meet": true, "meeting": true,
+		"meetings": true, "new": true, "null": true, "participants": true,
+		"session": true, "settings": true, "signin": true, "signout": true,
+		"support": true, "undefined": true, "users": true, "waiting": true,
+		"webhooks": true,
+	}
+)
+
+const (
+	MeetingLobbyBypass_None     MeetingLobbyBypass = "none"
+	MeetingLobbyBypass_SignedIn MeetingLobbyBypass = "signedIn"
+	MeetingLobbyBypass_Domains  MeetingLobbyBypass = "domains"
+	MeetingLobbyBypass_Everyone MeetingLobbyBypass = "everyone"
+)
+
+type MeetingLobbyBypass string
+
 type MeetingDeps interface {
+	config.MeetingConfigProvider
 	MeetingCollectionProvider
 }
 
 type Meeting struct {
-	ID        ResourceID `json:"id" bson:"_id,omitempty"`
-	UserID    ResourceID `json:"userId" bson:"userId"`
-	Code      string     `json:"code" bson:"code"`
-	CreatedAt time.Time  `json:"createdAt" bson:"createdAt"`
-	UpdatedAt time.Time  `json:"updatedAt" bson:"updatedAt"`
-	ExpiresAt time.Time  `json:"expiresAt" bson:"expiresAt"`
+	ID           ResourceID         `json:"id" bson:"_id,omitempty"`
+	UserID       ResourceID         `json:"userId" bson:"userId"`
+	Code         string             `json:"code" bson:"code"`
+	Personal     bool               `json:"personal" bson:"personal"`
+	Lobby        MeetingLobby       `json:"lobby" bson:"lobby"`
+	HasPasscode  bool               `json:"hasPasscode" bson:"hasPasscode"`
+	PasscodeHash *string            `json:"-" bson:"passcodeHash"`
+	CreatedAt    time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time          `json:"updatedAt" bson:"updatedAt"`
+	Recurrence   *MeetingRecurrence `json:"recurrence" bson:"recurrence"`
+	ExpiresAt    *time.Time         `json:"expiresAt" bson:"expiresAt,omitempty"` // nil for personal meetings
+}
+
+// returns a random code in the format xxxx-xxxx-xxxx
+func newMeetingCode() (string, error) {
+	buf := make([]byte, 7)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))
+	return code[:4] + "-" + code[4:8] + "-" + code[8:], nil
+}
+
+// validates a personal meeting code chosen by a user
+func newPersonalMeetingCode(code string) (string, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if len(code) < MeetingCodeLengthMin || len(code) > MeetingCodeLengthMax {
+		return "", fmt.Errorf("Code in request body must be %d to %d characters", MeetingCodeLengthMin, MeetingCodeLengthMax)
+	}
+	if !meetingCodeRegexp.MatchString(code) {
+		return "", fmt.Errorf("Code in request body must contain only letters, numbers and single hyphens")
+	}
+	if meetingGeneratedCodeRegexp.MatchString(code) {
+		return "", fmt.Errorf("Code in request body must not look like a generated code")
+	}
+	if meetingReservedCodes[code] {
+		return "", fmt.Errorf("Code in request body is reserved")
+	}
+	return code, nil
+}
+
+// sets the hashed passcode, an empty passcode removes it
+func (m *Meeting) setPasscode(passcode string) error {
+	if passcode == "" {
+		m.HasPasscode = false
+		m.PasscodeHash = nil
+		return nil
+	}
+
+	n := utf8.RuneCountInString(passcode)
+	if n < MeetingPasscodeLengthMin || n > MeetingPasscodeLengthMax || len(passcode) > 72 {
+		return fmt.Errorf("Passcode in request body must be %d to %d characters", MeetingPasscodeLengthMin, MeetingPasscodeLengthMax)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(passcode), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	h := string(hash)
+	m.HasPasscode = true
+	m.PasscodeHash = &h
+	return nil
+}
+
+// reports whether passcode matches the meeting passcode
+func (m *Meeting) verifyPasscode(passcode string) bool {
+	if m.PasscodeHash == nil {
+		return true
+	}
+	return bcrypt.CompareHashAndPassword([]byte(*m.PasscodeHash), []byte(passcode)) == nil
+}
+
+// MeetingLobby defines who may skip the waiting room
+type MeetingLobby struct {
+	Bypass  MeetingLobbyBypass `json:"bypass" bson:"bypass"`
+	Domains []string           `json:"domains" bson:"domains"`
+}
+
+func newMeetingLobby(l *MeetingLobby) (MeetingLobby, error) {
+	lobby := MeetingLobby{Bypass: MeetingLobbyBypass_None, Domains: []string{}}
+	if l == nil {
+		return lobby, nil
+	}
+
+	switch l.Bypass {
+	case MeetingLobbyBypass_None, MeetingLobbyBypass_SignedIn, MeetingLobbyBypass_Everyone:
+		lobby.Bypass = l.Bypass
+	case MeetingLobbyBypass_Domains:
+		lobby.Bypass = l.Bypass
+		if len(l.Domains) == 0 {
+			return lobby, fmt.Errorf("Missing lobby domains in request body")
+		}
+		if len(l.Domains) > MeetingLobbyDomainsCountMax {
+			return lobby, fmt.Errorf("Lobby domains in request body exceed %d items", MeetingLobbyDomainsCountMax)
+		}
+		for _, d := range l.Domains {
+			d = strings.ToLower(strings.TrimSpace(d))
+			if d == "" || strings.ContainsAny(d, "@/ ") || !strings.Contains(d, ".") {
+				return lobby, fmt.Errorf("Unexpected lobby domain %q in request body", d)
+			}
+			lobby.Domains = append(lobby.Domains, d)
+		}
+	default:
+		return lobby, fmt.Errorf("Unexpected lobby bypass in request body")
+	}
+
+	return lobby, nil
+}
+
+// Bypasses reports whether user (nil if not signed in) may skip the waiting room
+func (l MeetingLobby) Bypasses(user *User) bool {
+	switch l.Bypass {
+	case MeetingLobbyBypass_Everyone:
+		return true
+	case MeetingLobbyBypass_SignedIn:
+		return user != nil
+	case MeetingLobbyBypass_Domains:
+		if user == nil || user.EmailDomain == "" {
+			return false
+		}
+		for _, d := range l.Domains {
+			if d == user.EmailDomain {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MeetingRecurrence schedules a meeting, all occurrences share the meeting
+// code and room
+type MeetingRecurrence struct {
+	Rule     string    `json:"rule" bson:"rule"`         // RFC 5545 RRULE subset, see util.RRule
+	Start    time.Time `json:"start" bson:"start"`       // first occurrence
+	Duration int       `json:"duration" bson:"duration"` // minutes
+	TimeZone string    `json:"timeZone" bson:"timeZone"` // IANA time zone of the wall clock time of occurrences
+}
+
+// validates a recurrence, an empty rule removes it
+func newMeetingRecurrence(rec *MeetingRecurrence) (*MeetingRecurrence, error) {
+	if rec.Rule == "" {
+		return nil, nil
+	}
+
+	_, err := util.ParseRRule(rec.Rule)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid recurrence rule in request body: %s", err.Error())
+	}
+	if rec.Start.IsZero() {
+		return nil, fmt.Errorf("Missing recurrence start in request body")
+	}
+	if rec.Duration < 1 || rec.Duration > MeetingRecurrenceDurationMax {
+		return nil, fmt.Errorf("Recurrence duration in request body must be 1 to %d minutes", MeetingRecurrenceDurationMax)
+	}
+
+	tz := rec.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return nil, fmt.Errorf("Unexpected recurrence time zone %q in request body", rec.TimeZone)
+	}
+
+	return &MeetingRecurrence{
+		Rule:     strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rec.Rule)), "RRULE:"),
+		Start:    rec.Start.UTC(),
+		Duration: rec.Duration,
+		TimeZone: tz,
+	}, nil
+}
+
+func (rec *MeetingRecurrence) duration() time.Duration {
+	return time.Duration(rec.Duration) * time.Minute
+}
+
+// returns the start of occurrences that overlap [from, to)
+func (rec *MeetingRecurrence) starts(from, to time.Time) ([]time.Time, error) {
+	rule, err := util.ParseRRule(rec.Rule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(rec.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	return rule.Between(rec.Start.In(loc), from.Add(-rec.duration()+time.Nanosecond), to), nil
+}
+
+// reports whether an occurrence starts at t
+func (rec *MeetingRecurrence) includes(t time.Time) bool {
+	starts, err := rec.starts(t.Add(rec.duration()-time.Nanosecond), t.Add(time.Nanosecond))
+	return err == nil && len(starts) == 1 && starts[0].Equal(t)
 }
 
 type MeetingCollectionProvider interface {
@@ -43,36 +270,54 @@ type MeetingCollectionProvider interface {
 
 type MeetingCollection struct {
 	collection *mongo.Collection
+	bus        event.Bus
+	cf         config.MeetingConfigProvider
 }
 
-func NewMeetingCollection(ctx context.Context, db *mongo.Database) *MeetingCollection {
-	collection := db.Collection("meeting")
+func NewMeetingCollection(db *mongo.Database, bus event.Bus, cf config.MeetingConfigProvider) *MeetingCollection {
+	collection := db.Collection(MeetingCollectionName)
 
-	// create indexes
-	go func() {
-		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
-			Keys:    bson.D{{Key: "code", Value: 1}},
-			Options: options.Index().SetUnique(true),
-		})
+	return &MeetingCollection{collection: collection, bus: bus, cf: cf}
+}
 
-		if err != nil {
-			msg := fmt.Sprintf("error creating mongo indexes: %s", err.Error())
-			if os.Getenv("APP_ENV") == "testing" {
-				log.Println(msg) // do not panic in tests
-			} else {
-				panic(msg)
-			}
-		}
-	}()
+// EnsureIndexes creates the indexes of the collection
+func (c *MeetingCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "MeetingCollection.EnsureIndexes")
+	defer span.End()
+
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}, {
+		// index for expire, personal meetings have no expiresAt
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}, {
+		// index for one personal meeting per user
+		Keys: bson.D{{Key: "userId", Value: 1}},
+		Options: options.Index().
+			SetName(meetingPersonalIndexName).
+			SetUnique(true).
+			SetPartialFilterExpression(bson.D{{Key: "personal", Value: true}}),
+	}})
+	return err
+}
 
-	return &MeetingCollection{collection: collection}
+// reports whether err is a duplicate key error of the one personal meeting per
+// user index rather than of the code index
+func isPersonalMeetingDuplicateKeyError(err error) bool {
+	return mongo.IsDuplicateKeyError(err) && strings.Contains(err.Error(), "index: "+meetingPersonalIndexName+" ")
 }
 
 func (c *MeetingCollection) FindAnyByCode(
 	ctx context.Context, code string,
 ) ([]*Meeting, error) {
+	ctx, span := tracing.Start(ctx, "MeetingCollection.FindAnyByCode")
+	defer span.End()
+
 	cur, err := c.collection.Find(ctx, bson.D{
 		{Key: "code", Value: code},
+		notExpired(),
 	}, options.Find().SetLimit(1))
 	if err != nil {
 		return nil, err
@@ -87,9 +332,34 @@ func (c *MeetingCollection) FindAnyByCode(
 	return meetings, nil
 }
 
+func (c *MeetingCollection) FindOnePersonalByUserID(
+	ctx context.Context, userID ResourceID,
+) (*Meeting, error) {
+	ctx, span := tracing.Start(ctx, "MeetingCollection.FindOnePersonalByUserID")
+	defer span.End()
+
+	var meeting Meeting
+	err := c.collection.FindOne(ctx, bson.D{
+		{Key: "userId", Value: userID},
+		{Key: "personal", Value: true},
+		notExpired(),
+	}).Decode(&meeting)
+
+	if err != nil && err == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &meeting, nil
+}
+
 func (c *MeetingCollection) FindOneByID(
 	ctx context.Context, id ResourceID,
 ) (*Meeting, error) {
+	ctx, span := tracing.Start(ctx, "MeetingCollection.FindOneByID")
+	defer span.End()
+
 	_id, err := id.ObjectID()
 	if err != nil {
 		return nil, err
@@ -98,6 +368,7 @@ func (c *MeetingCollection) FindOneByID(
 	var meeting Meeting
 	err = c.collection.FindOne(ctx, bson.D{
 		{Key: "_id", Value: _id},
+		notExpired(),
 	}).Decode(&meeting)
 
 	if err != nil && err == mongo.ErrNoDocuments {
@@ -109,9 +380,45 @@ func (c *MeetingCollection) FindOneByID(
 	return &meeting, nil
 }
 
+// Refresh extends the expiry of a meeting in use, never shortens it and
+// leaves meetings without expiry untouched
+func (c *MeetingCollection) Refresh(
+	ctx context.Context, meeting *Meeting,
+) error {
+	ctx, span := tracing.Start(ctx, "MeetingCollection.Refresh")
+	defer span.End()
+
+	cf := c.cf.MeetingConfig()
+	if meeting.ExpiresAt == nil || time.Until(*meeting.ExpiresAt) > cf.TTL-cf.RefreshInterval {
+		return nil
+	}
+
+	_id, err := meeting.ID.ObjectID()
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(cf.TTL)
+	_, err = c.collection.UpdateOne(ctx, bson.D{
+		{Key: "_id", Value: _id},
+		{Key: "expiresAt", Value: bson.D{{Key: "$exists", Value: true}}},
+	}, bson.D{
+		{Key: "$max", Value: bson.D{{Key: "expiresAt", Value: expiresAt}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	meeting.ExpiresAt = &expiresAt
+	return nil
+}
+
 func (c *MeetingCollection) Save(
 	ctx context.Context, meeting *Meeting,
 ) error {
+	ctx, span := tracing.Start(ctx, "MeetingCollection.Save")
+	defer span.End()
+
 	if meeting.ID == "" {
 		now := time.Now()
 		meeting.CreatedAt = now
@@ -122,7 +429,7 @@ func (c *MeetingCollection) Save(
 			return err
 		}
 		meeting.ID = ResourceIDFromObjectID(r.InsertedID.(primitive.ObjectID))
-		return nil
+		return c.publish(ctx, event.Operation_Insert, meeting)
 	} else {
 		_id, err := meeting.ID.ObjectID()
 		if err != nil {
@@ -136,8 +443,21 @@ func (c *MeetingCollection) Save(
 		}, bson.D{
 			{Key: "$set", Value: meeting},
 		})
+		if err != nil {
+			return err
+		}
+		return c.publish(ctx, event.Operation_Update, meeting)
+	}
+}
+
+func (c *MeetingCollection) publish(
+	ctx context.Context, operation event.Operation, meeting *Meeting,
+) error {
+	e, err := event.NewEvent(MeetingCollectionName, operation, string(meeting.ID), meeting)
+	if err != nil {
 		return err
 	}
+	return c.bus.Publish(ctx, e)
 }
 
 type MeetingController struct {
@@ -148,6 +468,13 @@ func NewMeetingController(ds MeetingDeps) *MeetingController {
 	return &MeetingController{MeetingDeps: ds}
 }
 
+type MeetingCreateBody struct {
+	Code       *string            `json:"code"`
+	Lobby      *MeetingLobby      `json:"lobby"`
+	Passcode   *string            `json:"passcode"`
+	Recurrence *MeetingRecurrence `json:"recurrence"`
+}
+
 func (c *MeetingController) MeetingCreateHandler(w http.ResponseWriter, r *http.Request) {
 	// decode auth token
 	auth, err := middleware.GetAuthToken(r)
@@ -160,37 +487,94 @@ func (c *MeetingController) MeetingCreateHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// create code
-	buf := make([]byte, 7)
-	_, err = rand.Read(buf)
-	if err != nil {
-		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+	// decode body
+	b := &MeetingCreateBody{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil && err != io.EOF {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))
-	code = code[:4] + "-" + code[4:8] + "-" + code[8:]
+	// get lobby
+	lobby, err := newMeetingLobby(b.Lobby)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// create meeting
 	meeting := &Meeting{
-		UserID:    ResourceID(auth.UserID),
-		Code:      code,
-		ExpiresAt: time.Now().Add(meetingTTL),
+		UserID: ResourceID(auth.UserID),
+		Lobby:  lobby,
+	}
+
+	if b.Code != nil {
+		// get personal code
+		meeting.Code, err = newPersonalMeetingCode(*b.Code)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		meeting.Personal = true
+
+		// ensure user has no personal meeting
+		personal, err := c.MeetingCollection().FindOnePersonalByUserID(r.Context(), meeting.UserID)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if personal != nil {
+			util.WriteJSONError(w, http.StatusConflict, "You already have a personal meeting")
+			return
+		}
+	} else {
+		// create code
+		meeting.Code, err = newMeetingCode()
+		if err != nil {
+			util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		expiresAt := time.Now().Add(c.MeetingConfig().TTL)
+		meeting.ExpiresAt = &expiresAt
+	}
+
+	// set passcode
+	if b.Passcode != nil {
+		err = meeting.setPasscode(*b.Passcode)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// set recurrence
+	if b.Recurrence != nil {
+		meeting.Recurrence, err = newMeetingRecurrence(b.Recurrence)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 	}
 
 	// save meeting
 	err = c.MeetingCollection().Save(r.Context(), meeting)
-	if err != nil {
+	if err != nil && isPersonalMeetingDuplicateKeyError(err) {
+		util.WriteJSONError(w, http.StatusConflict, "You already have a personal meeting")
+		return
+	} else if err != nil && mongo.IsDuplicateKeyError(err) {
+		util.WriteJSONError(w, http.StatusConflict, "Meeting code is already taken")
+		return
+	} else if err != nil {
 		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	metrics.MeetingsCreatedTotal.Inc()
 
 	util.WriteJSONResponse(w, http.StatusOK, meeting)
 }
 
 func (c *MeetingController) MeetingSearchHandler(w http.ResponseWriter, r *http.Request) {
 	// get meeting code
-	code := r.URL.Query().Get("code")
+	code := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("code")))
 	if code == "" {
 		util.WriteJSONError(w, http.StatusBadRequest, "Missing code in request query")
 		return
@@ -232,12 +616,157 @@ func (c *MeetingController) MeetingRetrieveHandler(w http.ResponseWriter, r *htt
 	util.WriteJSONResponse(w, http.StatusOK, meeting)
 }
 
+type MeetingUpdateBody struct {
+	Lobby      *MeetingLobby      `json:"lobby"`
+	Passcode   *string            `json:"passcode"`
+	Recurrence *MeetingRecurrence `json:"recurrence"`
+}
+
+func (c *MeetingController) MeetingUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return
+	}
+
+	// find one by id
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	// ensure auth user is the meeting admin
+	if auth.UserID != string(meeting.UserID) {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Only meeting admins can update meetings")
+		return
+	}
+
+	// decode body
+	b := &MeetingUpdateBody{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// update lobby
+	if b.Lobby != nil {
+		lobby, err := newMeetingLobby(b.Lobby)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		meeting.Lobby = lobby
+	}
+
+	// update passcode
+	if b.Passcode != nil {
+		err = meeting.setPasscode(*b.Passcode)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// update recurrence
+	if b.Recurrence != nil {
+		meeting.Recurrence, err = newMeetingRecurrence(b.Recurrence)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// save meeting
+	err = c.MeetingCollection().Save(r.Context(), meeting)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, meeting)
+}
+
+func (c *MeetingController) MeetingExtendHandler(w http.ResponseWriter, r *http.Request) {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return
+	}
+
+	// find one by id
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	// ensure auth user is the meeting admin
+	if auth.UserID != string(meeting.UserID) {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Only meeting admins can extend meetings")
+		return
+	}
+
+	// ensure meeting expires
+	if meeting.ExpiresAt == nil {
+		util.WriteJSONError(w, http.StatusBadRequest, "Personal meetings do not expire")
+		return
+	}
+
+	// extend meeting
+	expiresAt := time.Now().Add(c.MeetingConfig().TTL)
+	meeting.ExpiresAt = &expiresAt
+
+	// save meeting
+	err = c.MeetingCollection().Save(r.Context(), meeting)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, meeting)
+}
+
 func RegisterMeetingRoutes(r *mux.Router, ds MeetingDeps) *mux.Router {
 	c := NewMeetingController(ds)
 
 	r.HandleFunc("/meetings", c.MeetingSearchHandler).Methods(http.MethodGet).Queries("code", "{code}")
 	r.HandleFunc("/meetings", c.MeetingCreateHandler).Methods(http.MethodPost)
+	r.HandleFunc("/meetings/{meetingId}:extend", c.MeetingExtendHandler).Methods(http.MethodPost)
 	r.HandleFunc("/meetings/{meetingId}", c.MeetingRetrieveHandler).Methods(http.MethodGet)
+	r.HandleFunc("/meetings/{meetingId}", c.MeetingUpdateHandler).Methods(http.MethodPut)
 
 	return r
 }
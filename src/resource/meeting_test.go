@@ -3,26 +3,40 @@ package resource
 import (
 	"bytes"
 	"encoding/json"
+	"reflect"
 	"testing"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func newMeetingAndJSON() (Meeting, []byte) {
 	var t, _ = time.Parse(time.RFC3339, "2022-01-01T00:00:00.000Z")
 	var m = Meeting{
-		ID:        "some-id",
-		UserID:    "some-id",
-		Code:      "some-code",
+		ID:     "some-id",
+		UserID: "some-id",
+		Code:   "some-code",
+		Lobby: MeetingLobby{
+			Bypass:  MeetingLobbyBypass_Domains,
+			Domains: []string{"example.com"},
+		},
 		CreatedAt: t,
 		UpdatedAt: t,
-		ExpiresAt: t,
+		Recurrence: &MeetingRecurrence{
+			Rule:     "FREQ=WEEKLY;BYDAY=MO",
+			Start:    t,
+			Duration: 30,
+			TimeZone: "UTC",
+		},
+		ExpiresAt: &t,
 	}
 
-	var j = []byte(`{"id":"some-id","userId":"some-id","code":"some-code",` +
+	var j = []byte(`{"id":"some-id","userId":"some-id","code":"some-code","personal":false,` +
+		`"lobby":{"bypass":"domains","domains":["example.com"]},"hasPasscode":false,` +
 		`"createdAt":"2022-01-01T00:00:00Z","updatedAt":"2022-01-01T00:00:00Z",` +
+		`"recurrence":{"rule":"FREQ=WEEKLY;BYDAY=MO","start":"2022-01-01T00:00:00Z","duration":30,"timeZone":"UTC"},` +
 		`"expiresAt":"2022-01-01T00:00:00Z"}`)
 
 	return m, j
@@ -50,7 +64,7 @@ func TestMeetingUnmarshalJSON(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error unmarshalling json: %#v", err)
 	}
-	if m != value {
+	if !reflect.DeepEqual(m, value) {
 		t.Fatalf("Unexpected unmarshalled json: %#v", value)
 	}
 }
@@ -59,12 +73,16 @@ func newMeetingAndBSON() (Meeting, []byte) {
 	var o = primitive.NewObjectID()
 	var t, _ = time.Parse(time.RFC3339, "2022-01-01T00:00:00.000Z")
 	var m = Meeting{
-		ID:        ResourceIDFromObjectID(o),
-		UserID:    ResourceIDFromObjectID(o),
-		Code:      "some-code",
+		ID:     ResourceIDFromObjectID(o),
+		UserID: ResourceIDFromObjectID(o),
+		Code:   "some-code",
+		Lobby: MeetingLobby{
+			Bypass:  MeetingLobbyBypass_Domains,
+			Domains: []string{"example.com"},
+		},
 		CreatedAt: t,
 		UpdatedAt: t,
-		ExpiresAt: t,
+		ExpiresAt: &t,
 	}
 
 	var d = primitive.NewDateTimeFromTime(t)
@@ -72,8 +90,16 @@ func newMeetingAndBSON() (Meeting, []byte) {
 		{Key: "_id", Value: o},
 		{Key: "userId", Value: o},
 		{Key: "code", Value: "some-code"},
+		{Key: "personal", Value: false},
+		{Key: "lobby", Value: bson.D{
+			{Key: "bypass", Value: "domains"},
+			{Key: "domains", Value: bson.A{"example.com"}},
+		}},
+		{Key: "hasPasscode", Value: false},
+		{Key: "passcodeHash", Value: nil},
 		{Key: "createdAt", Value: d},
 		{Key: "updatedAt", Value: d},
+		{Key: "recurrence", Value: nil},
 		{Key: "expiresAt", Value: d},
 	})
 
@@ -102,7 +128,118 @@ func TestMeetingUnmarshalBSON(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error unmarshalling bson: %#v", err)
 	}
-	if m != value {
+	if !reflect.DeepEqual(m, value) {
 		t.Fatalf("Unexpected unmarshalled bson: %#v", value)
 	}
 }
+
+func TestMeetingLobbyBypasses(t *testing.T) {
+	t.Parallel()
+	user := &User{Email: "someone@example.com", EmailDomain: "example.com"}
+	other := &User{Email: "someone@other.com", EmailDomain: "other.com"}
+
+	tests := []struct {
+		lobby    MeetingLobby
+		user     *User
+		expected bool
+	}{
+		{MeetingLobby{Bypass: MeetingLobbyBypass_None}, user, false},
+		{MeetingLobby{}, user, false},
+		{MeetingLobby{Bypass: MeetingLobbyBypass_Everyone}, nil, true},
+		{MeetingLobby{Bypass: MeetingLobbyBypass_SignedIn}, nil, false},
+		{MeetingLobby{Bypass: MeetingLobbyBypass_SignedIn}, user, true},
+		{MeetingLobby{Bypass: MeetingLobbyBypass_Domains, Domains: []string{"example.com"}}, nil, false},
+		{MeetingLobby{Bypass: MeetingLobbyBypass_Domains, Domains: []string{"example.com"}}, user, true},
+		{MeetingLobby{Bypass: MeetingLobbyBypass_Domains, Domains: []string{"example.com"}}, other, false},
+		{MeetingLobby{Bypass: MeetingLobbyBypass_Domains, Domains: []string{"example.com"}}, &User{}, false},
+	}
+
+	for _, tt := range tests {
+		if v := tt.lobby.Bypasses(tt.user); v != tt.expected {
+			t.Errorf("expected %#v to bypass %#v to be %#v got %#v", tt.lobby, tt.user, tt.expected, v)
+		}
+	}
+}
+
+func TestNewMeetingLobby(t *testing.T) {
+	t.Parallel()
+
+	lobby, err := newMeetingLobby(nil)
+	if err != nil || lobby.Bypass != MeetingLobbyBypass_None {
+		t.Fatalf("Unexpected default lobby: %#v %#v", lobby, err)
+	}
+
+	lobby, err = newMeetingLobby(&MeetingLobby{Bypass: MeetingLobbyBypass_Domains, Domains: []string{" Example.COM "}})
+	if err != nil || !reflect.DeepEqual(lobby.Domains, []string{"example.com"}) {
+		t.Fatalf("Unexpected domains lobby: %#v %#v", lobby, err)
+	}
+
+	for _, l := range []MeetingLobby{
+		{Bypass: "unknown"},
+		{Bypass: MeetingLobbyBypass_Domains},
+		{Bypass: MeetingLobbyBypass_Domains, Domains: []string{"user@example.com"}},
+		{Bypass: MeetingLobbyBypass_Domains, Domains: []string{"localhost"}},
+	} {
+		if _, err := newMeetingLobby(&l); err == nil {
+			t.Errorf("expected error for %#v got nil", l)
+		}
+	}
+}
+
+func TestMeetingPasscode(t *testing.T) {
+	t.Parallel()
+	var m Meeting
+
+	if err := m.setPasscode("short"); err == nil {
+		t.Fatalf("Expected error setting short passcode")
+	}
+	if err := m.setPasscode("secret-passcode"); err != nil {
+		t.Fatalf("Error setting passcode: %#v", err)
+	}
+	if !m.HasPasscode || m.PasscodeHash == nil || *m.PasscodeHash == "secret-passcode" {
+		t.Fatalf("Unexpected passcode hash: %#v", m.PasscodeHash)
+	}
+	if !m.verifyPasscode("secret-passcode") {
+		t.Fatalf("Expected passcode to verify")
+	}
+	if m.verifyPasscode("wrong-passcode") {
+		t.Fatalf("Expected wrong passcode not to verify")
+	}
+	if err := m.setPasscode(""); err != nil || m.HasPasscode || m.PasscodeHash != nil {
+		t.Fatalf("Expected passcode to be removed: %#v", m)
+	}
+}
+
+func TestNewPersonalMeetingCode(t *testing.T) {
+	t.Parallel()
+
+	code, err := newPersonalMeetingCode(" Alice-Standup ")
+	if err != nil || code != "alice-standup" {
+		t.Fatalf("Unexpected personal code: %#v %#v", code, err)
+	}
+
+	generated, _ := newMeetingCode()
+	for _, c := range []string{"ab", "alice--standup", "-alice", "alice_standup", "admin", generated,
+		"abcdefghijklmnopqrstuvwxyz0123456789"} {
+		if _, err := newPersonalMeetingCode(c); err == nil {
+			t.Errorf("expected error for %q got nil", c)
+		}
+	}
+}
+
+func TestIsPersonalMeetingDuplicateKeyError(t *testing.T) {
+	t.Parallel()
+	newErr := func(index string) error {
+		return mongo.WriteException{WriteErrors: []mongo.WriteError{{
+			Code:    11000,
+			Message: "E11000 duplicate key error collection: livemeet.meeting index: " + index + " dup key: { : \"some-value\" }",
+		}}}
+	}
+
+	if !isPersonalMeetingDuplicateKeyError(newErr("userId_1")) {
+		t.Errorf("expected duplicate userId to be a personal meeting error")
+	}
+	if isPersonalMeetingDuplicateKeyError(newErr("code_1")) {
+		t.Errorf("expected duplicate code to not be a personal meeting error")
+	}
+}
@@ -0,0 +1,332 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aravindanve/livemeet-server/src/client"
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"github.com/aravindanve/livemeet-server/src/util"
+	"github.com/gorilla/mux"
+	"github.com/livekit/protocol/livekit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	MessageTextLengthMax = 2000
+	messageLimitDefault  = 50
+	messageLimitMax      = 200
+)
+
+type MessageDeps interface {
+	config.LiveKitConfigProvider
+	client.LiveKitClientProvider
+	MeetingCollectionProvider
+	MessageCollectionProvider
+}
+
+// Message is a chat message sent to the conference room or a breakout room
+type Message struct {
+	ID            ResourceID `json:"id" bson:"_id,omitempty"`
+	MeetingID     ResourceID `json:"meetingId" bson:"meetingId"`
+	RoomName      string     `json:"roomName" bson:"roomName"`
+	ParticipantID ResourceID `json:"participantId" bson:"participantId"`
+	Name          string     `json:"name" bson:"name"`
+	Text          string     `json:"text" bson:"text"`
+	CreatedAt     time.Time  `json:"createdAt" bson:"createdAt"`
+	ExpiresAt     time.Time  `json:"expiresAt" bson:"expiresAt"`
+}
+
+// reports whether room is the conference room or a breakout room of meeting
+func isMeetingRoom(meeting *Meeting, room string) bool {
+	return room == meeting.Code || strings.HasPrefix(room, meeting.Code+breakoutRoomSuffix)
+}
+
+type MessageCollectionProvider interface {
+	MessageCollection() *MessageCollection
+}
+
+type MessageCollection struct {
+	collection *mongo.Collection
+	cf         config.MessageConfigProvider
+}
+
+func NewMessageCollection(db *mongo.Database, cf config.MessageConfigProvider) *MessageCollection {
+	collection := db.Collection("message")
+
+	return &MessageCollection{collection: collection, cf: cf}
+}
+
+// EnsureIndexes creates the indexes of the collection
+func (c *MessageCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "MessageCollection.EnsureIndexes")
+	defer span.End()
+
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys: bson.D{{Key: "meetingId", Value: 1}, {Key: "roomName", Value: 1}, {Key: "_id", Value: -1}},
+	}, {
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}})
+	return err
+}
+
+// FindAllByRoom returns up to limit messages of a room sent before the message
+// with id before, or the latest messages if before is empty, oldest first
+func (c *MessageCollection) FindAllByRoom(
+	ctx context.Context, meetingID ResourceID, roomName string, before ResourceID, limit int,
+) ([]*Message, error) {
+	ctx, span := tracing.Start(ctx, "MessageCollection.FindAllByRoom")
+	defer span.End()
+
+	filter := bson.D{
+		{Key: "meetingId", Value: meetingID},
+		{Key: "roomName", Value: roomName},
+	}
+	if before != "" {
+		_before, err := before.ObjectID()
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$lt", Value: _before}}})
+	}
+
+	cur, err := c.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*Message, 0)
+	err = cur.All(ctx, &messages)
+	if err != nil {
+		return nil, err
+	}
+
+	// reverse to oldest first
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, nil
+}
+
+func (c *MessageCollection) Save(
+	ctx context.Context, message *Message,
+) error {
+	ctx, span := tracing.Start(ctx, "MessageCollection.Save")
+	defer span.End()
+
+	now := time.Now()
+	message.CreatedAt = now
+	message.ExpiresAt = now.Add(c.cf.MessageConfig().TTL)
+
+	r, err := c.collection.InsertOne(ctx, message)
+	if err != nil {
+		return err
+	}
+	message.ID = ResourceIDFromObjectID(r.InsertedID.(primitive.ObjectID))
+	return nil
+}
+
+type MessageController struct {
+	MessageDeps
+}
+
+func NewMessageController(ds MessageDeps) *MessageController {
+	return &MessageController{MessageDeps: ds}
+}
+
+type MessageCreateBody struct {
+	Text string `json:"text"`
+}
+
+func (c *MessageController) MessageCreateHandler(w http.ResponseWriter, r *http.Request) {
+	// decode room token
+	authClaims, err := verifyRoomToken(c.LiveKitConfig(), r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return
+	}
+
+	// find meeting
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	// ensure token is for a room of the meeting
+	if authClaims.Video == nil || !isMeetingRoom(meeting, authClaims.Video.Room) {
+		util.WriteJSONError(w, http.StatusUnauthorized, "The authorized room does not match meeting")
+		return
+	}
+
+	// decode body
+	b := &MessageCreateBody{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	text := strings.TrimSpace(b.Text)
+	if text == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing text in request body")
+		return
+	}
+	if utf8.RuneCountInString(text) > MessageTextLengthMax {
+		util.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Text in request body exceeds %d characters", MessageTextLengthMax))
+		return
+	}
+
+	// get name from metadata
+	var metadata ParticipantMetadata
+	if authClaims.Metadata != "" {
+		_ = json.Unmarshal([]byte(authClaims.Metadata), &metadata)
+	}
+
+	// save message
+	message := &Message{
+		MeetingID:     meeting.ID,
+		RoomName:      authClaims.Video.Room,
+		ParticipantID: ResourceID(authClaims.Identity),
+		Name:          metadata.Name,
+		Text:          text,
+	}
+	err = c.MessageCollection().Save(r.Context(), message)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// relay message to room, the message is saved and listed in the history
+	// so failures are logged rather than reported to avoid duplicate retries
+	data, err := EncodeData(c.LiveKitConfig(), NewMessageData(message))
+	if err == nil {
+		_, err = c.LiveKitClient().SendData(r.Context(), &livekit.SendDataRequest{
+			Room: message.RoomName,
+			Data: data,
+			Kind: livekit.DataPacket_RELIABLE,
+		})
+	}
+	if err != nil {
+		middleware.Logger(r.Context()).Warn("error relaying message", "messageId", message.ID, "error", err)
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, message)
+}
+
+// MessageSearchHandler returns the history of the room of a room token, or of
+// any room of the meeting for meeting admins
+func (c *MessageController) MessageSearchHandler(w http.ResponseWriter, r *http.Request) {
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return
+	}
+
+	// get pagination
+	limit := messageLimitDefault
+	if q := r.URL.Query().Get("limit"); q != "" {
+		n, err := strconv.Atoi(q)
+		if err != nil || n < 1 || n > messageLimitMax {
+			util.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Limit in request query must be 1 to %d", messageLimitMax))
+			return
+		}
+		limit = n
+	}
+	before := ResourceID(r.URL.Query().Get("before"))
+	if _, err := before.ObjectID(); before != "" && err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, "Invalid before in request query")
+		return
+	}
+
+	// find meeting
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	// get room from room token or from query for meeting admins
+	var room string
+	if authClaims, err := verifyRoomToken(c.LiveKitConfig(), r); err == nil {
+		if authClaims.Video == nil || !isMeetingRoom(meeting, authClaims.Video.Room) {
+			util.WriteJSONError(w, http.StatusUnauthorized, "The authorized room does not match meeting")
+			return
+		}
+		room = authClaims.Video.Room
+	} else {
+		auth, _ := middleware.GetAuthToken(r)
+		if auth == nil {
+			util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		if auth.UserID != string(meeting.UserID) {
+			util.WriteJSONError(w, http.StatusUnauthorized, "Only meeting admins can view messages without a room token")
+			return
+		}
+		room = meeting.Code
+		if q := r.URL.Query().Get("roomName"); q != "" {
+			if !isMeetingRoom(meeting, q) {
+				util.WriteJSONError(w, http.StatusBadRequest, "Unexpected roomName in request query")
+				return
+			}
+			room = q
+		}
+	}
+
+	// find messages
+	messages, err := c.MessageCollection().FindAllByRoom(r.Context(), meeting.ID, room, before, limit)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// older messages may exist if the page is full
+	var nextBefore *ResourceID
+	if len(messages) == limit {
+		nextBefore = &messages[0].ID
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, map[string]any{
+		"messages":   messages,
+		"nextBefore": nextBefore,
+	})
+}
+
+func RegisterMessageRoutes(r *mux.Router, ds MessageDeps) *mux.Router {
+	c := NewMessageController(ds)
+
+	r.HandleFunc("/meetings/{meetingId}/messages", c.MessageSearchHandler).Methods(http.MethodGet)
+	r.HandleFunc("/meetings/{meetingId}/messages", c.MessageCreateHandler).Methods(http.MethodPost)
+
+	return r
+}
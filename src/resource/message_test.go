@@ -0,0 +1,21 @@
+package resource
+
+import (
+	"testing"
+)
+
+func TestIsMeetingRoom(t *testing.T) {
+	t.Parallel()
+	meeting := &Meeting{Code: "abcd-efgh-ijkl"}
+
+	for room, expected := range map[string]bool{
+		"abcd-efgh-ijkl":            true,
+		"abcd-efgh-ijkl_breakout_2": true,
+		"abcd-efgh-ijkl_waiting":    false,
+		"mnop-qrst-uvwx":            false,
+	} {
+		if isMeetingRoom(meeting, room) != expected {
+			t.Fatalf("Expected meeting room %q to be %v", room, expected)
+		}
+	}
+}
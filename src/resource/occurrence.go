@@ -0,0 +1,418 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"github.com/aravindanve/livemeet-server/src/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	occurrenceIDLayout     = "20060102T150405Z" // RFC 5545 RECURRENCE-ID in utc
+	occurrenceRangeDefault = 30 * 24 * time.Hour
+	occurrenceRangeMax     = 366 * 24 * time.Hour
+	occurrenceJoinLead     = 15 * time.Minute
+)
+
+type OccurrenceDeps interface {
+	MeetingCollectionProvider
+	OccurrenceCollectionProvider
+}
+
+// Occurrence is a single instance of a recurring meeting, only overridden or
+// cancelled occurrences are stored
+type Occurrence struct {
+	ID            string     `json:"id" bson:"occurrenceId"`
+	MeetingID     ResourceID `json:"meetingId" bson:"meetingId"`
+	OriginalStart time.Time  `json:"originalStart" bson:"originalStart"`
+	Start         time.Time  `json:"start" bson:"start"`
+	End           time.Time  `json:"end" bson:"end"`
+	Cancelled     bool       `json:"cancelled" bson:"cancelled"`
+	Overridden    bool       `json:"overridden" bson:"overridden"`
+	ExpiresAt     time.Time  `json:"-" bson:"expiresAt"`
+}
+
+func newOccurrenceID(originalStart time.Time) string {
+	return originalStart.UTC().Format(occurrenceIDLayout)
+}
+
+// returns the occurrence of meeting starting at originalStart as scheduled
+func newOccurrence(meeting *Meeting, originalStart time.Time) *Occurrence {
+	return &Occurrence{
+		ID:            newOccurrenceID(originalStart),
+		MeetingID:     meeting.ID,
+		OriginalStart: originalStart.UTC(),
+		Start:         originalStart.UTC(),
+		End:           originalStart.Add(meeting.Recurrence.duration()).UTC(),
+	}
+}
+
+// returns the scheduled occurrence of meeting with id or nil if the meeting
+// has no such occurrence
+func newOccurrenceByID(meeting *Meeting, id string) *Occurrence {
+	if meeting.Recurrence == nil {
+		return nil
+	}
+	originalStart, err := time.Parse(occurrenceIDLayout, id)
+	if err != nil || !meeting.Recurrence.includes(originalStart) {
+		return nil
+	}
+	return newOccurrence(meeting, originalStart)
+}
+
+type OccurrenceCollectionProvider interface {
+	OccurrenceCollection() *OccurrenceCollection
+}
+
+type OccurrenceCollection struct {
+	collection *mongo.Collection
+	cf         config.OccurrenceConfigProvider
+}
+
+func NewOccurrenceCollection(db *mongo.Database, cf config.OccurrenceConfigProvider) *OccurrenceCollection {
+	collection := db.Collection("occurrence")
+
+	return &OccurrenceCollection{collection: collection, cf: cf}
+}
+
+// EnsureIndexes creates the indexes of the collection
+func (c *OccurrenceCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "OccurrenceCollection.EnsureIndexes")
+	defer span.End()
+
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "meetingId", Value: 1}, {Key: "occurrenceId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}, {
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}})
+	return err
+}
+
+// FindAllByMeetingBetween returns the occurrences of a recurring meeting that
+// overlap [from, to) in order of start, with overrides applied
+func (c *OccurrenceCollection) FindAllByMeetingBetween(
+	ctx context.Context, meeting *Meeting, from, to time.Time,
+) ([]*Occurrence, error) {
+	ctx, span := tracing.Start(ctx, "OccurrenceCollection.FindAllByMeetingBetween")
+	defer span.End()
+
+	occurrences := make([]*Occurrence, 0)
+	if meeting.Recurrence == nil {
+		return occurrences, nil
+	}
+
+	// find scheduled occurrences
+	starts, err := meeting.Recurrence.starts(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// find overrides that were or are now in range
+	cur, err := c.collection.Find(ctx, bson.D{
+		{Key: "meetingId", Value: meeting.ID},
+		{Key: "$or", Value: bson.A{
+			bson.D{{Key: "originalStart", Value: bson.D{
+				{Key: "$gt", Value: from.Add(-meeting.Recurrence.duration())},
+				{Key: "$lt", Value: to},
+			}}},
+			bson.D{
+				{Key: "start", Value: bson.D{{Key: "$lt", Value: to}}},
+				{Key: "end", Value: bson.D{{Key: "$gt", Value: from}}},
+			},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make([]*Occurrence, 0)
+	err = cur.All(ctx, &overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	// apply overrides, ignoring ones left over from a previous rule
+	overridden := make(map[string]bool, len(overrides))
+	for _, o := range overrides {
+		if !meeting.Recurrence.includes(o.OriginalStart) {
+			continue
+		}
+		overridden[o.ID] = true
+		if o.Start.Before(to) && o.End.After(from) {
+			occurrences = append(occurrences, o)
+		}
+	}
+	for _, t := range starts {
+		o := newOccurrence(meeting, t)
+		if !overridden[o.ID] {
+			occurrences = append(occurrences, o)
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+
+	return occurrences, nil
+}
+
+// FindCurrentByMeeting returns the occurrence in progress or starting soon at
+// now, or nil if there is none
+func (c *OccurrenceCollection) FindCurrentByMeeting(
+	ctx context.Context, meeting *Meeting, now time.Time,
+) (*Occurrence, error) {
+	ctx, span := tracing.Start(ctx, "OccurrenceCollection.FindCurrentByMeeting")
+	defer span.End()
+
+	occurrences, err := c.FindAllByMeetingBetween(ctx, meeting, now, now.Add(occurrenceJoinLead))
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range occurrences {
+		if !o.Cancelled {
+			return o, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *OccurrenceCollection) FindOneByMeetingIDAndID(
+	ctx context.Context, meetingID ResourceID, id string,
+) (*Occurrence, error) {
+	ctx, span := tracing.Start(ctx, "OccurrenceCollection.FindOneByMeetingIDAndID")
+	defer span.End()
+
+	var occurrence Occurrence
+	err := c.collection.FindOne(ctx, bson.D{
+		{Key: "meetingId", Value: meetingID},
+		{Key: "occurrenceId", Value: id},
+	}).Decode(&occurrence)
+
+	if err != nil && err == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &occurrence, nil
+}
+
+// Save stores the occurrence as an override
+func (c *OccurrenceCollection) Save(
+	ctx context.Context, occurrence *Occurrence,
+) error {
+	ctx, span := tracing.Start(ctx, "OccurrenceCollection.Save")
+	defer span.End()
+
+	occurrence.Overridden = true
+
+	// keep overrides for a while after they end
+	end := occurrence.End
+	if occurrence.OriginalStart.After(end) {
+		end = occurrence.OriginalStart
+	}
+	occurrence.ExpiresAt = end.Add(c.cf.OccurrenceConfig().TTL)
+
+	_, err := c.collection.UpdateOne(ctx, bson.D{
+		{Key: "meetingId", Value: occurrence.MeetingID},
+		{Key: "occurrenceId", Value: occurrence.ID},
+	}, bson.D{
+		{Key: "$set", Value: occurrence},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+type OccurrenceController struct {
+	OccurrenceDeps
+}
+
+func NewOccurrenceController(ds OccurrenceDeps) *OccurrenceController {
+	return &OccurrenceController{OccurrenceDeps: ds}
+}
+
+func (c *OccurrenceController) OccurrenceSearchHandler(w http.ResponseWriter, r *http.Request) {
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return
+	}
+
+	// get range
+	from, to := time.Now(), time.Time{}
+	if q := r.URL.Query().Get("from"); q != "" {
+		t, err := time.Parse(time.RFC3339, q)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusBadRequest, "Invalid from in request query")
+			return
+		}
+		from = t
+	}
+	if q := r.URL.Query().Get("to"); q != "" {
+		t, err := time.Parse(time.RFC3339, q)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusBadRequest, "Invalid to in request query")
+			return
+		}
+		to = t
+	} else {
+		to = from.Add(occurrenceRangeDefault)
+	}
+	if !to.After(from) {
+		util.WriteJSONError(w, http.StatusBadRequest, "Expected to after from in request query")
+		return
+	}
+	if to.Sub(from) > occurrenceRangeMax {
+		util.WriteJSONError(w, http.StatusBadRequest, "Range in request query must not exceed 366 days")
+		return
+	}
+
+	// find meeting
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+	if meeting.Recurrence == nil {
+		util.WriteJSONError(w, http.StatusBadRequest, "Meeting is not recurring")
+		return
+	}
+
+	// find occurrences
+	occurrences, err := c.OccurrenceCollection().FindAllByMeetingBetween(r.Context(), meeting, from, to)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := map[string]any{
+		"occurrences": occurrences,
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, res)
+}
+
+type OccurrenceUpdateBody struct {
+	Start     *time.Time `json:"start"`
+	End       *time.Time `json:"end"`
+	Cancelled *bool      `json:"cancelled"`
+}
+
+func (c *OccurrenceController) OccurrenceUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return
+	}
+
+	// get occurrence id
+	occurrenceID := mux.Vars(r)["occurrenceId"]
+	if occurrenceID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing occurrenceId in request path")
+		return
+	}
+
+	// find meeting
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	// ensure auth user is the meeting admin
+	if auth.UserID != string(meeting.UserID) {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Only meeting admins can update occurrences")
+		return
+	}
+
+	// find occurrence
+	occurrence, err := c.OccurrenceCollection().FindOneByMeetingIDAndID(r.Context(), meeting.ID, occurrenceID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if occurrence == nil {
+		occurrence = newOccurrenceByID(meeting, occurrenceID)
+	}
+	if occurrence == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Occurrence not found")
+		return
+	}
+
+	// decode body
+	b := &OccurrenceUpdateBody{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// update occurrence
+	if b.Start != nil {
+		occurrence.Start = b.Start.UTC()
+	}
+	if b.End != nil {
+		occurrence.End = b.End.UTC()
+	}
+	if b.Cancelled != nil {
+		occurrence.Cancelled = *b.Cancelled
+	}
+	if !occurrence.End.After(occurrence.Start) {
+		util.WriteJSONError(w, http.StatusBadRequest, "Expected end after start in request body")
+		return
+	}
+	if occurrence.End.Sub(occurrence.Start) > MeetingRecurrenceDurationMax*time.Minute {
+		util.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Occurrence in request body must not exceed %d minutes", MeetingRecurrenceDurationMax))
+		return
+	}
+
+	// save occurrence
+	err = c.OccurrenceCollection().Save(r.Context(), occurrence)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, occurrence)
+}
+
+func RegisterOccurrenceRoutes(r *mux.Router, ds OccurrenceDeps) *mux.Router {
+	c := NewOccurrenceController(ds)
+
+	r.HandleFunc("/meetings/{meetingId}/occurrences", c.OccurrenceSearchHandler).Methods(http.MethodGet)
+	r.HandleFunc("/meetings/{meetingId}/occurrences/{occurrenceId}", c.OccurrenceUpdateHandler).Methods(http.MethodPut)
+
+	return r
+}
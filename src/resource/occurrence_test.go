@@ -0,0 +1,33 @@
+package resource
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewOccurrenceByID(t *testing.T) {
+	t.Parallel()
+	meeting := &Meeting{
+		ID: "some-id",
+		Recurrence: &MeetingRecurrence{
+			Rule:     "FREQ=WEEKLY;BYDAY=MO,WE",
+			Start:    time.Date(2023, 1, 2, 14, 0, 0, 0, time.UTC),
+			Duration: 30,
+			TimeZone: "Asia/Kolkata",
+		},
+	}
+
+	o := newOccurrenceByID(meeting, "20230104T140000Z")
+	if o == nil {
+		t.Fatalf("Expected occurrence got nil")
+	}
+	if !o.End.Equal(time.Date(2023, 1, 4, 14, 30, 0, 0, time.UTC)) {
+		t.Fatalf("Unexpected occurrence end: %v", o.End)
+	}
+
+	for _, id := range []string{"20230103T140000Z", "20230104T150000Z", "20221228T140000Z", "some-id"} {
+		if o := newOccurrenceByID(meeting, id); o != nil {
+			t.Fatalf("Unexpected occurrence for id %q: %#v", id, o)
+		}
+	}
+}
@@ -3,13 +3,19 @@ package resource
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aravindanve/livemeet-server/src/client"
 	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/event"
+	"github.com/aravindanve/livemeet-server/src/metrics"
 	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/tracing"
 	"github.com/aravindanve/livemeet-server/src/util"
 	"github.com/gorilla/mux"
 	"github.com/livekit/protocol/auth"
@@ -21,8 +27,9 @@ import (
 )
 
 const (
-	participantTTL               = 30 * time.Minute
+	ParticipantCollectionName    = "participant"
 	participantWaitingRoomSuffix = "_waiting"
+	ParticipantMessageLengthMax  = 200
 )
 
 type ParticipantStatus string
@@ -38,25 +45,37 @@ type RoomType string
 const (
 	RoomType_Waiting    RoomType = "waiting"
 	RoomType_Conference RoomType = "conference"
+	RoomType_Breakout   RoomType = "breakout"
 )
 
 type ParticipantDeps interface {
+	config.AuthConfigProvider
+	config.InvitationConfigProvider
 	config.LiveKitConfigProvider
+	config.ParticipantConfigProvider
+	config.RateLimitConfigProvider
 	client.LiveKitClientProvider
+	middleware.RateLimitStoreProvider
 	UserCollectionProvider
 	MeetingCollectionProvider
 	ParticipantCollectionProvider
+	InvitationCollectionProvider
+	OccurrenceCollectionProvider
+	AttendanceCollectionProvider
 }
 
 type Participant struct {
-	ID        ResourceID        `json:"id" bson:"_id,omitempty"`
-	MeetingID ResourceID        `json:"meetingId" bson:"meetingId"`
-	Name      string            `json:"name" bson:"name"`
-	ImageURL  *string           `json:"imageUrl" bson:"imageUrl"`
-	Status    ParticipantStatus `json:"status" bson:"status"`
-	CreatedAt time.Time         `json:"createdAt" bson:"createdAt"`
-	UpdatedAt time.Time         `json:"updatedAt" bson:"updatedAt"`
-	ExpiresAt time.Time         `json:"expiresAt" bson:"expiresAt"`
+	ID           ResourceID        `json:"id" bson:"_id,omitempty"`
+	MeetingID    ResourceID        `json:"meetingId" bson:"meetingId"`
+	OccurrenceID *string           `json:"occurrenceId" bson:"occurrenceId"` // occurrence of a recurring meeting
+	UserID       *ResourceID       `json:"userId" bson:"userId,omitempty"`   // nil for guests
+	Name         string            `json:"name" bson:"name"`
+	ImageURL     *string           `json:"imageUrl" bson:"imageUrl"`
+	Status       ParticipantStatus `json:"status" bson:"status"`
+	Message      *string           `json:"message" bson:"message"`
+	CreatedAt    time.Time         `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time         `json:"updatedAt" bson:"updatedAt"`
+	ExpiresAt    time.Time         `json:"expiresAt" bson:"expiresAt"`
 }
 
 type ParticipantWithRoomTokens struct {
@@ -74,6 +93,7 @@ type RoomToken struct {
 type ParticipantMetadata struct {
 	Name     string  `json:"name"`
 	ImageURL *string `json:"imageUrl"`
+	Message  *string `json:"message"`
 }
 
 func newParticipantWithRoomTokens(cf config.LiveKitConfig, participant *Participant, room string, roomAdmin bool) (*ParticipantWithRoomTokens, error) {
@@ -92,6 +112,7 @@ func newParticipantWithRoomTokens(cf config.LiveKitConfig, participant *Particip
 			CanPublishData: &tr,
 			CanSubscribe:   &tr,
 		}
+		// the message is only for admins in the waiting room
 		metadata, err := json.Marshal(ParticipantMetadata{
 			Name:     participant.Name,
 			ImageURL: participant.ImageURL,
@@ -135,6 +156,7 @@ func newParticipantWithRoomTokens(cf config.LiveKitConfig, participant *Particip
 		metadata, err := json.Marshal(ParticipantMetadata{
 			Name:     participant.Name,
 			ImageURL: participant.ImageURL,
+			Message:  participant.Message,
 		})
 		if err != nil {
 			return nil, err
@@ -164,18 +186,58 @@ func newParticipantWithRoomTokens(cf config.LiveKitConfig, participant *Particip
 	}, nil
 }
 
+// verifies the room token in the authorization header of r
+func verifyRoomToken(cf config.LiveKitConfig, r *http.Request) (*auth.ClaimGrants, error) {
+	authHeader := r.Header.Get("authorization")
+	authHeaderParts := strings.Split(authHeader, " ")
+	if len(authHeaderParts) < 2 || authHeaderParts[0] != "Bearer" {
+		return nil, fmt.Errorf("Unauthorized")
+	}
+
+	authVerifier, err := auth.ParseAPIToken(authHeaderParts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var grants *auth.ClaimGrants
+	for _, secret := range cf.APISecrets() {
+		if grants, err = authVerifier.Verify(secret); err == nil {
+			return grants, nil
+		}
+	}
+	return nil, err
+}
+
 type ParticipantCollectionProvider interface {
 	ParticipantCollection() *ParticipantCollection
 }
 
 type ParticipantCollection struct {
 	collection *mongo.Collection
+	bus        event.Bus
 }
 
-func NewParticipantCollection(ctx context.Context, db *mongo.Database) *ParticipantCollection {
-	collection := db.Collection("participant")
+func NewParticipantCollection(db *mongo.Database, bus event.Bus) *ParticipantCollection {
+	collection := db.Collection(ParticipantCollectionName)
+
+	return &ParticipantCollection{collection: collection, bus: bus}
+}
 
-	return &ParticipantCollection{collection: collection}
+// EnsureIndexes creates the indexes of the collection
+func (c *ParticipantCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "ParticipantCollection.EnsureIndexes")
+	defer span.End()
+
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}, {
+		Keys: bson.D{{Key: "meetingId", Value: 1}, {Key: "status", Value: 1}},
+	}, {
+		// for counting waiting participants of all meetings
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}},
+	}})
+	return err
 }
 
 type ParticipantController struct {
@@ -185,6 +247,9 @@ type ParticipantController struct {
 func (c *ParticipantCollection) FindOneByID(
 	ctx context.Context, id ResourceID,
 ) (*Participant, error) {
+	ctx, span := tracing.Start(ctx, "ParticipantCollection.FindOneByID")
+	defer span.End()
+
 	_id, err := id.ObjectID()
 	if err != nil {
 		return nil, err
@@ -193,6 +258,7 @@ func (c *ParticipantCollection) FindOneByID(
 	var participant Participant
 	err = c.collection.FindOne(ctx, bson.D{
 		{Key: "_id", Value: _id},
+		notExpired(),
 	}).Decode(&participant)
 
 	if err != nil && err == mongo.ErrNoDocuments {
@@ -204,9 +270,47 @@ func (c *ParticipantCollection) FindOneByID(
 	return &participant, nil
 }
 
+func (c *ParticipantCollection) FindAllWaitingByMeetingID(
+	ctx context.Context, meetingID ResourceID,
+) ([]*Participant, error) {
+	ctx, span := tracing.Start(ctx, "ParticipantCollection.FindAllWaitingByMeetingID")
+	defer span.End()
+
+	cur, err := c.collection.Find(ctx, bson.D{
+		{Key: "meetingId", Value: meetingID},
+		{Key: "status", Value: ParticipantStatus_Waiting},
+		notExpired(),
+	}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	participants := []*Participant{}
+	err = cur.All(ctx, &participants)
+	if err != nil {
+		return nil, err
+	}
+
+	return participants, nil
+}
+
+// CountWaiting counts waiting participants of all meetings
+func (c *ParticipantCollection) CountWaiting(ctx context.Context) (int64, error) {
+	ctx, span := tracing.Start(ctx, "ParticipantCollection.CountWaiting")
+	defer span.End()
+
+	return c.collection.CountDocuments(ctx, bson.D{
+		{Key: "status", Value: ParticipantStatus_Waiting},
+		notExpired(),
+	})
+}
+
 func (c *ParticipantCollection) DeleteOneByID(
 	ctx context.Context, id ResourceID,
 ) error {
+	ctx, span := tracing.Start(ctx, "ParticipantCollection.DeleteOneByID")
+	defer span.End()
+
 	_id, err := id.ObjectID()
 	if err != nil {
 		return err
@@ -221,6 +325,9 @@ func (c *ParticipantCollection) DeleteOneByID(
 func (c *ParticipantCollection) Save(
 	ctx context.Context, participant *Participant,
 ) error {
+	ctx, span := tracing.Start(ctx, "ParticipantCollection.Save")
+	defer span.End()
+
 	if participant.ID == "" {
 		now := time.Now()
 		participant.CreatedAt = now
@@ -231,7 +338,7 @@ func (c *ParticipantCollection) Save(
 			return err
 		}
 		participant.ID = ResourceIDFromObjectID(r.InsertedID.(primitive.ObjectID))
-		return nil
+		return c.publish(ctx, event.Operation_Insert, participant)
 	} else {
 		_id, err := participant.ID.ObjectID()
 		if err != nil {
@@ -240,21 +347,57 @@ func (c *ParticipantCollection) Save(
 
 		participant.UpdatedAt = time.Now()
 
-		_, err = c.collection.UpdateOne(ctx, bson.D{
+		r, err := c.collection.UpdateOne(ctx, bson.D{
 			{Key: "_id", Value: _id},
 		}, bson.D{
 			{Key: "$set", Value: participant},
 		}, options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+		if r.UpsertedCount > 0 {
+			return c.publish(ctx, event.Operation_Insert, participant)
+		}
+		return c.publish(ctx, event.Operation_Update, participant)
+	}
+}
+
+func (c *ParticipantCollection) publish(
+	ctx context.Context, operation event.Operation, participant *Participant,
+) error {
+	e, err := event.NewEvent(ParticipantCollectionName, operation, string(participant.ID), participant)
+	if err != nil {
 		return err
 	}
+	return c.bus.Publish(ctx, e)
 }
 
 type ParticipantCreateBody struct {
-	Name *string `json:"name"`
+	Name       *string `json:"name"`
+	Message    *string `json:"message"`
+	Invitation *string `json:"invitation"`
+	Passcode   *string `json:"passcode"`
+}
+
+// trims message and ensures it is within length limits
+func normalizeParticipantMessage(message *string) (*string, error) {
+	if message == nil {
+		return nil, nil
+	}
+	m := strings.TrimSpace(*message)
+	if m == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(m) > ParticipantMessageLengthMax {
+		return nil, fmt.Errorf("Message in request body exceeds %d characters", ParticipantMessageLengthMax)
+	}
+	return &m, nil
 }
 
 func NewParticipantController(ds ParticipantDeps) *ParticipantController {
-	return &ParticipantController{ParticipantDeps: ds}
+	return &ParticipantController{
+		ParticipantDeps: ds,
+	}
 }
 
 func (c *ParticipantController) ParticipantCreateHandler(w http.ResponseWriter, r *http.Request) {
@@ -283,11 +426,26 @@ func (c *ParticipantController) ParticipantCreateHandler(w http.ResponseWriter,
 		return
 	}
 
+	// decode body
+	b := &ParticipantCreateBody{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil && err != io.EOF {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// get message
+	message, err := normalizeParticipantMessage(b.Message)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// get name and image
 	var name string
 	var imageURL *string
+	var user *User
 	if auth != nil {
-		user, err := c.UserCollection().FindOneByID(r.Context(), ResourceID(auth.UserID))
+		user, err = c.UserCollection().FindOneByID(r.Context(), ResourceID(auth.UserID))
 		if err != nil {
 			util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -297,13 +455,11 @@ func (c *ParticipantController) ParticipantCreateHandler(w http.ResponseWriter,
 			imageURL = user.ImageURL
 		}
 	} else {
-		// decode body
-		b := &ParticipantCreateBody{}
-		if err := json.NewDecoder(r.Body).Decode(b); err != nil {
-			util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		if b.Name == nil || strings.TrimSpace(*b.Name) == "" {
+			util.WriteJSONError(w, http.StatusBadRequest, "Missing name in request body")
 			return
 		}
-		name = *b.Name
+		name = strings.TrimSpace(*b.Name)
 	}
 
 	// get admin and status
@@ -312,25 +468,127 @@ func (c *ParticipantController) ParticipantCreateHandler(w http.ResponseWriter,
 	if auth != nil && meeting.UserID == ResourceID(auth.UserID) {
 		admin = true
 		status = ParticipantStatus_Admitted
+	} else if meeting.Lobby.Bypasses(user) {
+		status = ParticipantStatus_Admitted
 	}
 
-	// create participant
+	// use invitation
+	var invited bool
+	if !admin && b.Invitation != nil {
+		claims, err := parseInvitationToken(c.AuthConfig(), c.InvitationConfig(), *b.Invitation)
+		if err != nil || claims.MeetingID != meeting.ID {
+			util.WriteJSONError(w, http.StatusBadRequest, "Invalid invitation in request body")
+			return
+		}
+
+		invitation, err := c.InvitationCollection().UseOneByID(r.Context(), claims.InvitationID)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if invitation == nil {
+			util.WriteJSONError(w, http.StatusBadRequest, "Invitation has expired or was already used")
+			return
+		}
+
+		invited = true
+		status = ParticipantStatus_Admitted
+	}
+
+	// verify passcode, attempts are counted per ip and per meeting before the
+	// slow compare so that parallel guesses cannot pass the limits, the ip
+	// count is reset on success, the meeting count is not so that guesses from
+	// many addresses stay limited while others join
+	if !admin && !invited && meeting.HasPasscode {
+		if b.Passcode == nil || *b.Passcode == "" {
+			util.WriteJSONError(w, http.StatusUnauthorized, "Missing passcode in request body")
+			return
+		}
+
+		cf := c.ParticipantConfig()
+		ipKey := "passcode:ip:" + middleware.GetRemoteIP(r)
+		for _, l := range []struct {
+			key      string
+			requests int
+		}{
+			{ipKey, cf.PasscodeFailuresMaxPerIP},
+			{"passcode:meeting:" + string(meeting.ID), cf.PasscodeFailuresMaxPerMeeting},
+		} {
+			ctx, cancel := context.WithTimeout(r.Context(), c.RateLimitConfig().StoreTimeout)
+			allowed, _, err := c.RateLimitStore().Take(ctx, l.key, middleware.RateLimit{
+				Requests: l.requests,
+				Per:      cf.PasscodeFailuresWindow,
+			})
+			cancel()
+			if err != nil {
+				util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			if !allowed {
+				util.WriteJSONError(w, http.StatusTooManyRequests, "Too many failed passcode attempts, try again later")
+				return
+			}
+		}
+
+		if !meeting.verifyPasscode(*b.Passcode) {
+			util.WriteJSONError(w, http.StatusUnauthorized, "Invalid passcode")
+			return
+		}
+		if err := c.RateLimitStore().Reset(r.Context(), ipKey); err != nil {
+			middleware.Logger(r.Context()).Warn("error resetting passcode attempts", "error", err)
+		}
+	}
+
+	// refresh meeting expiry
+	err = c.MeetingCollection().Refresh(r.Context(), meeting)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// find current occurrence
 	now := time.Now()
-	participant := &Participant{
-		ID:        ResourceIDFromObjectID(primitive.NewObjectID()),
-		MeetingID: meeting.ID,
-		Name:      name,
-		ImageURL:  imageURL,
-		Status:    status,
-		CreatedAt: now,
-		UpdatedAt: now,
-		ExpiresAt: now.Add(participantTTL),
+	var occurrenceID *string
+	if meeting.Recurrence != nil {
+		occurrence, err := c.OccurrenceCollection().FindCurrentByMeeting(r.Context(), meeting, now)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if occurrence != nil {
+			occurrenceID = &occurrence.ID
+		}
 	}
 
-	// save participant
-	if status == ParticipantStatus_Waiting {
-		// save participant
-		err = c.ParticipantCollection().Save(r.Context(), participant)
+	// create participant
+	var userID *ResourceID
+	if user != nil {
+		userID = &user.ID
+	}
+	participant := &Participant{
+		ID:           ResourceIDFromObjectID(primitive.NewObjectID()),
+		MeetingID:    meeting.ID,
+		OccurrenceID: occurrenceID,
+		UserID:       userID,
+		Name:         name,
+		ImageURL:     imageURL,
+		Status:       status,
+		Message:      message,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		ExpiresAt:    now.Add(c.ParticipantConfig().TTL),
+	}
+
+	// save participant, participants admitted without waiting are saved too
+	// so that participant.admitted webhooks are sent for them
+	err = c.ParticipantCollection().Save(r.Context(), participant)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if status == ParticipantStatus_Admitted {
+		// register attendance
+		err = c.AttendanceCollection().Register(r.Context(), participant)
 		if err != nil {
 			util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
 			return
@@ -349,21 +607,7 @@ func (c *ParticipantController) ParticipantCreateHandler(w http.ResponseWriter,
 
 func (c *ParticipantController) ParticipantRetrieveHandler(w http.ResponseWriter, r *http.Request) {
 	// decode room token
-	authHeader := r.Header.Get("authorization")
-	authHeaderParts := strings.Split(authHeader, " ")
-	if len(authHeaderParts) < 2 || authHeaderParts[0] != "Bearer" {
-		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
-		return
-	}
-
-	authToken := authHeaderParts[1]
-	authVerifier, err := auth.ParseAPIToken(authToken)
-	if err != nil {
-		util.WriteJSONError(w, http.StatusUnauthorized, err.Error())
-		return
-	}
-
-	authClaims, err := authVerifier.Verify(c.LiveKitConfig().APISecret)
+	authClaims, err := verifyRoomToken(c.LiveKitConfig(), r)
 	if err != nil {
 		util.WriteJSONError(w, http.StatusUnauthorized, err.Error())
 		return
@@ -417,6 +661,15 @@ func (c *ParticipantController) ParticipantRetrieveHandler(w http.ResponseWriter
 		return
 	}
 
+	// register attendance if admitted
+	if participant.Status == ParticipantStatus_Admitted {
+		err = c.AttendanceCollection().Register(r.Context(), participant)
+		if err != nil {
+			util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
 	// delete participant if not waiting
 	if participant.Status != ParticipantStatus_Waiting {
 		err = c.ParticipantCollection().DeleteOneByID(r.Context(), participant.ID)
@@ -430,7 +683,8 @@ func (c *ParticipantController) ParticipantRetrieveHandler(w http.ResponseWriter
 }
 
 type ParticipantUpdateBody struct {
-	Status ParticipantStatus `json:"status"`
+	Status  ParticipantStatus `json:"status"`
+	Message *string           `json:"message"`
 }
 
 func (c *ParticipantController) ParticipantUpdateHandler(w http.ResponseWriter, r *http.Request) {
@@ -502,9 +756,20 @@ func (c *ParticipantController) ParticipantUpdateHandler(w http.ResponseWriter,
 		return
 	}
 
+	// get message
+	message, err := normalizeParticipantMessage(b.Message)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if message != nil && b.Status != ParticipantStatus_Denied {
+		util.WriteJSONError(w, http.StatusBadRequest, "Message in request body is only allowed when denying")
+		return
+	}
+
 	// update participant
 	participant.Status = b.Status
-	participant.ExpiresAt = time.Now().Add(participantTTL)
+	participant.ExpiresAt = time.Now().Add(c.ParticipantConfig().TTL)
 
 	// save participant
 	err = c.ParticipantCollection().Save(r.Context(), participant)
@@ -512,19 +777,17 @@ func (c *ParticipantController) ParticipantUpdateHandler(w http.ResponseWriter,
 		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	metrics.ParticipantAdmissionsTotal.WithLabelValues(string(participant.Status)).Inc()
 
 	// notify waiting room about updated participant
-	var _type string
+	var payload any
 	if participant.Status == ParticipantStatus_Admitted {
-		_type = "participantAdmitted"
+		payload = NewParticipantAdmittedData(participant.ID)
 	} else {
-		_type = "participantDenied"
+		payload = NewParticipantDeniedData(participant.ID, message)
 	}
 
-	data, err := util.EncodeLiveKitDataJSON(map[string]any{
-		"type": _type,
-		"id":   participant.ID,
-	})
+	data, err := EncodeData(c.LiveKitConfig(), payload)
 	if err != nil {
 		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -543,10 +806,100 @@ func (c *ParticipantController) ParticipantUpdateHandler(w http.ResponseWriter,
 	util.WriteJSONResponse(w, http.StatusOK, participant)
 }
 
+// ParticipantAdmitFailure is a waiting participant that could not be admitted
+type ParticipantAdmitFailure struct {
+	ParticipantID ResourceID `json:"participantId"`
+	Error         string     `json:"error"`
+}
+
+func (c *ParticipantController) ParticipantAdmitAllHandler(w http.ResponseWriter, r *http.Request) {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// get meeting id
+	meetingID := mux.Vars(r)["meetingId"]
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request path")
+		return
+	}
+
+	// find one meeting by id
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	// ensure auth user is the meeting admin
+	if auth.UserID != string(meeting.UserID) {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Only meeting admins can update participants")
+		return
+	}
+
+	// find all waiting participants
+	participants, err := c.ParticipantCollection().FindAllWaitingByMeetingID(r.Context(), meeting.ID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// save all participants before notifying so that a failed notification
+	// does not leave the rest waiting
+	admitted := make([]*Participant, 0, len(participants))
+	failed := make([]*ParticipantAdmitFailure, 0)
+	for _, participant := range participants {
+		// update participant
+		participant.Status = ParticipantStatus_Admitted
+		participant.ExpiresAt = time.Now().Add(c.ParticipantConfig().TTL)
+
+		// save participant
+		if err := c.ParticipantCollection().Save(r.Context(), participant); err != nil {
+			failed = append(failed, &ParticipantAdmitFailure{ParticipantID: participant.ID, Error: err.Error()})
+			continue
+		}
+		metrics.ParticipantAdmissionsTotal.WithLabelValues(string(participant.Status)).Inc()
+		admitted = append(admitted, participant)
+	}
+
+	// notify waiting room about updated participants, best effort since
+	// waiting participants also poll their status
+	for _, participant := range admitted {
+		data, err := EncodeData(c.LiveKitConfig(), NewParticipantAdmittedData(participant.ID))
+		if err == nil {
+			_, err = c.LiveKitClient().SendData(r.Context(), &livekit.SendDataRequest{
+				Room: meeting.Code + participantWaitingRoomSuffix,
+				Data: data,
+				Kind: livekit.DataPacket_RELIABLE,
+			})
+		}
+		if err != nil {
+			middleware.Logger(r.Context()).Warn("error notifying admitted participant", "participantId", participant.ID, "error", err)
+		}
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, map[string]any{
+		"participants": admitted,
+		"failed":       failed,
+	})
+}
+
 func RegisterParticipantRoutes(r *mux.Router, ds ParticipantDeps) *mux.Router {
 	c := NewParticipantController(ds)
 
 	r.HandleFunc("/meetings/{meetingId}/participants", c.ParticipantCreateHandler).Methods(http.MethodPost)
+	r.HandleFunc("/meetings/{meetingId}/participants:admitAll", c.ParticipantAdmitAllHandler).Methods(http.MethodPost)
 	r.HandleFunc("/meetings/{meetingId}/participants/{participantId}", c.ParticipantRetrieveHandler).Methods(http.MethodGet)
 	r.HandleFunc("/meetings/{meetingId}/participants/{participantId}", c.ParticipantUpdateHandler).Methods(http.MethodPut)
 
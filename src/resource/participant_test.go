@@ -13,6 +13,7 @@ import (
 
 func newParticipantAndJSON() (ParticipantWithRoomTokens, []byte) {
 	var t, _ = time.Parse(time.RFC3339, "2022-01-01T00:00:00.000Z")
+	var message = "Hello"
 	var p = ParticipantWithRoomTokens{
 		Participant: Participant{
 			ID:        "some-id",
@@ -20,6 +21,7 @@ func newParticipantAndJSON() (ParticipantWithRoomTokens, []byte) {
 			Name:      "Aravindan",
 			ImageURL:  nil,
 			Status:    ParticipantStatus_Waiting,
+			Message:   &message,
 			CreatedAt: t,
 			UpdatedAt: t,
 			ExpiresAt: t,
@@ -32,8 +34,8 @@ func newParticipantAndJSON() (ParticipantWithRoomTokens, []byte) {
 		}},
 	}
 
-	var j = []byte(`{"id":"some-id","meetingId":"some-id","name":"Aravindan",` +
-		`"imageUrl":null,"status":"waiting","createdAt":"2022-01-01T00:00:00Z",` +
+	var j = []byte(`{"id":"some-id","meetingId":"some-id","occurrenceId":null,"userId":null,"name":"Aravindan",` +
+		`"imageUrl":null,"status":"waiting","message":"Hello","createdAt":"2022-01-01T00:00:00Z",` +
 		`"updatedAt":"2022-01-01T00:00:00Z","expiresAt":"2022-01-01T00:00:00Z",` +
 		`"roomTokens":[{"roomName":"some-room","roomType":"conference","accessToken":"some-token",` +
 		`"accessTokenExpiresAt":"2022-01-01T00:00:00Z"}]}`)
@@ -86,9 +88,11 @@ func newParticipantAndBSON() (Participant, []byte) {
 	var b, _ = bson.Marshal(bson.D{
 		{Key: "_id", Value: o},
 		{Key: "meetingId", Value: o},
+		{Key: "occurrenceId", Value: nil},
 		{Key: "name", Value: "Aravindan"},
 		{Key: "imageUrl", Value: nil},
 		{Key: "status", Value: "waiting"},
+		{Key: "message", Value: nil},
 		{Key: "createdAt", Value: d},
 		{Key: "updatedAt", Value: d},
 		{Key: "expiresAt", Value: d},
@@ -0,0 +1,154 @@
+package resource
+
+import (
+	"context"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/event"
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"github.com/livekit/protocol/livekit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	RecordingCollectionName = "recording"
+)
+
+const (
+	RecordingStatus_Complete RecordingStatus = "complete"
+	RecordingStatus_Failed   RecordingStatus = "failed"
+)
+
+type RecordingStatus string
+
+// Recording is a finished livekit egress of the conference room, it is kept
+// as long as webhook deliveries so that retried livekit events are ignored
+type Recording struct {
+	ID        ResourceID      `json:"id" bson:"_id,omitempty"`
+	MeetingID ResourceID      `json:"meetingId" bson:"meetingId"`
+	EgressID  string          `json:"egressId" bson:"egressId"`
+	Status    RecordingStatus `json:"status" bson:"status"`
+	Error     *string         `json:"error" bson:"error"`
+	File      *RecordingFile  `json:"file" bson:"file"` // nil for streams and segments
+	StartedAt *time.Time      `json:"startedAt" bson:"startedAt"`
+	EndedAt   *time.Time      `json:"endedAt" bson:"endedAt"`
+	CreatedAt time.Time       `json:"createdAt" bson:"createdAt"`
+	ExpiresAt time.Time       `json:"expiresAt" bson:"expiresAt"`
+}
+
+// RecordingFile is the file written by the egress
+type RecordingFile struct {
+	Filename string `json:"filename" bson:"filename"`
+	Location string `json:"location" bson:"location"`
+	Size     int64  `json:"size" bson:"size"`         // bytes
+	Duration int64  `json:"duration" bson:"duration"` // seconds
+}
+
+// returns the recording of an ended egress
+func newRecording(meetingID ResourceID, info *livekit.EgressInfo) *Recording {
+	recording := &Recording{
+		MeetingID: meetingID,
+		EgressID:  info.EgressId,
+		Status:    RecordingStatus_Complete,
+	}
+	if info.Status != livekit.EgressStatus_EGRESS_COMPLETE {
+		recording.Status = RecordingStatus_Failed
+		if info.Error != "" {
+			recording.Error = &info.Error
+		}
+	}
+	if info.StartedAt > 0 {
+		t := time.Unix(0, info.StartedAt)
+		recording.StartedAt = &t
+	}
+	if info.EndedAt > 0 {
+		t := time.Unix(0, info.EndedAt)
+		recording.EndedAt = &t
+	}
+	if f := info.GetFile(); f != nil {
+		recording.File = &RecordingFile{
+			Filename: f.Filename,
+			Location: f.Location,
+			Size:     f.Size,
+			Duration: f.Duration / int64(time.Second),
+		}
+	}
+	return recording
+}
+
+// returns the room name of an egress request
+func egressRoomName(info *livekit.EgressInfo) string {
+	if r := info.GetRoomComposite(); r != nil {
+		return r.RoomName
+	}
+	if r := info.GetTrackComposite(); r != nil {
+		return r.RoomName
+	}
+	if r := info.GetTrack(); r != nil {
+		return r.RoomName
+	}
+	return ""
+}
+
+type RecordingCollectionProvider interface {
+	RecordingCollection() *RecordingCollection
+}
+
+type RecordingCollection struct {
+	collection *mongo.Collection
+	bus        event.Bus
+	cf         config.WebhookConfigProvider
+}
+
+func NewRecordingCollection(db *mongo.Database, bus event.Bus, cf config.WebhookConfigProvider) *RecordingCollection {
+	collection := db.Collection(RecordingCollectionName)
+
+	return &RecordingCollection{collection: collection, bus: bus, cf: cf}
+}
+
+// EnsureIndexes creates the indexes of the collection
+func (c *RecordingCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "RecordingCollection.EnsureIndexes")
+	defer span.End()
+
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "egressId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}, {
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}})
+	return err
+}
+
+// Insert creates the recording and publishes it, does nothing if a recording
+// of the egress exists
+func (c *RecordingCollection) Insert(
+	ctx context.Context, recording *Recording,
+) error {
+	ctx, span := tracing.Start(ctx, "RecordingCollection.Insert")
+	defer span.End()
+
+	now := time.Now()
+	recording.CreatedAt = now
+	recording.ExpiresAt = now.Add(c.cf.WebhookConfig().DeliveryTTL)
+
+	r, err := c.collection.InsertOne(ctx, recording)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil // livekit delivered the event again
+	}
+	if err != nil {
+		return err
+	}
+	recording.ID = ResourceIDFromObjectID(r.InsertedID.(primitive.ObjectID))
+
+	e, err := event.NewEvent(RecordingCollectionName, event.Operation_Insert, string(recording.ID), recording)
+	if err != nil {
+		return err
+	}
+	return c.bus.Publish(ctx, e)
+}
@@ -2,6 +2,7 @@ package resource
 
 import (
 	"fmt"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/bsontype"
@@ -37,3 +38,12 @@ func (o *ResourceID) UnmarshalBSONValue(t bsontype.Type, v []byte) error {
 	}
 	return fmt.Errorf("unexpected bsontype %s", t)
 }
+
+// notExpired returns a filter matching documents without expiresAt or with
+// expiresAt in the future. Expired documents are eventually removed by ttl
+// indexes but can linger for a while.
+func notExpired() bson.E {
+	return bson.E{Key: "expiresAt", Value: bson.D{
+		{Key: "$not", Value: bson.D{{Key: "$lte", Value: time.Now()}}},
+	}}
+}
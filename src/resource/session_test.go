@@ -48,6 +48,8 @@ func newSessionWithUserAndJSON() (Session, []byte) {
 			ID:                 "some-id",
 			Name:               "Aravindan",
 			ImageURL:           nil,
+			Email:              "aravindan@example.com",
+			EmailDomain:        "example.com",
 			Provider:           UserProvider_Google,
 			ProviderResourceID: "google-id",
 			CreatedAt:          t,
@@ -56,6 +58,7 @@ func newSessionWithUserAndJSON() (Session, []byte) {
 	}
 
 	var j = []byte(`{"user":{"id":"some-id","name":"Aravindan","imageUrl":null,` +
+		`"email":"aravindan@example.com","emailDomain":"example.com",` +
 		`"provider":"google","providerResourceId":"google-id","createdAt":"2022-01-01T00:00:00Z",` +
 		`"updatedAt":"2022-01-01T00:00:00Z"}}`)
 
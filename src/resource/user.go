@@ -2,11 +2,14 @@ package resource
 
 import (
 	"context"
-	"fmt"
-	"log"
-	"os"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"github.com/aravindanve/livemeet-server/src/util"
+	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -19,16 +22,34 @@ const (
 
 type UserProvider string
 
+type UserDeps interface {
+	UserCollectionProvider
+	MeetingCollectionProvider
+}
+
 type User struct {
 	ID                 ResourceID   `json:"id" bson:"_id,omitempty"`
 	Name               string       `json:"name" bson:"name"`
 	ImageURL           *string      `json:"imageUrl" bson:"imageUrl"`
+	Email              string       `json:"email" bson:"email"`
+	EmailDomain        string       `json:"emailDomain" bson:"emailDomain"`
 	Provider           UserProvider `json:"provider" bson:"provider"`
 	ProviderResourceID string       `json:"providerResourceId" bson:"providerResourceId"`
 	CreatedAt          time.Time    `json:"createdAt" bson:"createdAt"`
 	UpdatedAt          time.Time    `json:"updatedAt" bson:"updatedAt"`
 }
 
+// UserProfile is the part of a user shown to other users
+type UserProfile struct {
+	ID       ResourceID `json:"id"`
+	Name     string     `json:"name"`
+	ImageURL *string    `json:"imageUrl"`
+}
+
+func newUserProfile(user *User) *UserProfile {
+	return &UserProfile{ID: user.ID, Name: user.Name, ImageURL: user.ImageURL}
+}
+
 type UserCollectionProvider interface {
 	UserCollection() *UserCollection
 }
@@ -37,32 +58,32 @@ type UserCollection struct {
 	collection *mongo.Collection
 }
 
-func NewUserCollection(ctx context.Context, db *mongo.Database) *UserCollection {
+func NewUserCollection(db *mongo.Database) *UserCollection {
 	collection := db.Collection("user")
 
-	// create indexes
-	go func() {
-		_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
-			Keys:    bson.D{{Key: "providerResourceId", Value: 1}},
-			Options: options.Index().SetUnique(true),
-		})
+	return &UserCollection{collection: collection}
+}
 
-		if err != nil {
-			msg := fmt.Sprintf("error creating mongo indexes: %s", err.Error())
-			if os.Getenv("APP_ENV") == "testing" {
-				log.Println(msg) // do not panic in tests
-			} else {
-				panic(msg)
-			}
-		}
-	}()
+// EnsureIndexes creates the indexes of the collection
+func (c *UserCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "UserCollection.EnsureIndexes")
+	defer span.End()
 
-	return &UserCollection{collection: collection}
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "providerResourceId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}, {
+		Keys: bson.D{{Key: "email", Value: 1}},
+	}})
+	return err
 }
 
 func (c *UserCollection) FindOneByID(
 	ctx context.Context, id ResourceID,
 ) (*User, error) {
+	ctx, span := tracing.Start(ctx, "UserCollection.FindOneByID")
+	defer span.End()
+
 	_id, err := id.ObjectID()
 	if err != nil {
 		return nil, err
@@ -85,6 +106,9 @@ func (c *UserCollection) FindOneByID(
 func (c *UserCollection) FindOneByProviderResourceID(
 	ctx context.Context, provider UserProvider, providerResourceID string,
 ) (*User, error) {
+	ctx, span := tracing.Start(ctx, "UserCollection.FindOneByProviderResourceID")
+	defer span.End()
+
 	var user User
 	err := c.collection.FindOne(ctx, bson.D{
 		{Key: "provider", Value: provider},
@@ -100,9 +124,34 @@ func (c *UserCollection) FindOneByProviderResourceID(
 	return &user, nil
 }
 
+func (c *UserCollection) FindAllByEmail(
+	ctx context.Context, email string,
+) ([]*User, error) {
+	ctx, span := tracing.Start(ctx, "UserCollection.FindAllByEmail")
+	defer span.End()
+
+	cur, err := c.collection.Find(ctx, bson.D{
+		{Key: "email", Value: NormalizeUserEmail(email)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := []*User{}
+	err = cur.All(ctx, &users)
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (c *UserCollection) Save(
 	ctx context.Context, user *User,
 ) error {
+	ctx, span := tracing.Start(ctx, "UserCollection.Save")
+	defer span.End()
+
 	if user.ID == "" {
 		now := time.Now()
 		user.CreatedAt = now
@@ -130,3 +179,97 @@ func (c *UserCollection) Save(
 		return err
 	}
 }
+
+// NormalizeUserEmail returns the trimmed and lower cased email
+func NormalizeUserEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// returns the hosted domain if set or the domain part of email
+func newUserEmailDomain(email string, hostedDomain *string) string {
+	if hostedDomain != nil && *hostedDomain != "" {
+		return strings.ToLower(*hostedDomain)
+	}
+	if i := strings.LastIndex(email, "@"); i >= 0 {
+		return strings.ToLower(email[i+1:])
+	}
+	return ""
+}
+
+type UserController struct {
+	UserDeps
+}
+
+func NewUserController(ds UserDeps) *UserController {
+	return &UserController{UserDeps: ds}
+}
+
+func (c *UserController) UserSearchHandler(w http.ResponseWriter, r *http.Request) {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// get email
+	email := NormalizeUserEmail(r.URL.Query().Get("email"))
+	if email == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing email in request query")
+		return
+	}
+
+	// get meeting id
+	meetingID := r.URL.Query().Get("meetingId")
+	if meetingID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing meetingId in request query")
+		return
+	}
+
+	// find meeting
+	meeting, err := c.MeetingCollection().FindOneByID(r.Context(), ResourceID(meetingID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if meeting == nil {
+		util.WriteJSONError(w, http.StatusNotFound, "Meeting not found")
+		return
+	}
+
+	// ensure auth user is the meeting admin
+	if auth.UserID != string(meeting.UserID) {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Only meeting admins can search users")
+		return
+	}
+
+	// find all by email
+	users, err := c.UserCollection().FindAllByEmail(r.Context(), email)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	profiles := make([]*UserProfile, 0, len(users))
+	for _, user := range users {
+		profiles = append(profiles, newUserProfile(user))
+	}
+
+	res := map[string]any{
+		"users": profiles,
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, res)
+}
+
+func RegisterUserRoutes(r *mux.Router, ds UserDeps) *mux.Router {
+	c := NewUserController(ds)
+
+	r.HandleFunc("/users", c.UserSearchHandler).Methods(http.MethodGet).Queries("email", "{email}")
+
+	return r
+}
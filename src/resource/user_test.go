@@ -16,6 +16,8 @@ func newUserAndJSON() (User, []byte) {
 		ID:                 "some-id",
 		Name:               "Aravindan",
 		ImageURL:           nil,
+		Email:              "aravindan@example.com",
+		EmailDomain:        "example.com",
 		Provider:           UserProvider_Google,
 		ProviderResourceID: "google-id",
 		CreatedAt:          t,
@@ -23,6 +25,7 @@ func newUserAndJSON() (User, []byte) {
 	}
 
 	var j = []byte(`{"id":"some-id","name":"Aravindan","imageUrl":null,` +
+		`"email":"aravindan@example.com","emailDomain":"example.com",` +
 		`"provider":"google","providerResourceId":"google-id","createdAt":"2022-01-01T00:00:00Z",` +
 		`"updatedAt":"2022-01-01T00:00:00Z"}`)
 
@@ -63,6 +66,8 @@ func newUserAndBSON() (User, []byte) {
 		ID:                 ResourceIDFromObjectID(o),
 		Name:               "Aravindan",
 		ImageURL:           nil,
+		Email:              "aravindan@example.com",
+		EmailDomain:        "example.com",
 		Provider:           UserProvider_Google,
 		ProviderResourceID: "google-id",
 		CreatedAt:          t,
@@ -74,6 +79,8 @@ func newUserAndBSON() (User, []byte) {
 		{Key: "_id", Value: o},
 		{Key: "name", Value: "Aravindan"},
 		{Key: "imageUrl", Value: nil},
+		{Key: "email", Value: "aravindan@example.com"},
+		{Key: "emailDomain", Value: "example.com"},
 		{Key: "provider", Value: UserProvider_Google},
 		{Key: "providerResourceId", Value: "google-id"},
 		{Key: "createdAt", Value: d},
@@ -109,3 +116,18 @@ func TestUserUnmarshalBSON(t *testing.T) {
 		t.Fatalf("Unexpected unmarshalled bson: %#v", value)
 	}
 }
+
+func TestNewUserEmailDomain(t *testing.T) {
+	t.Parallel()
+	hd := "Example.com"
+
+	if v := newUserEmailDomain("user@gmail.com", nil); v != "gmail.com" {
+		t.Fatalf("Unexpected email domain: %#v", v)
+	}
+	if v := newUserEmailDomain("user@mail.example.com", &hd); v != "example.com" {
+		t.Fatalf("Unexpected email domain: %#v", v)
+	}
+	if v := newUserEmailDomain("", nil); v != "" {
+		t.Fatalf("Unexpected email domain: %#v", v)
+	}
+}
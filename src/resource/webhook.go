@@ -0,0 +1,805 @@
+package resource
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aravindanve/livemeet-server/src/client"
+	"github.com/aravindanve/livemeet-server/src/config"
+	"github.com/aravindanve/livemeet-server/src/event"
+	"github.com/aravindanve/livemeet-server/src/middleware"
+	"github.com/aravindanve/livemeet-server/src/tracing"
+	"github.com/aravindanve/livemeet-server/src/util"
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	WebhookCountMax           = 20
+	webhookDeliveryRetryDelay = 1 * time.Second
+	webhookDeliveryCountMax   = 50
+)
+
+const (
+	WebhookEvent_Ping                WebhookEvent = "ping"
+	WebhookEvent_MeetingCreated      WebhookEvent = "meeting.created"
+	WebhookEvent_ParticipantWaiting  WebhookEvent = "participant.waiting"
+	WebhookEvent_ParticipantAdmitted WebhookEvent = "participant.admitted"
+	WebhookEvent_ParticipantDenied   WebhookEvent = "participant.denied"
+	WebhookEvent_RecordingFinished   WebhookEvent = "recording.finished"
+)
+
+type WebhookEvent string
+
+func (e WebhookEvent) subscribable() bool {
+	switch e {
+	case WebhookEvent_MeetingCreated,
+		WebhookEvent_ParticipantWaiting,
+		WebhookEvent_ParticipantAdmitted,
+		WebhookEvent_ParticipantDenied,
+		WebhookEvent_RecordingFinished:
+		return true
+	}
+	return false
+}
+
+const (
+	WebhookDeliveryStatus_Pending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatus_Succeeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatus_Failed    WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDeliveryStatus string
+
+type WebhookDeps interface {
+	config.WebhookConfigProvider
+	client.WebhookClientProvider
+	WebhookCollectionProvider
+	WebhookDeliveryCollectionProvider
+}
+
+type Webhook struct {
+	ID        ResourceID     `json:"id" bson:"_id,omitempty"`
+	UserID    ResourceID     `json:"userId" bson:"userId"`
+	URL       string         `json:"url" bson:"url"`
+	Events    []WebhookEvent `json:"events" bson:"events"`
+	Secret    string         `json:"-" bson:"secret"` // only returned on create
+	CreatedAt time.Time      `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt" bson:"updatedAt"`
+}
+
+// WebhookWithSecret is returned once when the webhook is created
+type WebhookWithSecret struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+type WebhookPayload struct {
+	ID        ResourceID   `json:"id"`
+	Event     WebhookEvent `json:"event"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Data      any          `json:"data"`
+}
+
+type WebhookDelivery struct {
+	ID        ResourceID               `json:"id" bson:"_id,omitempty"`
+	WebhookID ResourceID               `json:"webhookId" bson:"webhookId"`
+	Event     WebhookEvent             `json:"event" bson:"event"`
+	Key       string                   `json:"key" bson:"key"`
+	Payload   string                   `json:"payload" bson:"payload"`
+	Status    WebhookDeliveryStatus    `json:"status" bson:"status"`
+	Attempts  []WebhookDeliveryAttempt `json:"attempts" bson:"attempts"`
+	CreatedAt time.Time                `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time                `json:"updatedAt" bson:"updatedAt"`
+	ExpiresAt time.Time                `json:"expiresAt" bson:"expiresAt"`
+}
+
+type WebhookDeliveryAttempt struct {
+	StatusCode *int      `json:"statusCode" bson:"statusCode"`
+	Error      *string   `json:"error" bson:"error"`
+	CreatedAt  time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+type WebhookCollectionProvider interface {
+	WebhookCollection() *WebhookCollection
+}
+
+type WebhookCollection struct {
+	collection *mongo.Collection
+}
+
+func NewWebhookCollection(db *mongo.Database) *WebhookCollection {
+	collection := db.Collection("webhook")
+
+	return &WebhookCollection{collection: collection}
+}
+
+// EnsureIndexes creates the indexes of the collection
+func (c *WebhookCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "WebhookCollection.EnsureIndexes")
+	defer span.End()
+
+	_, err := c.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "userId", Value: 1},
+			{Key: "events", Value: 1},
+		},
+	})
+	return err
+}
+
+func (c *WebhookCollection) FindOneByID(
+	ctx context.Context, id ResourceID,
+) (*Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookCollection.FindOneByID")
+	defer span.End()
+
+	_id, err := id.ObjectID()
+	if err != nil {
+		return nil, err
+	}
+
+	var webhook Webhook
+	err = c.collection.FindOne(ctx, bson.D{
+		{Key: "_id", Value: _id},
+	}).Decode(&webhook)
+
+	if err != nil && err == mongo.ErrNoDocuments {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (c *WebhookCollection) FindAllByUserID(
+	ctx context.Context, userID ResourceID,
+) ([]*Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookCollection.FindAllByUserID")
+	defer span.End()
+
+	return c.find(ctx, bson.D{
+		{Key: "userId", Value: userID},
+	})
+}
+
+func (c *WebhookCollection) FindAllByUserIDAndEvent(
+	ctx context.Context, userID ResourceID, event WebhookEvent,
+) ([]*Webhook, error) {
+	ctx, span := tracing.Start(ctx, "WebhookCollection.FindAllByUserIDAndEvent")
+	defer span.End()
+
+	return c.find(ctx, bson.D{
+		{Key: "userId", Value: userID},
+		{Key: "events", Value: event},
+	})
+}
+
+func (c *WebhookCollection) find(
+	ctx context.Context, filter bson.D,
+) ([]*Webhook, error) {
+	cur, err := c.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(WebhookCountMax),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]*Webhook, 0)
+	err = cur.All(ctx, &webhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (c *WebhookCollection) CountByUserID(
+	ctx context.Context, userID ResourceID,
+) (int64, error) {
+	ctx, span := tracing.Start(ctx, "WebhookCollection.CountByUserID")
+	defer span.End()
+
+	return c.collection.CountDocuments(ctx, bson.D{
+		{Key: "userId", Value: userID},
+	})
+}
+
+func (c *WebhookCollection) DeleteOneByID(
+	ctx context.Context, id ResourceID,
+) error {
+	ctx, span := tracing.Start(ctx, "WebhookCollection.DeleteOneByID")
+	defer span.End()
+
+	_id, err := id.ObjectID()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.collection.DeleteOne(ctx, bson.D{
+		{Key: "_id", Value: _id},
+	})
+	return err
+}
+
+func (c *WebhookCollection) Save(
+	ctx context.Context, webhook *Webhook,
+) error {
+	ctx, span := tracing.Start(ctx, "WebhookCollection.Save")
+	defer span.End()
+
+	if webhook.ID == "" {
+		now := time.Now()
+		webhook.CreatedAt = now
+		webhook.UpdatedAt = now
+
+		r, err := c.collection.InsertOne(ctx, webhook)
+		if err != nil {
+			return err
+		}
+		webhook.ID = ResourceIDFromObjectID(r.InsertedID.(primitive.ObjectID))
+		return nil
+	} else {
+		_id, err := webhook.ID.ObjectID()
+		if err != nil {
+			return err
+		}
+
+		webhook.UpdatedAt = time.Now()
+
+		_, err = c.collection.UpdateOne(ctx, bson.D{
+			{Key: "_id", Value: _id},
+		}, bson.D{
+			{Key: "$set", Value: webhook},
+		})
+		return err
+	}
+}
+
+type WebhookDeliveryCollectionProvider interface {
+	WebhookDeliveryCollection() *WebhookDeliveryCollection
+}
+
+type WebhookDeliveryCollection struct {
+	collection *mongo.Collection
+}
+
+func NewWebhookDeliveryCollection(db *mongo.Database) *WebhookDeliveryCollection {
+	collection := db.Collection("webhookDelivery")
+
+	return &WebhookDeliveryCollection{collection: collection}
+}
+
+// EnsureIndexes creates the indexes of the collection
+func (c *WebhookDeliveryCollection) EnsureIndexes(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "WebhookDeliveryCollection.EnsureIndexes")
+	defer span.End()
+
+	// index for expire
+	_, err := c.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	// index for deduplicating deliveries across instances
+	if err == nil {
+		_, err = c.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "webhookId", Value: 1},
+				{Key: "key", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		})
+	}
+	// index for listing deliveries
+	if err == nil {
+		_, err = c.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "webhookId", Value: 1},
+				{Key: "_id", Value: -1},
+			},
+		})
+	}
+	return err
+}
+
+func (c *WebhookDeliveryCollection) FindAllByWebhookID(
+	ctx context.Context, webhookID ResourceID,
+) ([]*WebhookDelivery, error) {
+	ctx, span := tracing.Start(ctx, "WebhookDeliveryCollection.FindAllByWebhookID")
+	defer span.End()
+
+	cur, err := c.collection.Find(ctx, bson.D{
+		{Key: "webhookId", Value: webhookID},
+	}, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(webhookDeliveryCountMax),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*WebhookDelivery, 0)
+	err = cur.All(ctx, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// Save inserts or updates a delivery, returns false if a delivery with the
+// same webhook and key was already inserted by another dispatcher
+func (c *WebhookDeliveryCollection) Save(
+	ctx context.Context, delivery *WebhookDelivery,
+) (bool, error) {
+	ctx, span := tracing.Start(ctx, "WebhookDeliveryCollection.Save")
+	defer span.End()
+
+	if delivery.ID == "" {
+		now := time.Now()
+		delivery.CreatedAt = now
+		delivery.UpdatedAt = now
+
+		r, err := c.collection.InsertOne(ctx, delivery)
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		delivery.ID = ResourceIDFromObjectID(r.InsertedID.(primitive.ObjectID))
+		return true, nil
+	} else {
+		_id, err := delivery.ID.ObjectID()
+		if err != nil {
+			return false, err
+		}
+
+		delivery.UpdatedAt = time.Now()
+
+		_, err = c.collection.UpdateOne(ctx, bson.D{
+			{Key: "_id", Value: _id},
+		}, bson.D{
+			{Key: "$set", Value: delivery},
+		})
+		return err == nil, err
+	}
+}
+
+// delivers a payload to a webhook with retries
+func deliverWebhook(
+	ctx context.Context, ds WebhookDeps, webhook *Webhook, e WebhookEvent, key string, data any, attemptsMax int,
+) (*WebhookDelivery, error) {
+	// create delivery
+	delivery := &WebhookDelivery{
+		WebhookID: webhook.ID,
+		Event:     e,
+		Key:       key,
+		Status:    WebhookDeliveryStatus_Pending,
+		Attempts:  []WebhookDeliveryAttempt{},
+		ExpiresAt: time.Now().Add(ds.WebhookConfig().DeliveryTTL),
+	}
+
+	// save delivery
+	ok, err := ds.WebhookDeliveryCollection().Save(ctx, delivery)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil // already claimed
+	}
+
+	// create payload
+	payload, err := json.Marshal(&WebhookPayload{
+		ID:        delivery.ID,
+		Event:     e,
+		CreatedAt: delivery.CreatedAt,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = string(payload)
+
+	delay := webhookDeliveryRetryDelay
+	for attempt := 1; attempt <= attemptsMax; attempt++ {
+		status, err := ds.WebhookClient().Send(ctx, &client.WebhookRequest{
+			URL:        webhook.URL,
+			Secret:     webhook.Secret,
+			Event:      string(e),
+			DeliveryID: string(delivery.ID),
+			Body:       payload,
+		})
+
+		// record attempt
+		a := WebhookDeliveryAttempt{CreatedAt: time.Now()}
+		if status != 0 {
+			a.StatusCode = &status
+		}
+		if err != nil {
+			msg := err.Error()
+			a.Error = &msg
+		}
+		delivery.Attempts = append(delivery.Attempts, a)
+
+		if err == nil {
+			delivery.Status = WebhookDeliveryStatus_Succeeded
+		} else if attempt == attemptsMax || ctx.Err() != nil {
+			delivery.Status = WebhookDeliveryStatus_Failed
+		}
+
+		if _, err := ds.WebhookDeliveryCollection().Save(ctx, delivery); err != nil {
+			return nil, err
+		}
+		if delivery.Status != WebhookDeliveryStatus_Pending {
+			break
+		}
+
+		// wait with exponential backoff
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	return delivery, nil
+}
+
+type WebhookDispatcherDeps interface {
+	WebhookDeps
+	event.BusProvider
+	MeetingCollectionProvider
+}
+
+// WebhookDispatcher delivers webhooks for events published on the event bus
+type WebhookDispatcher struct {
+	WebhookDispatcherDeps
+	wg sync.WaitGroup
+}
+
+func NewWebhookDispatcher(ds WebhookDispatcherDeps) *WebhookDispatcher {
+	return &WebhookDispatcher{WebhookDispatcherDeps: ds}
+}
+
+// Run dispatches events until ctx is done and waits for pending deliveries
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	for e := range d.EventBus().Subscribe(ctx) {
+		d.wg.Add(1)
+		go func(e event.Event) {
+			defer d.wg.Done()
+			dctx, cancel := context.WithTimeout(ctx, d.WebhookConfig().DispatchTimeout)
+			defer cancel()
+			if err := d.dispatch(dctx, e); err != nil {
+				slog.Error("error dispatching webhooks", "error", err)
+			}
+		}(e)
+	}
+	d.wg.Wait()
+}
+
+func (d *WebhookDispatcher) dispatch(ctx context.Context, e event.Event) error {
+	var webhookEvent WebhookEvent
+	var meeting *Meeting
+	var data map[string]any
+
+	switch e.Collection {
+	case MeetingCollectionName:
+		if e.Operation != event.Operation_Insert {
+			return nil
+		}
+		meeting = &Meeting{}
+		if err := e.Decode(meeting); err != nil {
+			return err
+		}
+		webhookEvent = WebhookEvent_MeetingCreated
+		data = map[string]any{"meeting": meeting}
+
+	case ParticipantCollectionName:
+		var participant Participant
+		if err := e.Decode(&participant); err != nil {
+			return err
+		}
+		switch {
+		case participant.Status == ParticipantStatus_Waiting && e.Operation == event.Operation_Insert:
+			webhookEvent = WebhookEvent_ParticipantWaiting
+		case participant.Status == ParticipantStatus_Admitted:
+			webhookEvent = WebhookEvent_ParticipantAdmitted
+		case participant.Status == ParticipantStatus_Denied:
+			webhookEvent = WebhookEvent_ParticipantDenied
+		default:
+			return nil
+		}
+		m, err := d.MeetingCollection().FindOneByID(ctx, participant.MeetingID)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			return nil
+		}
+		meeting = m
+		data = map[string]any{"meeting": meeting, "participant": participant}
+
+	case RecordingCollectionName:
+		if e.Operation != event.Operation_Insert {
+			return nil
+		}
+		var recording Recording
+		if err := e.Decode(&recording); err != nil {
+			return err
+		}
+		m, err := d.MeetingCollection().FindOneByID(ctx, recording.MeetingID)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			return nil
+		}
+		meeting = m
+		webhookEvent = WebhookEvent_RecordingFinished
+		data = map[string]any{"meeting": meeting, "recording": recording}
+
+	default:
+		return nil
+	}
+
+	// find subscribed webhooks of meeting owner
+	webhooks, err := d.WebhookCollection().FindAllByUserIDAndEvent(ctx, meeting.UserID, webhookEvent)
+	if err != nil {
+		return err
+	}
+
+	// deliver to each webhook
+	key := fmt.Sprintf("%s:%s:%s", e.Collection, e.ID, webhookEvent)
+	var wg sync.WaitGroup
+	for _, webhook := range webhooks {
+		wg.Add(1)
+		go func(webhook *Webhook) {
+			defer wg.Done()
+			_, err := deliverWebhook(ctx, d, webhook, webhookEvent, key, data, d.WebhookConfig().DeliveryAttemptsMax)
+			if err != nil {
+				slog.Error("error delivering webhook", "webhookId", webhook.ID, "error", err)
+			}
+		}(webhook)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+type WebhookController struct {
+	WebhookDeps
+}
+
+func NewWebhookController(ds WebhookDeps) *WebhookController {
+	return &WebhookController{WebhookDeps: ds}
+}
+
+type WebhookCreateBody struct {
+	URL    string         `json:"url"`
+	Events []WebhookEvent `json:"events"`
+}
+
+func (c *WebhookController) WebhookCreateHandler(w http.ResponseWriter, r *http.Request) {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// decode body
+	b := &WebhookCreateBody{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if b.URL == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing url in request body")
+		return
+	}
+	if u, err := url.Parse(b.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Expected an https url in request body")
+		return
+	}
+	if err := c.WebhookClient().CheckURL(r.Context(), b.URL); err != nil {
+		util.WriteJSONError(w, http.StatusBadRequest, "Expected a url with a public address in request body")
+		return
+	}
+	if len(b.Events) == 0 {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing events in request body")
+		return
+	}
+	for _, e := range b.Events {
+		if !e.subscribable() {
+			util.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("Unexpected event %q in request body", e))
+			return
+		}
+	}
+
+	// ensure webhook count within limit
+	count, err := c.WebhookCollection().CountByUserID(r.Context(), ResourceID(auth.UserID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if count >= WebhookCountMax {
+		util.WriteJSONError(w, http.StatusBadRequest, "Maximum number of webhooks reached")
+		return
+	}
+
+	// create secret
+	secret, err := newWebhookSecret()
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// create webhook
+	webhook := &Webhook{
+		UserID: ResourceID(auth.UserID),
+		URL:    b.URL,
+		Events: b.Events,
+		Secret: secret,
+	}
+
+	// save webhook
+	err = c.WebhookCollection().Save(r.Context(), webhook)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, &WebhookWithSecret{Webhook: *webhook, Secret: webhook.Secret})
+}
+
+func (c *WebhookController) WebhookListHandler(w http.ResponseWriter, r *http.Request) {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// find all by user id
+	webhooks, err := c.WebhookCollection().FindAllByUserID(r.Context(), ResourceID(auth.UserID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := map[string]any{
+		"webhooks": webhooks,
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, res)
+}
+
+// finds the webhook in request path owned by the authorized user
+func (c *WebhookController) findAuthorizedWebhook(w http.ResponseWriter, r *http.Request) *Webhook {
+	// decode auth token
+	auth, err := middleware.GetAuthToken(r)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return nil
+	}
+	if auth == nil {
+		util.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil
+	}
+
+	// get webhook id
+	webhookID := mux.Vars(r)["webhookId"]
+	if webhookID == "" {
+		util.WriteJSONError(w, http.StatusBadRequest, "Missing webhookId in request path")
+		return nil
+	}
+
+	// find one by id
+	webhook, err := c.WebhookCollection().FindOneByID(r.Context(), ResourceID(webhookID))
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return nil
+	}
+	if webhook == nil || webhook.UserID != ResourceID(auth.UserID) {
+		util.WriteJSONError(w, http.StatusNotFound, "Webhook not found")
+		return nil
+	}
+
+	return webhook
+}
+
+func (c *WebhookController) WebhookDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	webhook := c.findAuthorizedWebhook(w, r)
+	if webhook == nil {
+		return
+	}
+
+	// delete webhook
+	err := c.WebhookCollection().DeleteOneByID(r.Context(), webhook.ID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, webhook)
+}
+
+func (c *WebhookController) WebhookTestHandler(w http.ResponseWriter, r *http.Request) {
+	webhook := c.findAuthorizedWebhook(w, r)
+	if webhook == nil {
+		return
+	}
+
+	// deliver ping without retries
+	key := fmt.Sprintf("%s:%s", WebhookEvent_Ping, NewResourceID())
+	data := map[string]any{"webhookId": webhook.ID}
+	delivery, err := deliverWebhook(r.Context(), c, webhook, WebhookEvent_Ping, key, data, 1)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, delivery)
+}
+
+func (c *WebhookController) WebhookDeliveryListHandler(w http.ResponseWriter, r *http.Request) {
+	webhook := c.findAuthorizedWebhook(w, r)
+	if webhook == nil {
+		return
+	}
+
+	// find all by webhook id
+	deliveries, err := c.WebhookDeliveryCollection().FindAllByWebhookID(r.Context(), webhook.ID)
+	if err != nil {
+		util.WriteJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := map[string]any{
+		"deliveries": deliveries,
+	}
+
+	util.WriteJSONResponse(w, http.StatusOK, res)
+}
+
+func RegisterWebhookRoutes(r *mux.Router, ds WebhookDeps) *mux.Router {
+	c := NewWebhookController(ds)
+
+	r.HandleFunc("/webhooks", c.WebhookCreateHandler).Methods(http.MethodPost)
+	r.HandleFunc("/webhooks", c.WebhookListHandler).Methods(http.MethodGet)
+	r.HandleFunc("/webhooks/{webhookId}", c.WebhookDeleteHandler).Methods(http.MethodDelete)
+	r.HandleFunc("/webhooks/{webhookId}/test", c.WebhookTestHandler).Methods(http.MethodPost)
+	r.HandleFunc("/webhooks/{webhookId}/deliveries", c.WebhookDeliveryListHandler).Methods(http.MethodGet)
+
+	return r
+}
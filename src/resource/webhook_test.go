@@ -0,0 +1,150 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newWebhookAndJSON() (Webhook, []byte) {
+	var t, _ = time.Parse(time.RFC3339, "2022-01-01T00:00:00.000Z")
+	var m = Webhook{
+		ID:        "some-id",
+		UserID:    "some-id",
+		URL:       "https://example.com/webhook",
+		Events:    []WebhookEvent{WebhookEvent_MeetingCreated},
+		CreatedAt: t,
+		UpdatedAt: t,
+	}
+
+	var j = []byte(`{"id":"some-id","userId":"some-id","url":"https://example.com/webhook",` +
+		`"events":["meeting.created"],"createdAt":"2022-01-01T00:00:00Z",` +
+		`"updatedAt":"2022-01-01T00:00:00Z"}`)
+
+	return m, j
+}
+
+func TestWebhookMarshalJSON(t *testing.T) {
+	t.Parallel()
+	m, j := newWebhookAndJSON()
+
+	value, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Error marshalling json: %#v", err)
+	}
+	if !bytes.Equal(j, value) {
+		t.Fatalf("Unexpected marshalled json: %#v", string(value))
+	}
+}
+
+func TestWebhookUnmarshalJSON(t *testing.T) {
+	t.Parallel()
+	m, j := newWebhookAndJSON()
+
+	var value Webhook
+	err := json.Unmarshal([]byte(j), &value)
+	if err != nil {
+		t.Fatalf("Error unmarshalling json: %#v", err)
+	}
+	if !reflect.DeepEqual(m, value) {
+		t.Fatalf("Unexpected unmarshalled json: %#v", value)
+	}
+}
+
+func TestWebhookMarshalJSONWithoutSecret(t *testing.T) {
+	t.Parallel()
+	m, j := newWebhookAndJSON()
+	m.Secret = "some-secret"
+
+	value, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Error marshalling json: %#v", err)
+	}
+	if !bytes.Equal(j, value) {
+		t.Fatalf("Unexpected marshalled json: %#v", string(value))
+	}
+
+	value, err = json.Marshal(&WebhookWithSecret{Webhook: m, Secret: m.Secret})
+	if err != nil {
+		t.Fatalf("Error marshalling json: %#v", err)
+	}
+	if !bytes.Contains(value, []byte(`"secret":"some-secret"`)) {
+		t.Fatalf("Unexpected marshalled json: %#v", string(value))
+	}
+}
+
+func newWebhookAndBSON() (Webhook, []byte) {
+	var o = primitive.NewObjectID()
+	var t, _ = time.Parse(time.RFC3339, "2022-01-01T00:00:00.000Z")
+	var m = Webhook{
+		ID:        ResourceIDFromObjectID(o),
+		UserID:    ResourceIDFromObjectID(o),
+		URL:       "https://example.com/webhook",
+		Events:    []WebhookEvent{WebhookEvent_MeetingCreated},
+		Secret:    "some-secret",
+		CreatedAt: t,
+		UpdatedAt: t,
+	}
+
+	var d = primitive.NewDateTimeFromTime(t)
+	var b, _ = bson.Marshal(bson.D{
+		{Key: "_id", Value: o},
+		{Key: "userId", Value: o},
+		{Key: "url", Value: "https://example.com/webhook"},
+		{Key: "events", Value: bson.A{"meeting.created"}},
+		{Key: "secret", Value: "some-secret"},
+		{Key: "createdAt", Value: d},
+		{Key: "updatedAt", Value: d},
+	})
+
+	return m, b
+}
+
+func TestWebhookMarshalBSON(t *testing.T) {
+	t.Parallel()
+	m, b := newWebhookAndBSON()
+
+	value, err := bson.Marshal(m)
+	if err != nil {
+		t.Fatalf("Error marshalling bson: %#v", err)
+	}
+	if !bytes.Equal(b, value) {
+		t.Fatalf("Unexpected marshalled bson: %#v", string(value))
+	}
+}
+
+func TestWebhookUnmarshalBSON(t *testing.T) {
+	t.Parallel()
+	m, b := newWebhookAndBSON()
+
+	var value Webhook
+	err := bson.Unmarshal(b, &value)
+	if err != nil {
+		t.Fatalf("Error unmarshalling bson: %#v", err)
+	}
+	if !reflect.DeepEqual(m, value) {
+		t.Fatalf("Unexpected unmarshalled bson: %#v", value)
+	}
+}
+
+func TestWebhookEventSubscribable(t *testing.T) {
+	t.Parallel()
+
+	if WebhookEvent_Ping.subscribable() {
+		t.Fatalf("Expected %q to not be subscribable", WebhookEvent_Ping)
+	}
+	if !WebhookEvent_ParticipantAdmitted.subscribable() {
+		t.Fatalf("Expected %q to be subscribable", WebhookEvent_ParticipantAdmitted)
+	}
+	if !WebhookEvent_RecordingFinished.subscribable() {
+		t.Fatalf("Expected %q to be subscribable", WebhookEvent_RecordingFinished)
+	}
+	if WebhookEvent("unknown").subscribable() {
+		t.Fatalf("Expected %q to not be subscribable", "unknown")
+	}
+}
@@ -2,24 +2,106 @@ package route
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/aravindanve/livemeet-server/src/middleware"
 	"github.com/aravindanve/livemeet-server/src/provider"
 	"github.com/aravindanve/livemeet-server/src/resource"
 	"github.com/aravindanve/livemeet-server/src/util"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// server to server routes are exempt from the per ip limit of all routes
+var rateLimitPolicies = []middleware.RateLimitPolicy{{
+	Name:     "livekitWebhook",
+	Method:   http.MethodPost,
+	Path:     "/livekit/webhook",
+	Disabled: true,
+}, {
+	Name:  "all",
+	By:    middleware.RateLimitKey_IP,
+	Limit: middleware.RateLimit{Requests: 600, Per: time.Minute},
+}, {
+	Name:   "meetingSearch",
+	Method: http.MethodGet,
+	Path:   "/meetings",
+	By:     middleware.RateLimitKey_IP,
+	Limit:  middleware.RateLimit{Requests: 20, Per: time.Minute},
+}, {
+	Name:   "meetingCreate",
+	Method: http.MethodPost,
+	Path:   "/meetings",
+	By:     middleware.RateLimitKey_User,
+	Limit:  middleware.RateLimit{Requests: 60, Per: time.Hour},
+}, {
+	Name:   "participantCreate",
+	Method: http.MethodPost,
+	Path:   "/meetings/{meetingId}/participants",
+	By:     middleware.RateLimitKey_IP,
+	Limit:  middleware.RateLimit{Requests: 20, Per: time.Minute},
+}, {
+	Name:   "participantRetrieve",
+	Method: http.MethodGet,
+	Path:   "/meetings/{meetingId}/participants/{participantId}",
+	By:     middleware.RateLimitKey_IP,
+	Limit:  middleware.RateLimit{Requests: 120, Per: time.Minute},
+}, {
+	Name:   "invitationCreate",
+	Method: http.MethodPost,
+	Path:   "/meetings/{meetingId}/invitations",
+	By:     middleware.RateLimitKey_User,
+	Limit:  middleware.RateLimit{Requests: 20, Per: time.Hour},
+}, {
+	Name:   "messageCreate",
+	Method: http.MethodPost,
+	Path:   "/meetings/{meetingId}/messages",
+	By:     middleware.RateLimitKey_IP,
+	Limit:  middleware.RateLimit{Requests: 60, Per: time.Minute},
+}, {
+	Name:   "userSearch",
+	Method: http.MethodGet,
+	Path:   "/users",
+	By:     middleware.RateLimitKey_User,
+	Limit:  middleware.RateLimit{Requests: 60, Per: time.Minute},
+}, {
+	Name:   "authCreate",
+	Method: http.MethodPost,
+	Path:   "/auth",
+	By:     middleware.RateLimitKey_IP,
+	Limit:  middleware.RateLimit{Requests: 20, Per: time.Minute},
+}}
+
+// server to server routes are not called from browsers
+var corsPolicies = []middleware.CORSPolicy{{
+	PathPrefix: "/livekit/webhook",
+	Disabled:   true,
+}}
+
 func RegisterRoutes(r *mux.Router, p provider.Provider) *mux.Router {
 	// register routes
+	resource.RegisterHealthRoutes(r, p)
 	resource.RegisterSessionRoutes(r, p)
+	resource.RegisterUserRoutes(r, p)
 	resource.RegisterAuthRoutes(r, p)
 	resource.RegisterMeetingRoutes(r, p)
 	resource.RegisterParticipantRoutes(r, p)
+	resource.RegisterInvitationRoutes(r, p)
+	resource.RegisterOccurrenceRoutes(r, p)
+	resource.RegisterAttendanceRoutes(r, p)
+	resource.RegisterBreakoutRoutes(r, p)
+	resource.RegisterMessageRoutes(r, p)
+	resource.RegisterWebhookRoutes(r, p)
 
 	// register middleware
-	r.Use(middleware.CORSMiddleware())
+	r.Use(middleware.ProxyMiddleware(p))
+	r.Use(middleware.LoggerMiddleware())
+	r.Use(middleware.TracingMiddleware())
+	r.Use(middleware.MetricsMiddleware())
+	r.Use(middleware.SecurityHeadersMiddleware(p))
+	r.Use(middleware.CORSMiddleware(p, corsPolicies...))
 	r.Use(middleware.AuthMiddleware(p))
+	r.Use(middleware.RateLimitMiddleware(p, rateLimitPolicies...))
 
 	// handle 404
 	r.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -28,3 +110,10 @@ func RegisterRoutes(r *mux.Router, p provider.Provider) *mux.Router {
 
 	return r
 }
+
+// RegisterMetricsRoutes registers the prometheus endpoint, served on the
+// internal metrics address only
+func RegisterMetricsRoutes(r *mux.Router) *mux.Router {
+	r.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	return r
+}
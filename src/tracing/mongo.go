@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WrapMongoMonitor returns a command monitor that adds commands as events to
+// the span of the operation context, failed commands are recorded as errors.
+// Events are passed on to m.
+func WrapMongoMonitor(m *event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			trace.SpanFromContext(ctx).AddEvent("mongo command",
+				trace.WithAttributes(attribute.String("db.operation", e.CommandName)))
+			if m != nil && m.Started != nil {
+				m.Started(ctx, e)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			if m != nil && m.Succeeded != nil {
+				m.Succeeded(ctx, e)
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			span := trace.SpanFromContext(ctx)
+			span.RecordError(errors.New(e.Failure), trace.WithAttributes(attribute.String("db.operation", e.CommandName)))
+			span.SetStatus(codes.Error, e.Failure)
+			if m != nil && m.Failed != nil {
+				m.Failed(ctx, e)
+			}
+		},
+	}
+}
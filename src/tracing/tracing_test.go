@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aravindanve/livemeet-server/src/config"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInitDisabled(t *testing.T) {
+	shutdown, err := Init(context.Background(), config.TracingConfig{Enabled: false})
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+}
+
+func TestEndRecordsError(t *testing.T) {
+	t.Parallel()
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	_, span := tp.Tracer("test").Start(context.Background(), "test")
+	End(span, errors.New("failed"))
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Errorf("expected 1 span got %d", len(spans))
+		return
+	}
+	if s := spans[0].Status(); s.Code != codes.Error || s.Description != "failed" {
+		t.Errorf(`expected status to be error "failed" got %v %q`, s.Code, s.Description)
+		return
+	}
+	if n := len(spans[0].Events()); n != 1 {
+		t.Errorf("expected 1 error event got %d", n)
+		return
+	}
+}
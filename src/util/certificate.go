@@ -0,0 +1,93 @@
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// CertificateFiles serves a certificate and key pair from files, the pair is
+// loaded again by Watch when either file changes so that renewed certificates
+// are served without a restart
+type CertificateFiles struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+	modTime  time.Time // of the loaded pair, only used by load and Watch
+}
+
+// NewCertificateFiles loads the pair once so that invalid files fail startup
+func NewCertificateFiles(certFile, keyFile string) (*CertificateFiles, error) {
+	c := &CertificateFiles{certFile: certFile, keyFile: keyFile}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// returns the latest modification time of the pair
+func (c *CertificateFiles) stat() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (c *CertificateFiles) load() error {
+	modTime, err := c.stat()
+	if err != nil {
+		return fmt.Errorf("error loading tls certificate: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("error loading tls certificate: %w", err)
+	}
+	c.cert.Store(&cert)
+	c.modTime = modTime
+	return nil
+}
+
+// loads the pair again when either file changed, returns whether it changed
+func (c *CertificateFiles) reload() (bool, error) {
+	modTime, err := c.stat()
+	if err != nil {
+		return false, fmt.Errorf("error loading tls certificate: %w", err)
+	}
+	if modTime.Equal(c.modTime) {
+		return false, nil
+	}
+	return true, c.load()
+}
+
+// Watch checks the files every interval until ctx is done, reloaded is called
+// when the files changed with the error loading them if any, the previous
+// pair is served until the files load
+func (c *CertificateFiles) Watch(ctx context.Context, interval time.Duration, reloaded func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if changed, err := c.reload(); changed || err != nil {
+			reloaded(err)
+		}
+	}
+}
+
+// GetCertificate is used as tls.Config.GetCertificate
+func (c *CertificateFiles) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.cert.Load(), nil
+}
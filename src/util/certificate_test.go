@@ -0,0 +1,149 @@
+package util
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writes a self signed certificate for cn and its key
+func writeTestCertificate(t *testing.T, certFile, keyFile, cn string, modTime time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{certFile, keyFile} {
+		if err := os.Chtimes(name, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func commonName(t *testing.T, c *CertificateFiles) string {
+	cert, err := c.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestCertificateFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	now := time.Now()
+	writeTestCertificate(t, certFile, keyFile, "first", now.Add(-time.Minute))
+
+	c, err := NewCertificateFiles(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cn := commonName(t, c); cn != "first" {
+		t.Errorf("expected common name to be first got %s", cn)
+	}
+
+	// unchanged files are not loaded again
+	if changed, err := c.reload(); changed || err != nil {
+		t.Errorf("expected unchanged files to be skipped got %v %v", changed, err)
+	}
+
+	// renewed files are loaded
+	writeTestCertificate(t, certFile, keyFile, "second", now)
+	if changed, err := c.reload(); !changed || err != nil {
+		t.Errorf("expected renewed files to be loaded got %v %v", changed, err)
+	}
+	if cn := commonName(t, c); cn != "second" {
+		t.Errorf("expected common name to be second got %s", cn)
+	}
+
+	// invalid files keep the previous pair
+	if err := os.WriteFile(certFile, []byte("invalid"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(certFile, now.Add(time.Minute), now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.reload(); err == nil {
+		t.Errorf("expected invalid files to fail")
+	}
+	if cn := commonName(t, c); cn != "second" {
+		t.Errorf("expected common name to be second got %s", cn)
+	}
+}
+
+func TestCertificateFilesWatch(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	now := time.Now()
+	writeTestCertificate(t, certFile, keyFile, "first", now.Add(-time.Minute))
+
+	c, err := NewCertificateFiles(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 1)
+	go c.Watch(ctx, 10*time.Millisecond, func(err error) {
+		reloaded <- err
+	})
+
+	// files being replaced may fail to load until both are written
+	writeTestCertificate(t, certFile, keyFile, "second", now)
+	timeout := time.After(5 * time.Second)
+	for loaded := false; !loaded; {
+		select {
+		case err := <-reloaded:
+			loaded = err == nil
+		case <-timeout:
+			t.Fatal("expected renewed files to be loaded")
+		}
+	}
+	if cn := commonName(t, c); cn != "second" {
+		t.Errorf("expected common name to be second got %s", cn)
+	}
+}
+
+func TestNewCertificateFilesInvalid(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if _, err := NewCertificateFiles(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")); err == nil {
+		t.Errorf("expected missing files to fail")
+	}
+}
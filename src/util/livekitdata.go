@@ -1,13 +1,51 @@
 package util
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+)
 
 const (
 	LiveKitDataType_JSON LiveKitDataType = 1
+	LiveKitDataType_CBOR LiveKitDataType = 2
 )
 
+var ErrLiveKitDataType = errors.New("unsupported livekit data type")
+
+var liveKitDataCBOREncMode, _ = cbor.EncOptions{
+	Time: cbor.TimeRFC3339Nano, // same as json
+}.EncMode()
+
+// LiveKitDataType is the first byte of data sent to livekit rooms and
+// identifies the encoding of the rest of the data
 type LiveKitDataType byte
 
+// ParseLiveKitDataType parses the name of an encoding, json or cbor
+func ParseLiveKitDataType(s string) (LiveKitDataType, error) {
+	switch s {
+	case "json":
+		return LiveKitDataType_JSON, nil
+	case "cbor":
+		return LiveKitDataType_CBOR, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrLiveKitDataType, s)
+	}
+}
+
+func EncodeLiveKitData(t LiveKitDataType, d any) ([]byte, error) {
+	switch t {
+	case LiveKitDataType_JSON:
+		return EncodeLiveKitDataJSON(d)
+	case LiveKitDataType_CBOR:
+		return EncodeLiveKitDataCBOR(d)
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrLiveKitDataType, t)
+	}
+}
+
 func EncodeLiveKitDataJSON(d any) ([]byte, error) {
 	b, err := json.Marshal(d)
 	if err != nil {
@@ -15,3 +53,27 @@ func EncodeLiveKitDataJSON(d any) ([]byte, error) {
 	}
 	return append([]byte{byte(LiveKitDataType_JSON)}, b...), nil
 }
+
+func EncodeLiveKitDataCBOR(d any) ([]byte, error) {
+	b, err := liveKitDataCBOREncMode.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{byte(LiveKitDataType_CBOR)}, b...), nil
+}
+
+// DecodeLiveKitData decodes data of any supported encoding into d
+func DecodeLiveKitData(b []byte, d any) error {
+	if len(b) == 0 {
+		return fmt.Errorf("%w: empty data", ErrLiveKitDataType)
+	}
+
+	switch t := LiveKitDataType(b[0]); t {
+	case LiveKitDataType_JSON:
+		return json.Unmarshal(b[1:], d)
+	case LiveKitDataType_CBOR:
+		return cbor.Unmarshal(b[1:], d)
+	default:
+		return fmt.Errorf("%w: %d", ErrLiveKitDataType, t)
+	}
+}
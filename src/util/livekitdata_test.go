@@ -2,6 +2,7 @@ package util
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -35,3 +36,44 @@ func TestEncodeLiveKitDataJSON(t *testing.T) {
 		return
 	}
 }
+
+func TestEncodeLiveKitDataCBOR(t *testing.T) {
+	t.Parallel()
+
+	a := map[string]string{
+		"hello": "world",
+	}
+	m, err := EncodeLiveKitDataCBOR(a)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if m[0] != byte(LiveKitDataType_CBOR) {
+		t.Errorf("expected data type to be %v got %v", LiveKitDataType_CBOR, m[0])
+		return
+	}
+
+	var b map[string]string
+	err = DecodeLiveKitData(m, &b)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("expected data to be %#v got %#v", a, b)
+		return
+	}
+}
+
+func TestDecodeLiveKitDataUnsupported(t *testing.T) {
+	t.Parallel()
+
+	var b map[string]string
+	for _, m := range [][]byte{nil, {9, '{', '}'}} {
+		err := DecodeLiveKitData(m, &b)
+		if !errors.Is(err, ErrLiveKitDataType) {
+			t.Errorf("expected error to be %#v got %#v", ErrLiveKitDataType, err)
+			return
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RRuleFreq_Daily      RRuleFreq = "DAILY"
+	RRuleFreq_Weekly     RRuleFreq = "WEEKLY"
+	RRuleFreq_Monthly    RRuleFreq = "MONTHLY"
+	rruleIntervalMax               = 1000
+	rrulePeriodsMax                = 100000
+	rruleUntilLayout               = "20060102T150405Z"
+	rruleUntilDateLayout           = "20060102"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+type RRuleFreq string
+
+// RRule is the subset of RFC 5545 recurrence rules with FREQ of DAILY, WEEKLY
+// or MONTHLY, INTERVAL, BYDAY for weekly rules, BYMONTHDAY for monthly rules
+// and either COUNT or UNTIL. Weeks start on monday.
+type RRule struct {
+	Freq       RRuleFreq
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+}
+
+// ParseRRule parses a rule such as FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10, with or
+// without the RRULE: prefix
+func ParseRRule(s string) (*RRule, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("empty rule")
+	}
+
+	rule := &RRule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate rule part %q", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			switch f := RRuleFreq(value); f {
+			case RRuleFreq_Daily, RRuleFreq_Weekly, RRuleFreq_Monthly:
+				rule.Freq = f
+			default:
+				return nil, fmt.Errorf("unsupported frequency %q", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > rruleIntervalMax {
+				return nil, fmt.Errorf("interval must be 1 to %d", rruleIntervalMax)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("count must be a positive number")
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := time.Parse(rruleUntilLayout, value)
+			if err != nil {
+				// dates include the whole day
+				until, err = time.Parse(rruleUntilDateLayout, value)
+				if err != nil {
+					return nil, fmt.Errorf("until must be in the format %s or %s", rruleUntilLayout, rruleUntilDateLayout)
+				}
+				until = until.Add(24*time.Hour - time.Second)
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				wd, ok := rruleWeekdays[d]
+				if !ok {
+					return nil, fmt.Errorf("unsupported weekday %q", d)
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, d := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(d)
+				if err != nil || n < 1 || n > 31 {
+					return nil, fmt.Errorf("month day must be 1 to 31")
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			if value != "MO" {
+				return nil, fmt.Errorf("unsupported week start %q", value)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("missing frequency")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("count and until must not both be set")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != RRuleFreq_Weekly {
+		return nil, fmt.Errorf("weekdays are only supported for weekly rules")
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq != RRuleFreq_Monthly {
+		return nil, fmt.Errorf("month days are only supported for monthly rules")
+	}
+
+	return rule, nil
+}
+
+// Between returns the occurrences of the rule starting at start that fall in
+// [from, to). Occurrences keep the wall clock time of start in its location
+// across daylight saving changes. Start is the first occurrence only if it
+// matches the rule.
+func (r *RRule) Between(start, from, to time.Time) []time.Time {
+	res := make([]time.Time, 0)
+	count := 0
+
+	for i := 0; i < rrulePeriodsMax; i++ {
+		for _, t := range r.period(start, i) {
+			if t.Before(start) {
+				continue
+			}
+			if r.Until != nil && t.After(*r.Until) {
+				return res
+			}
+			if !t.Before(to) {
+				return res
+			}
+			count++
+			if !t.Before(from) {
+				res = append(res, t)
+			}
+			if r.Count > 0 && count >= r.Count {
+				return res
+			}
+		}
+	}
+
+	return res
+}
+
+// returns the candidate occurrences in the ith period in ascending order
+func (r *RRule) period(start time.Time, i int) []time.Time {
+	y, m, d := start.Date()
+	h, min, s := start.Clock()
+	loc := start.Location()
+
+	switch r.Freq {
+	case RRuleFreq_Weekly:
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+
+		// offsets from monday
+		offsets := make([]int, 0, len(days))
+		for _, wd := range days {
+			offsets = append(offsets, (int(wd)+6)%7)
+		}
+		sort.Ints(offsets)
+
+		monday := d - (int(start.Weekday())+6)%7 + i*7*r.Interval
+		res := make([]time.Time, 0, len(offsets))
+		for j, o := range offsets {
+			if j > 0 && o == offsets[j-1] {
+				continue
+			}
+			res = append(res, time.Date(y, m, monday+o, h, min, s, 0, loc))
+		}
+		return res
+
+	case RRuleFreq_Monthly:
+		monthDays := r.ByMonthDay
+		if len(monthDays) == 0 {
+			monthDays = []int{d}
+		}
+		monthDays = append([]int(nil), monthDays...)
+		sort.Ints(monthDays)
+
+		// months without the day are skipped
+		first := time.Date(y, m+time.Month(i*r.Interval), 1, h, min, s, 0, loc)
+		last := first.AddDate(0, 1, -1).Day()
+		res := make([]time.Time, 0, len(monthDays))
+		for j, md := range monthDays {
+			if md > last || (j > 0 && md == monthDays[j-1]) {
+				continue
+			}
+			res = append(res, first.AddDate(0, 0, md-1))
+		}
+		return res
+
+	default:
+		return []time.Time{time.Date(y, m, d+i*r.Interval, h, min, s, 0, loc)}
+	}
+}
@@ -0,0 +1,114 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	t.Parallel()
+
+	r, err := ParseRRule("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;UNTIL=20230131")
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	until := time.Date(2023, 1, 31, 23, 59, 59, 0, time.UTC)
+	expected := &RRule{
+		Freq:     RRuleFreq_Weekly,
+		Interval: 2,
+		ByDay:    []time.Weekday{time.Monday, time.Wednesday},
+		Until:    &until,
+	}
+	if !reflect.DeepEqual(r, expected) {
+		t.Errorf("expected rule to be %#v got %#v", expected, r)
+		return
+	}
+
+	for _, s := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=DAILY;COUNT=2;UNTIL=20230131",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+	} {
+		if _, err := ParseRRule(s); err == nil {
+			t.Errorf("expected error for rule %q got nil", s)
+			return
+		}
+	}
+}
+
+func TestRRuleBetween(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+
+	cases := []struct {
+		rule     string
+		start    time.Time
+		from     time.Time
+		to       time.Time
+		expected []time.Time
+	}{{
+		// keeps wall clock time across daylight saving change on 2023-03-12
+		rule:  "FREQ=DAILY;INTERVAL=2",
+		start: time.Date(2023, 3, 9, 9, 0, 0, 0, loc),
+		from:  time.Date(2023, 3, 10, 0, 0, 0, 0, loc),
+		to:    time.Date(2023, 3, 16, 0, 0, 0, 0, loc),
+		expected: []time.Time{
+			time.Date(2023, 3, 11, 9, 0, 0, 0, loc),
+			time.Date(2023, 3, 13, 9, 0, 0, 0, loc),
+			time.Date(2023, 3, 15, 9, 0, 0, 0, loc),
+		},
+	}, {
+		// skips weekdays before start in the first week
+		rule:  "FREQ=WEEKLY;BYDAY=FR,MO;COUNT=3",
+		start: time.Date(2023, 1, 4, 9, 0, 0, 0, time.UTC),
+		from:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		to:    time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+		expected: []time.Time{
+			time.Date(2023, 1, 6, 9, 0, 0, 0, time.UTC),
+			time.Date(2023, 1, 9, 9, 0, 0, 0, time.UTC),
+			time.Date(2023, 1, 13, 9, 0, 0, 0, time.UTC),
+		},
+	}, {
+		// skips months without the day
+		rule:  "FREQ=MONTHLY;BYMONTHDAY=31;UNTIL=20230531T000000Z",
+		start: time.Date(2023, 1, 31, 9, 0, 0, 0, time.UTC),
+		from:  time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+		to:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		expected: []time.Time{
+			time.Date(2023, 3, 31, 9, 0, 0, 0, time.UTC),
+		},
+	}}
+
+	for _, c := range cases {
+		r, err := ParseRRule(c.rule)
+		if err != nil {
+			t.Errorf("expected error to be nil got %#v", err)
+			return
+		}
+
+		ts := r.Between(c.start, c.from, c.to)
+		if len(ts) != len(c.expected) {
+			t.Errorf("expected %d occurrences for %q got %v", len(c.expected), c.rule, ts)
+			return
+		}
+		for i := range ts {
+			if !ts[i].Equal(c.expected[i]) {
+				t.Errorf("expected occurrence %d for %q to be %v got %v", i, c.rule, c.expected[i], ts[i])
+				return
+			}
+		}
+	}
+}
@@ -18,9 +18,16 @@ func WriteJSONResponse(w http.ResponseWriter, statusCode int, body interface{})
 	w.Write(b)
 }
 
+// WriteJSONError writes an error with the request id of the response if set
+// by the logger middleware
 func WriteJSONError(w http.ResponseWriter, statusCode int, message string) {
-	WriteJSONResponse(w, statusCode, map[string]any{
+	body := map[string]any{
 		"error":   true,
 		"message": message,
-	})
+	}
+	if requestID := w.Header().Get("x-request-id"); requestID != "" {
+		body["requestId"] = requestID
+	}
+
+	WriteJSONResponse(w, statusCode, body)
 }
@@ -62,3 +62,22 @@ func TestWriteJSONError(t *testing.T) {
 		return
 	}
 }
+
+func TestWriteJSONErrorWithRequestID(t *testing.T) {
+	t.Parallel()
+	w := httptest.NewRecorder()
+	w.Header().Set("x-request-id", "some-request-id")
+
+	WriteJSONError(w, http.StatusInternalServerError, "this is an error message")
+
+	var b map[string]any
+	err := json.NewDecoder(w.Result().Body).Decode(&b)
+	if err != nil {
+		t.Errorf("expected error to be nil got %#v", err)
+		return
+	}
+	if b["requestId"] != "some-request-id" {
+		t.Errorf("expected request id to be %q got %#v", "some-request-id", b["requestId"])
+		return
+	}
+}